@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const abcBarsPerLine = 4
+
+var (
+	abcAccidentals = map[int]string{-2: "__", -1: "_", 0: "=", 1: "^", 2: "^^"}
+
+	// abcSuffixes are the usual ABC spellings of chord suffixes that are not plain ASCII.
+	abcSuffixes = map[string]string{
+		"°":    "dim",
+		"°7":   "dim7",
+		"+":    "aug",
+		"M7":   "maj7",
+		"m7♭5": "m7b5",
+		"+M7":  "aug(maj7)",
+		"mM7":  "m(maj7)",
+		"M9":   "maj9",
+	}
+)
+
+// abcPitch returns the ABC name of p, where C is middle C and c the octave above, with an accidental if it is needed
+// in the bar.
+func abcPitch(p pitch, keySig keySignature, altered map[int]int) string {
+	var accidental string
+	if keySig.needsAccidental(p, altered) {
+		accidental = abcAccidentals[p.alter]
+	}
+
+	name := string(p.step)
+	switch {
+	case p.octave >= 5:
+		name = strings.ToLower(name) + strings.Repeat("'", p.octave-5)
+	case p.octave < 4:
+		name += strings.Repeat(",", 4-p.octave)
+	}
+
+	return accidental + name
+}
+
+// abcChordName returns the chord symbol of the named chord in ASCII, such as "F#m7b5".
+func abcChordName(name string) string {
+	root, suffix, bass, ok := splitChordName(name)
+	if !ok {
+		return asciiAccidentals.Replace(name)
+	}
+	if s, ok := abcSuffixes[suffix]; ok {
+		suffix = s
+	}
+	if bass != "" {
+		bass = "/" + bass
+	}
+
+	return asciiAccidentals.Replace(root + suffix + bass)
+}
+
+// abcKey returns the value of the K: field for the key, or "none" when its signature cannot be written.
+func abcKey(key, scale string) string {
+	if !keySignatureFor(key, scale).valid() {
+		return "none"
+	}
+	step, alter, _ := parseNote(key)
+	name := asciiAccidentals.Replace(pitch{step: step, alter: alter}.name())
+	if scale == "Minor" {
+		name += "m"
+	}
+
+	return name
+}
+
+// abcText escapes a word of a w: lyrics line, in which spaces separate the words of successive notes.
+func abcText(s string) string {
+	if s == "" {
+		return "*"
+	}
+	return strings.ReplaceAll(s, " ", "~")
+}
+
+// writeABC writes the sections as ABC tunes, one for each. The scale is written in quarter notes. Chords are written a
+// bar each with their chord symbols, with their positions as words below.
+func (m *model) writeABC(w io.Writer, sections []scoreSection) error {
+	keySig := keySignatureFor(m.key, m.scale)
+	if !keySig.valid() {
+		keySig = 0
+	}
+
+	var b strings.Builder
+	for i, sec := range sections {
+		var bars, words []string
+		var altered map[int]int
+		for j, p := range sec.melody {
+			if j%beatsPerBar == 0 {
+				bars = append(bars, "")
+				altered = make(map[int]int)
+			}
+			bars[len(bars)-1] += abcPitch(p, keySig, altered) + " "
+		}
+		for _, c := range sec.chords {
+			altered = make(map[int]int)
+			var tones string
+			for _, p := range voiceNotes(c.notes, scoreOctave) {
+				tones += abcPitch(p, keySig, altered)
+			}
+			bars = append(bars, fmt.Sprintf("\"%s\"[%s]%d ", abcChordName(c.name), tones, beatsPerBar))
+			words = append(words, abcText(c.position))
+		}
+		if len(bars) == 0 {
+			continue
+		}
+
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "X:%d\nT:%s %s\nT:%s\nM:4/4\nL:1/4\nK:%s\n", i+1, m.key, m.scale, sec.title,
+			abcKey(m.key, m.scale))
+		for j := 0; j < len(bars); j += abcBarsPerLine {
+			line := bars[j:minInt(j+abcBarsPerLine, len(bars))]
+			end := "|"
+			if j+abcBarsPerLine >= len(bars) {
+				end = "|]"
+			}
+			b.WriteString(strings.Join(line, "| ") + end + "\n")
+			if len(words) > 0 {
+				lineWords := words[j:minInt(j+abcBarsPerLine, len(words))]
+				b.WriteString("w:" + strings.Join(lineWords, " ") + "\n")
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// showABCExport asks what to export and where to save it as ABC notation.
+func (m *model) showABCExport() {
+	m.showScoreExport("ABC", ".abc", m.writeABC)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestABCPitch(t *testing.T) {
+	altered := make(map[int]int)
+	var names []string
+	for _, p := range []pitch{{'C', 0, 4}, {'C', 0, 5}, {'C', 0, 6}, {'G', 0, 3}, {'F', 0, 4}, {'F', 1, 4}, {'F', 1, 4},
+		{'B', -1, 2}, {'E', 2, 4}} {
+		names = append(names, abcPitch(p, 1, altered))
+	}
+	assert.Equal(t, []string{"C", "c", "c'", "G,", "=F", "^F", "F", "_B,,", "^^E"}, names)
+}
+
+func TestABCChordName(t *testing.T) {
+	tests := []struct {
+		name, abc string
+	}{
+		{"C", "C"},
+		{"F♯m7♭5", "F#m7b5"},
+		{"B♭M7", "Bbmaj7"},
+		{"C♯°", "C#dim"},
+		{"D°7", "Ddim7"},
+		{"E♭/G", "Eb/G"},
+		{"N.C.", "N.C."},
+	}
+
+	for _, e := range tests {
+		assert.Equal(t, e.abc, abcChordName(e.name))
+	}
+}
+
+func TestABCKey(t *testing.T) {
+	assert.Equal(t, "C", abcKey("C", "Major"))
+	assert.Equal(t, "F#m", abcKey("F♯", "Minor"))
+	assert.Equal(t, "Bb", abcKey("B♭", "Major"))
+	assert.Equal(t, "none", abcKey("D♯", "Major"))
+}
+
+func TestWriteABC(t *testing.T) {
+	m := model{key: "A", scale: "Minor", scaleIntervals: scaleIntervals["Minor"]}
+	m.scaleNotes = enumerateScale(m.key, m.scaleIntervals)
+	m.progression = []chord{m.buildTriads()[0], m.buildSecondaryDoms()[3]}
+
+	var b strings.Builder
+	assert.NoError(t, m.writeABC(&b, []scoreSection{m.scoreSections(exportScale)[0], m.scoreSections(exportProgression)[0]}))
+	assert.Equal(t, `X:1
+T:A Minor
+T:Scale
+M:4/4
+L:1/4
+K:Am
+A B c d | e f g a |]
+
+X:2
+T:A Minor
+T:Progression
+M:4/4
+L:1/4
+K:Am
+"Am"[Ace]4 | "B7"[B^d^fa]4 |]
+w:I V⁷~/~V
+`, b.String())
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"sort"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	segmentBeat = "Beat"
+	segmentBar  = "Bar"
+
+	drumChannel = 9 // General MIDI percussion, which has no pitch
+
+	minNoteShare = 0.25    // part of a segment a note must sound for to be counted in its chord
+	maxSegments  = 1 << 16 // segments analyzed at most, over nine hours of beats at 120 bpm
+	barsPerLine  = 4
+	chartRows    = 3 // rows of the chart shown without scrolling
+
+	noChord      = "N.C."
+	unknownChord = "?"
+)
+
+// analysis is a chord chart of a MIDI file: a chord for every segment and a Roman numeral for every chord, relative to
+// the detected key.
+type analysis struct {
+	key    string
+	scale  string
+	chords []chord
+}
+
+var (
+	segmentations = []string{segmentBeat, segmentBar}
+
+	// Krumhansl-Kessler key profiles: how well each pitch class, counted from the tonic, fits in a major and a minor
+	// key.
+	majorProfile = [chromaticScaleLen]float64{6.35, 2.23, 3.48, 2.33, 4.38, 4.09, 2.52, 5.19, 2.39, 3.66, 2.29, 2.88}
+	minorProfile = [chromaticScaleLen]float64{6.33, 2.68, 3.52, 5.38, 2.60, 3.53, 2.54, 4.75, 3.98, 2.69, 3.34, 3.17}
+
+	romanNumerals = []string{"I", "II", "III", "IV", "V", "VI", "VII"}
+)
+
+// segmentNotes returns, for each segment of length ticks, the MIDI notes that sound for at least minNoteShare of it,
+// lowest first. Percussion is left out, and so is anything after the first maxSegments segments.
+func segmentNotes(song midiSong, length int) [][]int {
+	if length <= 0 {
+		return nil
+	}
+
+	segments := make([]map[int]int, minInt((song.end()+length-1)/length, maxSegments))
+	for _, n := range song.notes {
+		if n.channel == drumChannel {
+			continue
+		}
+		for i := n.tick / length; i < len(segments) && i*length < n.tick+n.length; i++ {
+			start, end := i*length, (i+1)*length
+			overlap := minInt(end, n.tick+n.length) - maxInt(start, n.tick)
+			if segments[i] == nil {
+				segments[i] = make(map[int]int)
+			}
+			segments[i][n.note] += overlap
+		}
+	}
+
+	notes := make([][]int, len(segments))
+	for i, s := range segments {
+		for note, ticks := range s {
+			if float64(ticks) >= minNoteShare*float64(length) {
+				notes[i] = append(notes[i], note)
+			}
+		}
+		sort.Ints(notes[i])
+	}
+
+	return notes
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// pitchWeights returns how long each pitch class sounds in the song, in ticks. Percussion is left out.
+func pitchWeights(song midiSong) [chromaticScaleLen]float64 {
+	var weights [chromaticScaleLen]float64
+	for _, n := range song.notes {
+		if n.channel != drumChannel {
+			weights[pitchClass(n.note)] += float64(n.length)
+		}
+	}
+	return weights
+}
+
+// correlation returns the Pearson correlation of the weights with the profile rotated to start on tonic.
+func correlation(weights, profile [chromaticScaleLen]float64, tonic int) float64 {
+	var meanW, meanP float64
+	for i := range weights {
+		meanW += weights[i] / chromaticScaleLen
+		meanP += profile[i] / chromaticScaleLen
+	}
+
+	var cov, varW, varP float64
+	for i := range weights {
+		w := weights[i] - meanW
+		p := profile[pitchClass(i-tonic)] - meanP
+		cov += w * p
+		varW += w * w
+		varP += p * p
+	}
+	if varW == 0 {
+		return 0
+	}
+
+	return cov / math.Sqrt(varW*varP)
+}
+
+// detectKey returns the key whose profile best fits the weights, spelled as on the circle of fifths, or false if there
+// are no notes.
+func detectKey(weights [chromaticScaleLen]float64) (key, scale string, ok bool) {
+	best := math.Inf(-1)
+	for i := 0; i < circleSegments; i++ {
+		for _, minor := range []bool{false, true} {
+			k, profile, s := circleMajorKeys[i], majorProfile, "Major"
+			if minor {
+				k, profile, s = circleMinorKeys[i], minorProfile, "Minor"
+			}
+			if r := correlation(weights, profile, notePitchClass(k)); r > best {
+				best = r
+				key, scale = k, s
+			}
+		}
+	}
+
+	return key, scale, best != 0
+}
+
+// romanNumeral returns the position of the chord in the key: the position of the first chord of the chord sections
+// with the same root and tones, or the scale degree of its root, flattened or sharpened if it is outside the scale,
+// followed by its quality.
+func romanNumeral(c chordMatch, scaleNotes []string, sections []chordSection) (chord, bool) {
+	for _, sec := range sections {
+		for _, sc := range sec.chords {
+			if c.matches(sc) {
+				return sc, true
+			}
+		}
+	}
+
+	for i, n := range scaleNotes {
+		if notePitchClass(n) == c.root {
+			return chord{position: romanNumerals[i] + c.quality.suffix}, false
+		}
+	}
+	for i, n := range scaleNotes {
+		if notePitchClass(n) == pitchClass(c.root+1) {
+			return chord{position: flat + romanNumerals[i] + c.quality.suffix}, false
+		}
+	}
+	for i, n := range scaleNotes {
+		if notePitchClass(n) == pitchClass(c.root-1) {
+			return chord{position: sharp + romanNumerals[i] + c.quality.suffix}, false
+		}
+	}
+
+	return chord{}, false
+}
+
+// analyzeSong detects the key of the song and identifies the chord of each beat or bar. Chords found in the chord
+// sections of the key are named and spelled as they are there.
+func analyzeSong(song midiSong, segmentation string) analysis {
+	a := analysis{key: keyNames[0], scale: scaleNames[0]}
+	if key, scale, ok := detectKey(pitchWeights(song)); ok {
+		a.key, a.scale = key, scale
+	}
+	km := model{key: a.key, scale: a.scale, scaleIntervals: scaleIntervals[a.scale]}
+	km.scaleNotes = enumerateScale(km.key, km.scaleIntervals)
+	sections := km.chordSections()
+
+	length := song.beatTicks()
+	if segmentation == segmentBar {
+		length *= song.beats
+	}
+	for _, notes := range segmentNotes(song, length) {
+		if len(notes) == 0 {
+			a.chords = append(a.chords, chord{name: noChord})
+			continue
+		}
+
+		match, ok := identifyChord(notes)
+		if !ok {
+			c := chord{name: unknownChord}
+			seen := make(map[int]bool)
+			for _, n := range notes {
+				if pc := pitchClass(n); !seen[pc] {
+					seen[pc] = true
+					c.notes = append(c.notes, spellPitchClass(pc, km.scaleNotes))
+				}
+			}
+			a.chords = append(a.chords, c)
+			continue
+		}
+
+		c := match.spell(spellPitchClass(match.root, km.scaleNotes), km.scaleNotes)
+		numeral, found := romanNumeral(match, km.scaleNotes, sections)
+		c.position = numeral.position
+		if found {
+			c.name, c.notes = numeral.name+match.slash(numeral.notes, km.scaleNotes), numeral.notes
+		}
+		a.chords = append(a.chords, c)
+	}
+
+	return a
+}
+
+// buildAnalysis returns the chord chart of an imported MIDI file.
+func (m *model) buildAnalysis() fyne.CanvasObject {
+	m.analysisGrid = container.NewGridWithColumns(barsPerLine)
+	m.analysisLabel = widget.NewLabel("Import a MIDI file to see its chords")
+	m.segmentSelector = widget.NewSelect(segmentations, func(string) {
+		m.refreshAnalysis()
+	})
+	m.segmentSelector.SetSelected(segmentBar)
+
+	useKey := widget.NewButton("Use Key", func() {
+		key, ok := keyName(m.analysis.key)
+		if m.song == nil || !ok {
+			return
+		}
+		m.scaleSelector.SetSelected(m.analysis.scale)
+		m.keySelector.SetSelected(key)
+	})
+
+	chart := container.NewVScroll(container.NewVBox(m.analysisGrid))
+	chart.SetMinSize(fyne.NewSize(0, chartRows*newChordCard(chord{}, nil, nil).MinSize().Height))
+
+	return container.NewBorder(
+		container.NewHBox(
+			widget.NewButton("Import MIDI…", m.showMIDIImport),
+			widget.NewLabel("Chord Every"),
+			m.segmentSelector,
+			layout.NewSpacer(),
+			m.analysisLabel,
+			useKey,
+		),
+		nil, nil, nil,
+		chart,
+	)
+}
+
+// refreshAnalysis analyzes the imported MIDI file, laying the chart out a bar to a line when there is a chord to a
+// beat and barsPerLine bars to a line otherwise.
+func (m *model) refreshAnalysis() {
+	if m.song == nil {
+		return
+	}
+
+	m.analysis = analyzeSong(*m.song, m.segmentSelector.Selected)
+	columns := barsPerLine
+	if m.segmentSelector.Selected == segmentBeat {
+		columns = m.song.beats
+	}
+	m.analysisGrid.Layout = layout.NewGridLayoutWithColumns(columns)
+	m.fillChordGrid(m.analysis.chords, m.analysisGrid)
+	m.analysisLabel.SetText(fmt.Sprintf("%s: %d/%d, %s %s",
+		m.songName, m.song.beats, m.song.beatUnit, m.analysis.key, m.analysis.scale))
+}
+
+// showMIDIImport asks for a MIDI file and shows its chord chart.
+func (m *model) showMIDIImport() {
+	m.openFile([]string{".mid", ".midi"}, func(r io.Reader, name string) error {
+		song, err := readSMF(r)
+		if err != nil {
+			return err
+		}
+		m.song = &song
+		m.songName = filepath.Base(name)
+		m.refreshAnalysis()
+		m.tabs.Select(m.analysisTab)
+		return nil
+	})
+}
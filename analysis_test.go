@@ -0,0 +1,90 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// songOf returns a song of chords one bar of 4/4 each, with a note of the drum channel that must be ignored.
+func songOf(chords ...[]int) midiSong {
+	song := midiSong{division: ticksPerBeat, beats: 4, beatUnit: 4}
+	bar := 4 * ticksPerBeat
+	for i, c := range chords {
+		for _, n := range c {
+			song.notes = append(song.notes, midiNote{tick: i * bar, length: bar, note: n, velocity: 90})
+		}
+		song.notes = append(song.notes, midiNote{tick: i * bar, length: bar / 8, channel: drumChannel, note: 37})
+	}
+	return song
+}
+
+func TestSegmentNotes(t *testing.T) {
+	song := midiSong{division: 8, beats: 4, beatUnit: 4, notes: []midiNote{
+		{tick: 0, length: 16, note: 60},
+		{tick: 0, length: 1, note: 62}, // too short to count
+		{tick: 7, length: 6, note: 64}, // counted in the second segment only
+		{tick: 16, length: 8, note: 67},
+		{tick: 16, length: 8, note: 36, channel: drumChannel},
+	}}
+
+	assert.Equal(t, [][]int{{60}, {60, 64}, {67}}, segmentNotes(song, 8))
+	assert.Equal(t, [][]int{{60, 64}, {67}}, segmentNotes(song, 16))
+	assert.Nil(t, segmentNotes(song, 0))
+
+	song.notes = append(song.notes, midiNote{tick: 1 << 40, length: 8, note: 72})
+	assert.Len(t, segmentNotes(song, 8), maxSegments)
+}
+
+func TestDetectKey(t *testing.T) {
+	tests := []struct {
+		song  midiSong
+		key   string
+		scale string
+	}{
+		{songOf([]int{60, 64, 67}, []int{65, 69, 72}, []int{67, 71, 74, 77}, []int{60, 64, 67}), "C", "Major"},
+		{songOf([]int{57, 60, 64}, []int{62, 65, 69}, []int{64, 68, 71}, []int{57, 60, 64}), "A", "Minor"},
+		{songOf([]int{63, 67, 70}, []int{68, 72, 75}, []int{70, 74, 77}, []int{63, 67, 70}), "E♭", "Major"},
+		{songOf([]int{56, 59, 63}, []int{61, 64, 68}, []int{63, 67, 70}, []int{56, 59, 63}), "G♯", "Minor"},
+	}
+
+	for _, e := range tests {
+		key, scale, ok := detectKey(pitchWeights(e.song))
+		assert.True(t, ok)
+		assert.Equal(t, e.key, key)
+		assert.Equal(t, e.scale, scale)
+	}
+
+	_, _, ok := detectKey(pitchWeights(songOf()))
+	assert.False(t, ok)
+}
+
+func TestAnalyzeSong(t *testing.T) {
+	song := songOf(
+		[]int{48, 64, 67, 72},
+		[]int{57, 60, 64},
+		[]int{62, 66, 69, 72},
+		[]int{55, 65, 71, 74},
+		nil,
+		[]int{58, 62, 65},
+		[]int{52, 55, 60},
+		[]int{60, 61, 62},
+	)
+
+	a := analyzeSong(song, segmentBar)
+	assert.Equal(t, "C", a.key)
+	assert.Equal(t, "Major", a.scale)
+	var names, positions []string
+	for _, c := range a.chords {
+		names = append(names, c.name)
+		positions = append(positions, c.position)
+	}
+	assert.Equal(t, []string{"C", "Am", "D7", "G7", noChord, "B♭", "C/E", unknownChord}, names)
+	assert.Equal(t, []string{"I", "VI", "V⁷ / V", "V⁷", "", "♭VII", "I", ""}, positions)
+	assert.Equal(t, []string{"D", "F♯", "A", "C"}, a.chords[2].notes)
+	assert.Equal(t, []string{"C", "D♭", "D"}, a.chords[7].notes)
+
+	a = analyzeSong(song, segmentBeat)
+	assert.Equal(t, 4*8, len(a.chords))
+	assert.Equal(t, "Am", a.chords[5].name)
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	serveCommand = "serve"
+	apiPrefix    = "/api/v1/"
+)
+
+// apiRoutes are the handlers of the API endpoints by their paths below apiPrefix. The chord section of "chords/" is
+// the rest of the path.
+var apiRoutes = map[string]apiHandler{
+	"keys":      apiKeys,
+	"scale":     apiScale,
+	"chords":    apiChords,
+	"chords/":   apiSection,
+	"chord":     apiChord,
+	"identify":  apiIdentify,
+	"transpose": apiTranspose,
+}
+
+// openAPI is the OpenAPI description of the API.
+//
+//go:embed openapi.json
+var openAPI []byte
+
+type (
+	// apiHandler answers a GET request to the API with a value to write as JSON, or with an error.
+	apiHandler func(r *http.Request) (interface{}, error)
+
+	// apiError is an error with the HTTP status of the response it is answered with.
+	apiError struct {
+		status int
+		err    error
+	}
+
+	// keysData lists the keys, scales and chord sections that the API accepts.
+	keysData struct {
+		Keys     []string `json:"keys"`
+		Scales   []string `json:"scales"`
+		Sections []string `json:"sections"`
+	}
+
+	// identification is a chord identified from its notes.
+	identification struct {
+		Chord   chordData `json:"chord"`
+		NoFifth bool      `json:"noFifth"` // the perfect fifth of the chord was missing from the notes
+	}
+
+	// transposition is a list of chords moved from one key to another.
+	transposition struct {
+		From   string   `json:"from"`
+		To     string   `json:"to"`
+		Scale  string   `json:"scale"`
+		Chords []string `json:"chords"`
+	}
+)
+
+func (e apiError) Error() string {
+	return e.err.Error()
+}
+
+func badRequest(format string, a ...interface{}) error {
+	return apiError{http.StatusBadRequest, fmt.Errorf(format, a...)}
+}
+
+func notFound(format string, a ...interface{}) error {
+	return apiError{http.StatusNotFound, fmt.Errorf(format, a...)}
+}
+
+// runServe runs the serve command with its arguments, answering API requests until the server fails.
+func runServe(args []string) error {
+	fs := flag.NewFlagSet(serveCommand, flag.ContinueOnError)
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	origin := fs.String("origin", "*", "origin of the web pages allowed to call the API, or empty for none")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           newAPIHandler(*origin),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Printf("Serving the API at http://%s%s, described by %sopenapi.json", *addr, apiPrefix, apiPrefix)
+	return server.ListenAndServe()
+}
+
+// newAPIHandler returns the handler of all the API endpoints, allowing calls from web pages of the origin.
+func newAPIHandler(origin string) http.Handler {
+	mux := http.NewServeMux()
+	for path, h := range apiRoutes {
+		mux.Handle(apiPrefix+path, h)
+	}
+	mux.HandleFunc(apiPrefix+"openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(openAPI)
+	})
+	mux.Handle("/", apiHandler(func(r *http.Request) (interface{}, error) {
+		return nil, notFound("no endpoint %s, see %sopenapi.json", r.URL.Path, apiPrefix)
+	}))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin != "" {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeAPIJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "only GET is allowed"})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// apiRoute returns the handler of the path below apiPrefix.
+func apiRoute(path string) (apiHandler, bool) {
+	if strings.HasPrefix(path, "chords/") {
+		path = "chords/"
+	}
+	h, ok := apiRoutes[path]
+	return h, ok
+}
+
+// answer returns the status and the value of the response to the request, which is an object with an error field if
+// the request failed.
+func (h apiHandler) answer(r *http.Request) (int, interface{}) {
+	v, err := h(r)
+	if err != nil {
+		status := http.StatusInternalServerError
+		var e apiError
+		if errors.As(err, &e) {
+			status = e.status
+		}
+		return status, map[string]string{"error": err.Error()}
+	}
+	return http.StatusOK, v
+}
+
+func (h apiHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	status, v := h.answer(r)
+	writeAPIJSON(w, status, v)
+}
+
+func writeAPIJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(v)
+}
+
+// queryModel returns a model of the key and scale of the query, C major unless given.
+func queryModel(r *http.Request) (*model, error) {
+	key, scale := keyNames[0], scaleNames[0]
+	if k := r.URL.Query().Get("key"); k != "" {
+		key = k
+	}
+	if s := r.URL.Query().Get("scale"); s != "" {
+		scale = s
+	}
+	m, err := keyModel(key, scale)
+	if err != nil {
+		return nil, apiError{http.StatusBadRequest, err}
+	}
+	return m, nil
+}
+
+// queryList returns the comma-separated values of the query parameter, without spaces around them.
+func queryList(r *http.Request, name string) []string {
+	var values []string
+	for _, v := range strings.Split(r.URL.Query().Get(name), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func apiKeys(*http.Request) (interface{}, error) {
+	return keysData{Keys: keyNames, Scales: scaleNames, Sections: sectionFlags()}, nil
+}
+
+func apiScale(r *http.Request) (interface{}, error) {
+	m, err := queryModel(r)
+	if err != nil {
+		return nil, err
+	}
+	return m.keyData(nil), nil
+}
+
+func apiChords(r *http.Request) (interface{}, error) {
+	m, err := queryModel(r)
+	if err != nil {
+		return nil, err
+	}
+	sections := sectionFlags()
+	if s := r.URL.Query().Get("sections"); s != "" {
+		if sections, err = parseSections(s); err != nil {
+			return nil, apiError{http.StatusBadRequest, err}
+		}
+	}
+	return m.keyData(sections), nil
+}
+
+func apiSection(r *http.Request) (interface{}, error) {
+	id := strings.TrimPrefix(r.URL.Path, apiPrefix+"chords/")
+	if !contains(sectionFlags(), id) {
+		return nil, notFound("unknown section %q, expected one of %s", id, strings.Join(sectionFlags(), ", "))
+	}
+	m, err := queryModel(r)
+	if err != nil {
+		return nil, err
+	}
+	return m.keyData([]string{id}).Sections[0], nil
+}
+
+func apiChord(r *http.Request) (interface{}, error) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		return nil, badRequest("missing chord name")
+	}
+	root, q, bass, ok := parseChord(name)
+	if !ok {
+		return nil, badRequest("unknown chord %q", name)
+	}
+	c := chord{name: root + q.suffix, notes: spellChord(root, q)}
+	if bass != "" {
+		c.name += "/" + bass
+	}
+	return newChordData(c), nil
+}
+
+// apiIdentify names the chord of notes given as MIDI note numbers in any order, or as note names from the lowest up,
+// each above the one before. The root is spelled as among the notes, or else as in the key and
+// scale of the query.
+func apiIdentify(r *http.Request) (interface{}, error) {
+	values := queryList(r, "notes")
+	if len(values) == 0 {
+		return nil, badRequest("missing notes")
+	}
+
+	var notes []int
+	names := map[int]string{}
+	for _, v := range values {
+		if n, err := strconv.Atoi(v); err == nil {
+			if n < 0 || n > 127 {
+				return nil, badRequest("MIDI note %d is out of range", n)
+			}
+			notes = append(notes, n)
+			continue
+		}
+		step, alter, ok := parseNote(v)
+		if !ok {
+			return nil, badRequest("unknown note %q", v)
+		}
+		name := pitch{step: step, alter: alter}.name()
+		n := 60 + notePitchClass(name)
+		for len(notes) > 0 && n <= notes[len(notes)-1] {
+			n += chromaticScaleLen
+		}
+		notes = append(notes, n)
+		names[pitchClass(n)] = name
+	}
+
+	match, ok := identifyChord(notes)
+	if !ok {
+		err := fmt.Errorf("notes %s form no known chord", strings.Join(values, ","))
+		return nil, apiError{http.StatusUnprocessableEntity, err}
+	}
+	var scaleNotes []string
+	if r.URL.Query().Get("key") != "" {
+		m, err := queryModel(r)
+		if err != nil {
+			return nil, err
+		}
+		scaleNotes = m.scaleNotes
+	}
+	root, found := names[match.root]
+	if !found {
+		root = spellPitchClass(match.root, scaleNotes)
+	}
+
+	return identification{Chord: newChordData(match.spell(root, scaleNotes)), NoFifth: match.noFifth}, nil
+}
+
+func apiTranspose(r *http.Request) (interface{}, error) {
+	q := r.URL.Query()
+	scale := q.Get("scale")
+	if scale == "" {
+		scale = scaleNames[0]
+	}
+	if _, ok := scaleIntervals[scale]; !ok {
+		return nil, badRequest("unknown scale %q", scale)
+	}
+	from, ok := keyName(q.Get("from"))
+	if !ok {
+		return nil, badRequest("unknown key %q to transpose from", q.Get("from"))
+	}
+	to, ok := keyName(q.Get("to"))
+	if !ok {
+		return nil, badRequest("unknown key %q to transpose to", q.Get("to"))
+	}
+
+	t := transposition{From: from, To: to, Scale: scale, Chords: []string{}}
+	spell := transposer(from, to, scale)
+	for _, c := range queryList(r, "chords") {
+		if _, _, _, ok := splitChordName(c); !ok {
+			return nil, badRequest("unknown chord %q", c)
+		}
+		t.Chords = append(t.Chords, transposeChord(c, spell))
+	}
+	return t, nil
+}
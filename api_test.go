@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// apiGet sends a request to the API and returns the status and the decoded JSON of the response.
+func apiGet(t *testing.T, method, target string) (int, map[string]interface{}) {
+	rec := httptest.NewRecorder()
+	newAPIHandler("*").ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"), target)
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"), target)
+
+	var body map[string]interface{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body), target)
+	return rec.Code, body
+}
+
+func TestAPI(t *testing.T) {
+	tests := []struct {
+		target   string
+		expected string // JSON of the response
+	}{
+		{
+			"/api/v1/scale?key=Eb&scale=Minor",
+			`{"version":1,"key":"E♭","scale":"Minor","scaleNotes":["E♭","F","G♭","A♭","B♭","C♭","D♭"],"sections":[]}`,
+		},
+		{
+			"/api/v1/scale?key=" + url.QueryEscape("G#") + "&scale=Minor",
+			`{"version":1,"key":"G♯","scale":"Minor","scaleNotes":["G♯","A♯","B","C♯","D♯","E","F♯"],"sections":[]}`,
+		},
+		{
+			"/api/v1/chords/tritone-substitution?key=G",
+			`{"id":"tritone-substitution","title":"Tritone Substitution","chords":[{"name":"A♭7","position":"sub VII⁷ / V⁷",
+				"root":"A♭","quality":"dominant seventh","notes":["A♭","C","E♭","G♭"],"pitchClasses":[8,0,3,6],
+				"intervals":[0,4,7,10]}]}`,
+		},
+		{
+			"/api/v1/chord?name=" + url.QueryEscape("Bbm7b5/E"),
+			`{"name":"B♭m7♭5/E","root":"B♭","bass":"E","quality":"half-diminished seventh","notes":["B♭","D♭","F♭","A♭"],
+				"pitchClasses":[10,1,4,8],"intervals":[0,3,6,10]}`,
+		},
+		{
+			"/api/v1/chord?name=" + url.QueryEscape("F#maj7"),
+			`{"name":"F♯M7","root":"F♯","quality":"major seventh","notes":["F♯","A♯","C♯","E♯"],"pitchClasses":[6,10,1,5],
+				"intervals":[0,4,7,11]}`,
+		},
+		{
+			"/api/v1/identify?notes=B,D,F,G",
+			`{"chord":{"name":"G7/B","root":"G","bass":"B","quality":"dominant seventh","notes":["G","B","D","F"],
+				"pitchClasses":[7,11,2,5],"intervals":[0,4,7,10]},"noFifth":false}`,
+		},
+		{
+			"/api/v1/identify?notes=70,62,65,68&key=Bb",
+			`{"chord":{"name":"B♭7/D","root":"B♭","bass":"D","quality":"dominant seventh","notes":["B♭","D","F","A♭"],
+				"pitchClasses":[10,2,5,8],"intervals":[0,4,7,10]},"noFifth":false}`,
+		},
+		{
+			"/api/v1/transpose?chords=C,Am7,F/A,G7&from=C&to=Eb",
+			`{"from":"C","to":"E♭","scale":"Major","chords":["E♭","Cm7","A♭/C","B♭7"]}`,
+		},
+	}
+
+	for _, e := range tests {
+		status, body := apiGet(t, http.MethodGet, e.target)
+		assert.Equal(t, http.StatusOK, status, e.target)
+
+		var expected map[string]interface{}
+		if assert.NoError(t, json.Unmarshal([]byte(e.expected), &expected), e.target) {
+			assert.Equal(t, expected, body, e.target)
+		}
+	}
+}
+
+func TestAPIChords(t *testing.T) {
+	status, body := apiGet(t, http.MethodGet, "/api/v1/chords?key=D&sections=triads,sevenths")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "D", body["key"])
+	assert.Len(t, body["sections"], 2)
+
+	status, body = apiGet(t, http.MethodGet, "/api/v1/chords")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, body["sections"], len(sectionFlags()))
+
+	status, body = apiGet(t, http.MethodGet, "/api/v1/keys")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, body["keys"], len(keyNames))
+}
+
+func TestAPIErrors(t *testing.T) {
+	tests := []struct {
+		method string
+		target string
+		status int
+		error  string
+	}{
+		{http.MethodGet, "/api/v1/scale?key=H", http.StatusBadRequest, `unknown key "H"`},
+		{http.MethodGet, "/api/v1/scale?scale=Dorian", http.StatusBadRequest, `unknown scale "Dorian"`},
+		{http.MethodGet, "/api/v1/chords?sections=ninths", http.StatusBadRequest,
+			`unknown section "ninths", expected one of triads, sevenths, secondary-dominants, secondary-lead-tones, ` +
+				`tritone-substitution`},
+		{http.MethodGet, "/api/v1/chords/ninths", http.StatusNotFound,
+			`unknown section "ninths", expected one of triads, sevenths, secondary-dominants, secondary-lead-tones, ` +
+				`tritone-substitution`},
+		{http.MethodGet, "/api/v1/chords/triads?key=X", http.StatusBadRequest, `unknown key "X"`},
+		{http.MethodGet, "/api/v1/chord", http.StatusBadRequest, "missing chord name"},
+		{http.MethodGet, "/api/v1/chord?name=Cxyz", http.StatusBadRequest, `unknown chord "Cxyz"`},
+		{http.MethodGet, "/api/v1/chord?name=Cbbbm7", http.StatusBadRequest, `unknown chord "Cbbbm7"`},
+		{http.MethodGet, "/api/v1/chord?name=" + url.QueryEscape("E###"), http.StatusBadRequest, `unknown chord "E###"`},
+		{http.MethodGet, "/api/v1/chord?name=" + url.QueryEscape("C/Bbbb"), http.StatusBadRequest,
+			`unknown chord "C/Bbbb"`},
+		{http.MethodGet, "/api/v1/identify", http.StatusBadRequest, "missing notes"},
+		{http.MethodGet, "/api/v1/identify?notes=C,Q", http.StatusBadRequest, `unknown note "Q"`},
+		{http.MethodGet, "/api/v1/identify?notes=Cbbb,E,G", http.StatusBadRequest, `unknown note "Cbbb"`},
+		{http.MethodGet, "/api/v1/identify?notes=60,128", http.StatusBadRequest, "MIDI note 128 is out of range"},
+		{http.MethodGet, "/api/v1/identify?notes=C,D", http.StatusUnprocessableEntity, "notes C,D form no known chord"},
+		{http.MethodGet, "/api/v1/transpose?chords=C&to=D", http.StatusBadRequest, `unknown key "" to transpose from`},
+		{http.MethodGet, "/api/v1/transpose?chords=C&from=C&to=Q", http.StatusBadRequest,
+			`unknown key "Q" to transpose to`},
+		{http.MethodGet, "/api/v1/transpose?chords=C,xyz&from=C&to=D", http.StatusBadRequest, `unknown chord "xyz"`},
+		{http.MethodGet, "/api/v1/transpose?chords=Cbbb&from=C&to=D", http.StatusBadRequest, `unknown chord "Cbbb"`},
+		{http.MethodGet, "/api/v1/nope", http.StatusNotFound, "no endpoint /api/v1/nope, see /api/v1/openapi.json"},
+		{http.MethodPost, "/api/v1/keys", http.StatusMethodNotAllowed, "only GET is allowed"},
+	}
+
+	for _, e := range tests {
+		status, body := apiGet(t, e.method, e.target)
+		assert.Equal(t, e.status, status, e.target)
+		assert.Equal(t, e.error, body["error"], e.target)
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newAPIHandler("").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+
+	var doc struct {
+		Paths      map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas struct {
+				SectionID struct {
+					Enum []string `json:"enum"`
+				} `json:"SectionID"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc)) {
+		for _, path := range []string{"keys", "scale", "chords", "chords/{section}", "chord", "identify", "transpose"} {
+			assert.Contains(t, doc.Paths, "/"+path)
+		}
+		assert.Equal(t, sectionFlags(), doc.Components.Schemas.SectionID.Enum)
+	}
+}
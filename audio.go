@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	minTempo = 40
+	maxTempo = 240
+
+	chordOctave = 4 // octave of the root when a chord is played
+)
+
+type (
+	// audioOutput plays rendered samples, replacing anything it is still playing.
+	audioOutput interface {
+		play(samples []float32) error
+	}
+
+	// wavOutput writes each rendering to a WAV file instead of playing it, which is useful for testing without an
+	// audio device.
+	wavOutput struct {
+		path string
+	}
+)
+
+func (o wavOutput) play(samples []float32) error {
+	f, err := os.Create(o.path)
+	if err != nil {
+		return err
+	}
+	if err := writeWAV(f, samples, sampleRate); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// newAudioOutput returns an output writing to the WAV file at path, or playing on the audio device if path is empty.
+func newAudioOutput(path string) (audioOutput, error) {
+	if path != "" {
+		return wavOutput{path}, nil
+	}
+	return newDeviceOutput()
+}
+
+// midiNotes returns the MIDI note numbers of the pitches.
+func midiNotes(pitches []pitch) []int {
+	midis := make([]int, 0, len(pitches))
+	for _, p := range pitches {
+		midis = append(midis, p.midi())
+	}
+	return midis
+}
+
+// play renders the notes and plays them in the background, and sends them to the MIDI output if one is open.
+func (m *model) play(notes []synthNote) {
+	if m.midiOut != nil {
+		m.midiOut.play(notes)
+	}
+	if m.audio == nil {
+		return
+	}
+
+	s := m.synth
+	go func() {
+		if err := m.audio.play(s.render(notes)); err != nil {
+			fyne.LogError("Unable to play audio", err)
+		}
+	}()
+}
+
+// playChord plays the chord with its root in chordOctave, using the current play mode.
+func (m *model) playChord(c chord) {
+	m.play(m.synth.chordNotes(midiNotes(voiceNotes(c.notes, chordOctave))))
+}
+
+// playProgression plays the chords of the progression one after the other.
+func (m *model) playProgression() {
+	var chords [][]int
+	for _, c := range m.progression {
+		chords = append(chords, midiNotes(voiceNotes(c.notes, chordOctave)))
+	}
+	m.play(m.synth.progressionNotes(chords))
+}
+
+// playScale plays the scale upwards from the tonic in chordOctave to the tonic an octave higher.
+func (m *model) playScale() {
+	notes := append(append([]string{}, m.scaleNotes...), m.scaleNotes[0])
+	m.play(m.synth.melodyNotes(midiNotes(voiceNotes(notes, chordOctave))))
+}
+
+// buildSoundControls returns the selectors for the instrument sound, chord play mode and tempo.
+func (m *model) buildSoundControls() fyne.CanvasObject {
+	waveformSelector := widget.NewSelect(waveforms, func(s string) {
+		m.synth.waveform = s
+	})
+	waveformSelector.SetSelected(m.synth.waveform)
+
+	modeSelector := widget.NewSelect(playModes, func(s string) {
+		m.synth.mode = s
+	})
+	modeSelector.SetSelected(m.synth.mode)
+
+	tempoLabel := widget.NewLabel("")
+	tempoSlider := widget.NewSlider(minTempo, maxTempo)
+	tempoSlider.Step = 1
+	tempoSlider.OnChanged = func(v float64) {
+		m.synth.tempo = int(v)
+		tempoLabel.SetText(fmt.Sprintf("%d BPM", m.synth.tempo))
+	}
+	tempoSlider.SetValue(float64(m.synth.tempo))
+
+	return container.NewBorder(nil, nil,
+		container.NewHBox(
+			widget.NewLabel("Sound"),
+			waveformSelector,
+			widget.NewLabel("Chords"),
+			modeSelector,
+			widget.NewLabel("Tempo"),
+		),
+		tempoLabel,
+		tempoSlider,
+	)
+}
@@ -0,0 +1,48 @@
+//go:build !ci
+
+package main
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/ebitengine/oto/v3"
+)
+
+// deviceOutput plays audio on the default audio device.
+type deviceOutput struct {
+	ctx *oto.Context
+
+	mu     sync.Mutex
+	player *oto.Player
+}
+
+// newDeviceOutput opens the audio device. It may only be called once.
+func newDeviceOutput() (audioOutput, error) {
+	ctx, ready, err := oto.NewContext(&oto.NewContextOptions{
+		SampleRate:   sampleRate,
+		ChannelCount: 1,
+		Format:       oto.FormatSignedInt16LE,
+	})
+	if err != nil {
+		return nil, err
+	}
+	<-ready
+
+	return &deviceOutput{ctx: ctx}, nil
+}
+
+func (o *deviceOutput) play(samples []float32) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.player != nil {
+		if err := o.player.Close(); err != nil {
+			return err
+		}
+	}
+	o.player = o.ctx.NewPlayer(bytes.NewReader(pcm16(samples)))
+	o.player.Play()
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"image/color"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+)
+
+// tappableLabel is a label that reports when it is tapped.
+type tappableLabel struct {
+	widget.Label
+
+	tapped func()
+}
+
+// chordCard is a card showing a single chord that reports when it is hovered or tapped. It can be highlighted, as
+// when its chord is played on a MIDI keyboard.
+type chordCard struct {
+	widget.Card
+
+	chord       chord
+	hovered     func(c *chord) // called with nil when the pointer leaves the card
+	tapped      func(c chord)
+	mu          sync.Mutex // guards highlighted, which is set from the goroutine reading the MIDI input
+	highlighted bool
+}
+
+// chordCardRenderer draws a card with a tint over it when it is highlighted.
+type chordCardRenderer struct {
+	fyne.WidgetRenderer
+
+	c    *chordCard
+	tint *canvas.Rectangle
+}
+
+var cardHighlightColor = color.NRGBA{R: 0x1e, G: 0x88, B: 0xe5, A: 0x60}
+
+var (
+	_ fyne.Tappable     = (*tappableLabel)(nil)
+	_ fyne.Tappable     = (*chordCard)(nil)
+	_ desktop.Hoverable = (*chordCard)(nil)
+)
+
+func newTappableLabel(text string, tapped func()) *tappableLabel {
+	l := &tappableLabel{tapped: tapped}
+	l.Text = text
+	l.ExtendBaseWidget(l)
+
+	return l
+}
+
+func (l *tappableLabel) Tapped(*fyne.PointEvent) {
+	if l.tapped != nil {
+		l.tapped()
+	}
+}
+
+func newChordCard(c chord, hovered func(*chord), tapped func(chord)) *chordCard {
+	card := &chordCard{
+		chord:   c,
+		hovered: hovered,
+		tapped:  tapped,
+	}
+	card.Title = c.name
+	card.Subtitle = c.position
+	card.Content = widget.NewLabel(strings.Join(c.notes, " "))
+	card.ExtendBaseWidget(card)
+
+	return card
+}
+
+func (c *chordCard) Tapped(*fyne.PointEvent) {
+	if c.tapped != nil {
+		c.tapped(c.chord)
+	}
+}
+
+func (c *chordCard) MouseIn(*desktop.MouseEvent) {
+	if c.hovered != nil {
+		c.hovered(&c.chord)
+	}
+}
+
+func (c *chordCard) MouseMoved(*desktop.MouseEvent) {}
+
+func (c *chordCard) MouseOut() {
+	if c.hovered != nil {
+		c.hovered(nil)
+	}
+}
+
+func (c *chordCard) setHighlighted(highlighted bool) {
+	c.mu.Lock()
+	changed := c.highlighted != highlighted
+	c.highlighted = highlighted
+	c.mu.Unlock()
+	if changed {
+		c.Refresh()
+	}
+}
+
+func (c *chordCard) isHighlighted() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.highlighted
+}
+
+func (c *chordCard) CreateRenderer() fyne.WidgetRenderer {
+	c.ExtendBaseWidget(c)
+	r := &chordCardRenderer{WidgetRenderer: c.Card.CreateRenderer(), c: c, tint: canvas.NewRectangle(color.Transparent)}
+	r.Refresh()
+
+	return r
+}
+
+func (r *chordCardRenderer) Layout(size fyne.Size) {
+	r.WidgetRenderer.Layout(size)
+	r.tint.Resize(size)
+}
+
+func (r *chordCardRenderer) Objects() []fyne.CanvasObject {
+	return append(r.WidgetRenderer.Objects(), r.tint)
+}
+
+func (r *chordCardRenderer) Refresh() {
+	r.tint.FillColor = color.Transparent
+	if r.c.isHighlighted() {
+		r.tint.FillColor = cardHighlightColor
+	}
+	r.tint.Refresh()
+	r.WidgetRenderer.Refresh()
+}
@@ -0,0 +1,264 @@
+package main
+
+import "strings"
+
+type (
+	// chordQuality is a kind of chord, such as a minor seventh, given by the semitones of its tones above the root and
+	// by the number of letters each tone is above the root, so that the tones can be spelled.
+	chordQuality struct {
+		suffix    string
+		intervals []int
+		degrees   []int
+	}
+
+	// chordMatch is a chord identified from a set of notes.
+	chordMatch struct {
+		root    int // pitch class, with C as 0
+		bass    int // pitch class of the lowest note
+		quality chordQuality
+		noFifth bool // the perfect fifth of the chord was missing from the notes
+	}
+)
+
+var (
+	// chordQualities are tried in order, so a set of notes that can be read more than one way with the same root, such
+	// as C E G A, is named after the earlier quality.
+	chordQualities = []chordQuality{
+		{"", []int{0, 4, 7}, []int{0, 2, 4}},
+		{"m", []int{0, 3, 7}, []int{0, 2, 4}},
+		{"°", []int{0, 3, 6}, []int{0, 2, 4}},
+		{"+", []int{0, 4, 8}, []int{0, 2, 4}},
+		{"sus4", []int{0, 5, 7}, []int{0, 3, 4}},
+		{"sus2", []int{0, 2, 7}, []int{0, 1, 4}},
+		{"7", []int{0, 4, 7, 10}, []int{0, 2, 4, 6}},
+		{"M7", []int{0, 4, 7, 11}, []int{0, 2, 4, 6}},
+		{"m7", []int{0, 3, 7, 10}, []int{0, 2, 4, 6}},
+		{"m7♭5", []int{0, 3, 6, 10}, []int{0, 2, 4, 6}},
+		{"°7", []int{0, 3, 6, 9}, []int{0, 2, 4, 6}},
+		{"mM7", []int{0, 3, 7, 11}, []int{0, 2, 4, 6}},
+		{"+M7", []int{0, 4, 8, 11}, []int{0, 2, 4, 6}},
+		{"7sus4", []int{0, 5, 7, 10}, []int{0, 3, 4, 6}},
+		{"6", []int{0, 4, 7, 9}, []int{0, 2, 4, 5}},
+		{"m6", []int{0, 3, 7, 9}, []int{0, 2, 4, 5}},
+		{"add9", []int{0, 2, 4, 7}, []int{0, 1, 2, 4}},
+		{"9", []int{0, 2, 4, 7, 10}, []int{0, 1, 2, 4, 6}},
+		{"M9", []int{0, 2, 4, 7, 11}, []int{0, 1, 2, 4, 6}},
+		{"m9", []int{0, 2, 3, 7, 10}, []int{0, 1, 2, 4, 6}},
+		{"5", []int{0, 7}, []int{0, 4}},
+	}
+
+	// asciiSuffixes spells the ASCII accidentals of chord suffixes, as in "m7b5", with music symbols.
+	asciiSuffixes = strings.NewReplacer("b", flat, "#", sharp)
+)
+
+// splitChordName splits a chord name such as "F♯m7♭5/C" into its root, suffix and bass, which is empty unless the
+// name has a slash. ASCII accidentals are accepted in the root and bass.
+func splitChordName(name string) (root, suffix, bass string, ok bool) {
+	if i := strings.LastIndex(name, "/"); i > 0 {
+		name, bass = name[:i], name[i+1:]
+		if _, _, ok := parseNote(bass); !ok {
+			return "", "", "", false
+		}
+	}
+	if name == "" || !strings.ContainsRune(steps, rune(name[0])) {
+		return "", "", "", false
+	}
+
+	end := 1
+	for n := accidentalLength(name[end:]); n > 0; n = accidentalLength(name[end:]) {
+		end += n
+	}
+	if _, _, ok := parseNote(name[:end]); !ok {
+		return "", "", "", false
+	}
+
+	return name[:end], name[end:], bass, true
+}
+
+// parseChord reads a chord name such as "F#m7b5/C" or "Ebmaj7", with ASCII accidentals or ChordPro suffixes, and
+// returns its root, quality and bass, spelled with music symbols. The bass is empty unless the name has a slash.
+func parseChord(name string) (root string, q chordQuality, bass string, ok bool) {
+	root, suffix, bass, ok := splitChordName(name)
+	if !ok {
+		return "", chordQuality{}, "", false
+	}
+	if s, found := chordProSuffixes[suffix]; found {
+		suffix = s
+	}
+	if q, ok = qualityOf(asciiSuffixes.Replace(suffix)); !ok {
+		return "", chordQuality{}, "", false
+	}
+
+	step, alter, _ := parseNote(root)
+	root = pitch{step: step, alter: alter}.name()
+	if bass != "" {
+		step, alter, _ = parseNote(bass)
+		bass = pitch{step: step, alter: alter}.name()
+	}
+	return root, q, bass, true
+}
+
+// accidentalLength returns the length in bytes of the accidental that s starts with, or 0.
+func accidentalLength(s string) int {
+	if strings.HasPrefix(s, "#") || strings.HasPrefix(s, "b") {
+		return 1
+	}
+	for _, a := range []string{sharp, flat, doubleSharp, doubleFlat} {
+		if strings.HasPrefix(s, a) {
+			return len(a)
+		}
+	}
+	return 0
+}
+
+// qualityOf returns the chord quality with the suffix.
+func qualityOf(suffix string) (chordQuality, bool) {
+	for _, q := range chordQualities {
+		if q.suffix == suffix {
+			return q, true
+		}
+	}
+	return chordQuality{}, false
+}
+
+// pitchClass returns the pitch class of a MIDI note, with C as 0.
+func pitchClass(midi int) int {
+	return (midi%chromaticScaleLen + chromaticScaleLen) % chromaticScaleLen
+}
+
+// notePitchClass returns the pitch class of a note name, which may have double accidentals.
+func notePitchClass(note string) int {
+	step, alter, _ := parseNote(note)
+	return pitchClass(stepSemitones[step] + alter)
+}
+
+// mask returns the set of pitch classes of the quality built on root, one bit for each.
+func (q chordQuality) mask(root int) int {
+	var mask int
+	for _, i := range q.intervals {
+		mask |= 1 << pitchClass(root+i)
+	}
+	return mask
+}
+
+// identifyChord names the chord formed by the MIDI notes, regardless of octave, doubling and order. A chord missing
+// only its fifth is recognized if it has at least four tones. When the notes can be read as more than one chord, a
+// chord with the lowest note as its root is preferred, so that C E G A is C6 and A C E G is Am7.
+func identifyChord(notes []int) (chordMatch, bool) {
+	if len(notes) == 0 {
+		return chordMatch{}, false
+	}
+
+	bass := notes[0]
+	var set int
+	for _, n := range notes {
+		if n < bass {
+			bass = n
+		}
+		set |= 1 << pitchClass(n)
+	}
+	bass = pitchClass(bass)
+
+	var best chordMatch
+	bestScore := 0
+	for i := 0; i < chromaticScaleLen; i++ {
+		root := (bass + i) % chromaticScaleLen
+		if set&(1<<root) == 0 {
+			continue
+		}
+		fifth := 1 << pitchClass(root+7)
+		for _, q := range chordQualities {
+			mask := q.mask(root)
+			var score int
+			switch {
+			case mask == set:
+				score = 4
+			case len(q.intervals) >= 4 && mask&fifth != 0 && mask&^fifth == set:
+				score = 2
+			default:
+				continue
+			}
+			if root == bass {
+				score++
+			}
+			if score > bestScore {
+				best = chordMatch{root: root, bass: bass, quality: q, noFifth: score < 4}
+				bestScore = score
+			}
+		}
+	}
+
+	return best, bestScore > 0
+}
+
+// matches reports whether c has the root and the tones of the identified chord, in any spelling.
+func (m chordMatch) matches(c chord) bool {
+	if len(c.notes) == 0 || notePitchClass(c.notes[0]) != m.root {
+		return false
+	}
+	var mask int
+	for _, n := range c.notes {
+		mask |= 1 << notePitchClass(n)
+	}
+
+	return mask == m.quality.mask(m.root)
+}
+
+// spellPitchClass names the pitch class as it is spelled in the scale. Other pitch classes are spelled as the scale
+// note above them flattened, as in the ♭VII of a major key, or else as the scale note below them sharpened.
+func spellPitchClass(pc int, scaleNotes []string) string {
+	for _, n := range scaleNotes {
+		if notePitchClass(n) == pc {
+			return n
+		}
+	}
+	for _, d := range []int{1, -1} {
+		for _, n := range scaleNotes {
+			if p, _ := newPitch(n, 0); pitchClass(p.midi()-d) == pc && p.alter-d >= -1 && p.alter-d <= 1 {
+				p.alter -= d
+				return p.name()
+			}
+		}
+	}
+
+	return chromaticScale[pc]
+}
+
+// spellChord spells the tones of the quality upwards from the named root, so that the tones of A♭ are A♭ C E♭ and
+// those of G♯ are G♯ B♯ D♯.
+func spellChord(root string, q chordQuality) []string {
+	step, alter, ok := parseNote(root)
+	if !ok {
+		return nil
+	}
+	index := strings.IndexByte(steps, step)
+	rootPC := stepSemitones[step] + alter
+
+	notes := make([]string, 0, len(q.intervals))
+	for i, interval := range q.intervals {
+		s := steps[(index+q.degrees[i])%len(steps)]
+		a := pitchClass(rootPC+interval-stepSemitones[s]+6) - 6
+		notes = append(notes, pitch{step: s, alter: a}.name())
+	}
+
+	return notes
+}
+
+// spell names the chord and spells its tones with the root spelled as given.
+func (c chordMatch) spell(root string, scaleNotes []string) chord {
+	notes := spellChord(root, c.quality)
+	return chord{name: root + c.quality.suffix + c.slash(notes, scaleNotes), notes: notes}
+}
+
+// slash returns the bass after a slash when it is not the root, spelled as among the notes of the chord, or "".
+func (c chordMatch) slash(notes []string, scaleNotes []string) string {
+	if c.bass == c.root {
+		return ""
+	}
+	for _, n := range notes {
+		if notePitchClass(n) == c.bass {
+			return "/" + n
+		}
+	}
+	return "/" + spellPitchClass(c.bass, scaleNotes)
+}
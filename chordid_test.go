@@ -0,0 +1,106 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentifyChord(t *testing.T) {
+	tests := []struct {
+		notes   []int
+		root    int
+		bass    int
+		suffix  string
+		noFifth bool
+	}{
+		{[]int{60, 64, 67}, 0, 0, "", false},
+		{[]int{64, 67, 72}, 0, 4, "", false},
+		{[]int{57, 60, 64}, 9, 9, "m", false},
+		{[]int{59, 62, 65}, 11, 11, "°", false},
+		{[]int{43, 59, 62, 65, 67}, 7, 7, "7", false},
+		{[]int{60, 64, 70}, 0, 0, "7", true},
+		{[]int{60, 64, 67, 69}, 0, 0, "6", false},
+		{[]int{57, 60, 64, 67}, 9, 9, "m7", false},
+		{[]int{60, 65, 67}, 0, 0, "sus4", false},
+		{[]int{67, 72, 74}, 7, 7, "sus4", false},
+		{[]int{62, 65, 68, 71}, 2, 2, "°7", false},
+		{[]int{48, 55, 60}, 0, 0, "5", false},
+		{[]int{60, 62, 64, 67, 70}, 0, 0, "9", false},
+	}
+
+	for _, e := range tests {
+		c, ok := identifyChord(e.notes)
+		assert.True(t, ok, "%v", e.notes)
+		assert.Equal(t, e.root, c.root, "%v", e.notes)
+		assert.Equal(t, e.bass, c.bass, "%v", e.notes)
+		assert.Equal(t, e.suffix, c.quality.suffix, "%v", e.notes)
+		assert.Equal(t, e.noFifth, c.noFifth, "%v", e.notes)
+	}
+
+	for _, notes := range [][]int{nil, {60}, {60, 61, 62}} {
+		_, ok := identifyChord(notes)
+		assert.False(t, ok, "%v", notes)
+	}
+}
+
+func TestSpellChord(t *testing.T) {
+	tests := []struct {
+		notes   []int
+		root    string
+		name    string
+		spelled []string
+	}{
+		{[]int{56, 60, 63}, "A♭", "A♭", []string{"A♭", "C", "E♭"}},
+		{[]int{56, 60, 63}, "G♯", "G♯", []string{"G♯", "B♯", "D♯"}},
+		{[]int{64, 67, 72}, "C", "C/E", []string{"C", "E", "G"}},
+		{[]int{62, 65, 68, 71}, "D", "D°7", []string{"D", "F", "A♭", "C♭"}},
+		{[]int{61, 65, 68, 70}, "C♯", "C♯6", []string{"C♯", "E♯", "G♯", "A♯"}},
+		{[]int{62, 67, 69}, "D", "Dsus4", []string{"D", "G", "A"}},
+	}
+
+	for _, e := range tests {
+		c, ok := identifyChord(e.notes)
+		assert.True(t, ok)
+		s := c.spell(e.root, nil)
+		assert.Equal(t, e.name, s.name)
+		assert.Equal(t, e.spelled, s.notes)
+	}
+}
+
+func TestSpellPitchClass(t *testing.T) {
+	scale := enumerateScale("F", majorIntervals)
+	assert.Equal(t, "B♭", spellPitchClass(10, scale))
+	assert.Equal(t, "D♭", spellPitchClass(1, scale))
+	assert.Equal(t, "A♭", spellPitchClass(8, scale))
+	assert.Equal(t, "E♭", spellPitchClass(3, enumerateScale("G", majorIntervals)))
+	assert.Equal(t, "C♯", spellPitchClass(1, enumerateScale("B", majorIntervals)))
+	assert.Equal(t, "G♭", spellPitchClass(6, nil))
+}
+
+func TestSplitChordName(t *testing.T) {
+	tests := []struct {
+		name, root, suffix, bass string
+	}{
+		{"C", "C", "", ""},
+		{"F♯m7♭5", "F♯", "m7♭5", ""},
+		{"Bbm7", "Bb", "m7", ""},
+		{"C#°", "C#", "°", ""},
+		{"E𝄫M7", "E𝄫", "M7", ""},
+		{"C/E", "C", "", "E"},
+		{"Dm7/C", "D", "m7", "C"},
+	}
+
+	for _, e := range tests {
+		root, suffix, bass, ok := splitChordName(e.name)
+		assert.True(t, ok, e.name)
+		assert.Equal(t, e.root, root, e.name)
+		assert.Equal(t, e.suffix, suffix, e.name)
+		assert.Equal(t, e.bass, bass, e.name)
+	}
+
+	for _, name := range []string{"", "N.C.", "H7", "C/X"} {
+		_, _, _, ok := splitChordName(name)
+		assert.False(t, ok, name)
+	}
+}
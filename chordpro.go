@@ -0,0 +1,576 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	chordProLyrics = iota
+	chordProDirective
+	chordProRaw // comments and tablature, kept as they are
+
+	noCapo  = "No Capo"
+	maxCapo = 7
+)
+
+type (
+	// chordProSegment is a chord and the lyrics sung from it up to the next chord. The chord of the first segment of a
+	// line is empty when the line does not start with a chord.
+	chordProSegment struct {
+		chord string
+		text  string
+	}
+
+	chordProLine struct {
+		kind     int
+		name     string // of a directive, as in {name: value}
+		value    string
+		segments []chordProSegment
+		raw      string
+	}
+
+	// chordProSong is a song in ChordPro format: lyrics with chords in brackets where they are played, and
+	// directives in braces.
+	chordProSong struct {
+		lines []chordProLine
+	}
+
+	// capoSuggestion is a capo position and the key of the shapes to play with it so that the song sounds in its key.
+	capoSuggestion struct {
+		capo  int
+		shape string
+	}
+)
+
+var (
+	// chordProSuffixes are the suffixes of the chord qualities as they are often written in ChordPro files.
+	chordProSuffixes = map[string]string{
+		"min":   "m",
+		"maj":   "",
+		"dim":   "°",
+		"dim7":  "°7",
+		"aug":   "+",
+		"sus":   "sus4",
+		"maj7":  "M7",
+		"m7b5":  "m7♭5",
+		"mmaj7": "mM7",
+		"maj9":  "M9",
+	}
+
+	// capoShapes are the keys with open chord shapes that guitarists play easily, most familiar first.
+	capoShapes = map[string][]string{
+		"Major": {"G", "C", "D", "A", "E"},
+		"Minor": {"E", "A", "D"},
+	}
+)
+
+// readChordPro reads a ChordPro song. Lines in tab and grid sections, and comment lines starting with "#", are kept as
+// they are.
+func readChordPro(r io.Reader) (chordProSong, error) {
+	var song chordProSong
+	var inTab bool
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(text)
+
+		switch {
+		case strings.HasPrefix(trimmed, "{") && strings.HasSuffix(trimmed, "}"):
+			name, value := splitDirective(trimmed[1 : len(trimmed)-1])
+			song.lines = append(song.lines, chordProLine{kind: chordProDirective, name: name, value: value})
+			switch name {
+			case "start_of_tab", "sot", "start_of_grid", "sog":
+				inTab = true
+			case "end_of_tab", "eot", "end_of_grid", "eog":
+				inTab = false
+			}
+		case inTab || strings.HasPrefix(trimmed, "#"):
+			song.lines = append(song.lines, chordProLine{kind: chordProRaw, raw: text})
+		default:
+			segments, err := splitLyrics(text)
+			if err != nil {
+				return chordProSong{}, fmt.Errorf("line %d: %w", n, err)
+			}
+			song.lines = append(song.lines, chordProLine{kind: chordProLyrics, segments: segments})
+		}
+	}
+
+	return song, scanner.Err()
+}
+
+// splitDirective splits the inside of a directive into its name, in lower case, and its value, which follows the first
+// colon or, without one, the first space. Either may be surrounded by spaces.
+func splitDirective(s string) (name, value string) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexByte(s, ':')
+	if i < 0 {
+		i = strings.IndexFunc(s, unicode.IsSpace)
+	}
+	if i < 0 {
+		return strings.ToLower(s), ""
+	}
+	return strings.ToLower(strings.TrimSpace(s[:i])), strings.TrimSpace(s[i+1:])
+}
+
+// splitLyrics splits a line of lyrics at its chords.
+func splitLyrics(line string) ([]chordProSegment, error) {
+	var segments []chordProSegment
+	var seg chordProSegment
+	for {
+		open := strings.IndexByte(line, '[')
+		if open < 0 {
+			seg.text += line
+			break
+		}
+		end := strings.IndexByte(line[open:], ']')
+		if end < 0 {
+			return nil, fmt.Errorf("chord %q has no closing bracket", line[open:])
+		}
+		seg.text += line[:open]
+		if seg.chord != "" || seg.text != "" {
+			segments = append(segments, seg)
+		}
+		seg = chordProSegment{chord: line[open+1 : open+end]}
+		line = line[open+end+1:]
+	}
+
+	return append(segments, seg), nil
+}
+
+// writeChordPro writes the song in ChordPro format.
+func writeChordPro(w io.Writer, song chordProSong) error {
+	var b strings.Builder
+	for _, l := range song.lines {
+		switch l.kind {
+		case chordProDirective:
+			if l.value == "" {
+				fmt.Fprintf(&b, "{%s}\n", l.name)
+			} else {
+				fmt.Fprintf(&b, "{%s: %s}\n", l.name, l.value)
+			}
+		case chordProRaw:
+			b.WriteString(l.raw + "\n")
+		default:
+			for _, seg := range l.segments {
+				if seg.chord != "" {
+					b.WriteString("[" + seg.chord + "]")
+				}
+				b.WriteString(seg.text)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// directive returns the value of the first directive with one of the names.
+func (s chordProSong) directive(names ...string) (string, bool) {
+	for _, l := range s.lines {
+		for _, n := range names {
+			if l.kind == chordProDirective && l.name == n {
+				return l.value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// setDirective sets the value of the first directive with the name, adding the directive after the directives at the
+// top of the song if there is none.
+func (s *chordProSong) setDirective(name, value string) {
+	at := 0
+	for i, l := range s.lines {
+		if l.kind == chordProDirective && l.name == name {
+			s.lines[i].value = value
+			return
+		}
+		if at == i && l.kind == chordProDirective && !strings.HasPrefix(l.name, "start_of_") {
+			at = i + 1
+		}
+	}
+
+	lines := append([]chordProLine(nil), s.lines[:at]...)
+	lines = append(lines, chordProLine{kind: chordProDirective, name: name, value: value})
+	s.lines = append(lines, s.lines[at:]...)
+}
+
+// chords returns the chords of the song in order.
+func (s chordProSong) chords() []string {
+	var chords []string
+	for _, l := range s.lines {
+		for _, seg := range l.segments {
+			if seg.chord != "" {
+				chords = append(chords, seg.chord)
+			}
+		}
+	}
+	return chords
+}
+
+// ascii reports whether the chords of the song are written with ASCII accidentals, as in "F#m" and "Bb", so that they
+// are written the same way when transposed.
+func (s chordProSong) ascii() bool {
+	for _, c := range s.chords() {
+		if strings.ContainsAny(c, sharp+flat+doubleSharp+doubleFlat) {
+			return false
+		}
+	}
+	return true
+}
+
+// chordProQuality returns the chord quality of a ChordPro chord suffix. Suffixes that are not known are read as minor
+// when they start with "m" and as major otherwise, which is close enough to find the key.
+func chordProQuality(suffix string) chordQuality {
+	if s, ok := chordProSuffixes[suffix]; ok {
+		suffix = s
+	}
+	if q, ok := qualityOf(suffix); ok {
+		return q
+	}
+	if strings.HasPrefix(suffix, "m") && !strings.HasPrefix(suffix, "maj") {
+		q, _ := qualityOf("m")
+		return q
+	}
+	q, _ := qualityOf("")
+	return q
+}
+
+// parseKey returns the key and scale of a key such as "Bb" or "F#m", spelled as in keyNames.
+func parseKey(s string) (key, scale string, ok bool) {
+	root, suffix, bass, ok := splitChordName(s)
+	if !ok || bass != "" {
+		return "", "", false
+	}
+	switch suffix {
+	case "":
+		scale = "Major"
+	case "m", "min":
+		scale = "Minor"
+	default:
+		return "", "", false
+	}
+	key, ok = keyName(root)
+	return key, scale, ok
+}
+
+// key returns the key of the song: the key given by its key directive, or else the key that best fits its chords,
+// each counted with all of its tones.
+func (s chordProSong) key() (key, scale string, ok bool) {
+	if value, found := s.directive("key"); found {
+		if key, scale, ok := parseKey(value); ok {
+			return key, scale, true
+		}
+	}
+
+	var weights [chromaticScaleLen]float64
+	for _, c := range s.chords() {
+		root, suffix, bass, ok := splitChordName(c)
+		if !ok {
+			continue
+		}
+		rootPC := notePitchClass(root)
+		for _, i := range chordProQuality(suffix).intervals {
+			weights[pitchClass(rootPC+i)]++
+		}
+		if bass != "" {
+			weights[notePitchClass(bass)]++
+		}
+	}
+	if key, scale, ok = detectKey(weights); !ok {
+		return "", "", false
+	}
+	key, ok = keyName(key)
+	return key, scale, ok
+}
+
+// transposeNote moves the note by the given number of letters and semitones. Notes of the new scale are spelled as
+// they are in it, and other notes keep their place relative to the scale, unless that would need a double accidental.
+func transposeNote(note string, letters, semitones int, scaleNotes []string) string {
+	step, alter, ok := parseNote(note)
+	if !ok {
+		return note
+	}
+	pc := pitchClass(stepSemitones[step] + alter + semitones)
+	for _, n := range scaleNotes {
+		if notePitchClass(n) == pc {
+			return n
+		}
+	}
+
+	s := steps[(strings.IndexByte(steps, step)+letters)%len(steps)]
+	if a := pitchClass(pc-stepSemitones[s]+6) - 6; a >= -1 && a <= 1 {
+		return pitch{step: s, alter: a}.name()
+	}
+	return spellPitchClass(pc, scaleNotes)
+}
+
+// transposer returns a function that transposes notes from one key to another of the scale, spelling them for the new
+// key.
+func transposer(from, to, scale string) func(note string) string {
+	fromStep, _, _ := parseNote(from)
+	toStep, _, _ := parseNote(to)
+	letters := (strings.IndexByte(steps, toStep) - strings.IndexByte(steps, fromStep) + len(steps)) % len(steps)
+	semitones := pitchClass(notePitchClass(to) - notePitchClass(from))
+	scaleNotes := enumerateScale(to, scaleIntervals[scale])
+
+	return func(note string) string {
+		return transposeNote(note, letters, semitones, scaleNotes)
+	}
+}
+
+// transposeChord returns the chord with its root and bass spelled by spell, as transposed notes. A chord that cannot be
+// read, such as "N.C.", is returned as it is.
+func transposeChord(name string, spell func(note string) string) string {
+	root, suffix, bass, ok := splitChordName(name)
+	if !ok {
+		return name
+	}
+	name = spell(root) + suffix
+	if bass != "" {
+		name += "/" + spell(bass)
+	}
+	return name
+}
+
+// transpose returns the song moved from one key to another of the scale, with its chords and key directive spelled
+// for the new key. Chords that cannot be read, such as "N.C.", are kept as they are.
+func (s chordProSong) transpose(from, to, scale string) chordProSong {
+	transposed := transposer(from, to, scale)
+	ascii := s.ascii()
+	spell := func(note string) string {
+		n := transposed(note)
+		if ascii {
+			n = asciiAccidentals.Replace(n)
+		}
+		return n
+	}
+
+	t := chordProSong{lines: make([]chordProLine, len(s.lines))}
+	for i, l := range s.lines {
+		t.lines[i] = l
+		if l.kind != chordProLyrics {
+			continue
+		}
+		t.lines[i].segments = make([]chordProSegment, len(l.segments))
+		for j, seg := range l.segments {
+			seg.chord = transposeChord(seg.chord, spell)
+			t.lines[i].segments[j] = seg
+		}
+	}
+
+	key := to
+	if ascii {
+		key = asciiAccidentals.Replace(key)
+	}
+	if scale == "Minor" {
+		key += "m"
+	}
+	t.setDirective("key", key)
+
+	return t
+}
+
+// capoSuggestions returns the capo positions, lowest first, at which the song can be played in the key with the open
+// shapes of one of capoShapes.
+func capoSuggestions(key, scale string) []capoSuggestion {
+	var suggestions []capoSuggestion
+	for capo := 1; capo <= maxCapo; capo++ {
+		for _, shape := range capoShapes[scale] {
+			if notePitchClass(shape) == pitchClass(notePitchClass(key)-capo) {
+				suggestions = append(suggestions, capoSuggestion{capo: capo, shape: shape})
+			}
+		}
+	}
+	return suggestions
+}
+
+func (c capoSuggestion) String() string {
+	return fmt.Sprintf("Capo %d, %s Shapes", c.capo, c.shape)
+}
+
+// text returns the song as it is shown: each line of lyrics below a line with its chords, over the syllables they are
+// played on.
+func (s chordProSong) text() string {
+	var b strings.Builder
+	for _, l := range s.lines {
+		switch l.kind {
+		case chordProDirective:
+			switch {
+			case l.name == "subtitle" || l.name == "st":
+				b.WriteString(l.value + "\n")
+			case l.name == "comment" || l.name == "c" || l.name == "comment_italic" || l.name == "ci":
+				b.WriteString("(" + l.value + ")\n")
+			case l.name == "soc" || l.name == "sov" || l.name == "sob" || strings.HasPrefix(l.name, "start_of_"):
+				label := l.value
+				if label == "" {
+					label = sectionLabel(l.name)
+				}
+				if label != "" {
+					b.WriteString(label + ":\n")
+				}
+			case l.name == "eoc" || l.name == "eov" || l.name == "eob" || strings.HasPrefix(l.name, "end_of_"):
+				b.WriteString("\n")
+			}
+		case chordProRaw:
+			if !strings.HasPrefix(strings.TrimSpace(l.raw), "#") {
+				b.WriteString(l.raw + "\n")
+			}
+		default:
+			var chords, lyrics []rune
+			for _, seg := range l.segments {
+				if seg.chord != "" {
+					for len(lyrics) < len(chords) {
+						lyrics = append(lyrics, ' ')
+					}
+					for len(chords) < len(lyrics) {
+						chords = append(chords, ' ')
+					}
+					chords = append(chords, []rune(seg.chord+" ")...)
+				}
+				lyrics = append(lyrics, []rune(seg.text)...)
+			}
+			if c := strings.TrimRight(string(chords), " "); c != "" {
+				b.WriteString(c + "\n")
+			}
+			if t := strings.TrimRight(string(lyrics), " "); t != "" || len(chords) == 0 {
+				b.WriteString(t + "\n")
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// sectionLabel returns the label of a section started by the directive, as "Chorus" for start_of_chorus and soc.
+func sectionLabel(name string) string {
+	switch name {
+	case "soc", "start_of_chorus":
+		return "Chorus"
+	case "sov", "start_of_verse":
+		return "Verse"
+	case "sob", "start_of_bridge":
+		return "Bridge"
+	}
+	return ""
+}
+
+// buildChordPro returns the song view: an imported ChordPro song shown with its lyrics and chords, transposed to any
+// key.
+func (m *model) buildChordPro() fyne.CanvasObject {
+	m.chordProLabel = widget.NewLabel("Import a ChordPro file to see its lyrics and chords")
+	m.chordProText = widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+
+	m.capoSelector = widget.NewSelect([]string{noCapo}, func(string) {
+		m.refreshChordPro()
+	})
+	m.capoSelector.PlaceHolder = capoSuggestion{maxCapo, "G"}.String() // never shown, but sizes the selector
+	m.transposeSelector = widget.NewSelect(keyNames, func(string) {
+		m.refreshCapo()
+	})
+
+	export := widget.NewButton("Export ChordPro…", m.showChordProExport)
+
+	return container.NewBorder(
+		container.NewVBox(
+			container.NewHBox(
+				widget.NewButton("Import ChordPro…", m.showChordProImport),
+				widget.NewLabel("Transpose To"),
+				m.transposeSelector,
+				m.capoSelector,
+				layout.NewSpacer(),
+				export,
+			),
+			m.chordProLabel,
+		),
+		nil, nil, nil,
+		container.NewScroll(m.chordProText),
+	)
+}
+
+// refreshCapo offers the capo positions for the key the song is transposed to.
+func (m *model) refreshCapo() {
+	m.capoSuggestions = nil
+	if m.chordPro != nil {
+		m.capoSuggestions = capoSuggestions(m.transposeSelector.Selected, m.chordProScale)
+	}
+	options := []string{noCapo}
+	for _, s := range m.capoSuggestions {
+		options = append(options, s.String())
+	}
+	m.capoSelector.Options = options
+	m.capoSelector.SetSelectedIndex(0)
+}
+
+// chordProView returns the song as it is shown: transposed to the selected key and, with a capo, to the key of the
+// shapes played.
+func (m *model) chordProView() chordProSong {
+	to := m.transposeSelector.Selected
+	song := m.chordPro.transpose(m.chordProKey, to, m.chordProScale)
+	if i := m.capoSelector.SelectedIndex() - 1; i >= 0 && i < len(m.capoSuggestions) {
+		c := m.capoSuggestions[i]
+		song = song.transpose(to, c.shape, m.chordProScale)
+		song.setDirective("capo", fmt.Sprint(c.capo))
+	}
+	return song
+}
+
+func (m *model) refreshChordPro() {
+	if m.chordPro == nil {
+		return
+	}
+
+	song := m.chordProView()
+	title, ok := m.chordPro.directive("title", "t")
+	if !ok {
+		title = m.chordProName
+	}
+	text := fmt.Sprintf("%s: %s %s", title, m.chordProKey, m.chordProScale)
+	if to := m.transposeSelector.Selected; to != m.chordProKey {
+		text += fmt.Sprintf(", transposed to %s %s", to, m.chordProScale)
+	}
+	m.chordProLabel.SetText(text)
+	m.chordProText.SetText(song.text())
+}
+
+// showChordProImport asks for a ChordPro file, finds its key and shows it in that key.
+func (m *model) showChordProImport() {
+	m.openFile([]string{".cho", ".chopro", ".chordpro", ".crd"}, func(r io.Reader, name string) error {
+		song, err := readChordPro(r)
+		if err != nil {
+			return err
+		}
+		key, scale, ok := song.key()
+		if !ok {
+			return fmt.Errorf("%s has no chords to find its key from", filepath.Base(name))
+		}
+		m.chordPro = &song
+		m.chordProName = filepath.Base(name)
+		m.chordProKey, m.chordProScale = key, scale
+		m.transposeSelector.SetSelected(key)
+		m.tabs.Select(m.chordProTab)
+		return nil
+	})
+}
+
+// showChordProExport asks where to save the song as it is shown.
+func (m *model) showChordProExport() {
+	if m.chordPro == nil {
+		return
+	}
+	name := strings.TrimSuffix(m.chordProName, filepath.Ext(m.chordProName))
+	m.saveFile(fmt.Sprintf("%s (%s).cho", name, m.transposeSelector.Selected), ".cho", func(w io.Writer) error {
+		return writeChordPro(w, m.chordProView())
+	})
+}
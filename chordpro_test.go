@@ -0,0 +1,122 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const amazingGrace = `{title: Amazing Grace}
+{artist: John Newton}
+# traditional
+{start_of_verse}
+A[G]mazing [G7]grace, how [C]sweet the [G]sound
+That [G]saved a [Em]wretch like [D]me[D7]
+{end_of_verse}
+{start_of_tab}
+e|--[3]--|
+{end_of_tab}
+[C/E]  [N.C.]
+`
+
+func TestReadChordPro(t *testing.T) {
+	song, err := readChordPro(strings.NewReader(amazingGrace))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"G", "G7", "C", "G", "G", "Em", "D", "D7", "C/E", "N.C."}, song.chords())
+	assert.Equal(t, []chordProSegment{{"", "A"}, {"G", "mazing "}, {"G7", "grace, how "}, {"C", "sweet the "},
+		{"G", "sound"}}, song.lines[4].segments)
+	assert.Equal(t, chordProRaw, song.lines[8].kind)
+
+	title, ok := song.directive("title", "t")
+	assert.True(t, ok)
+	assert.Equal(t, "Amazing Grace", title)
+
+	var b strings.Builder
+	assert.NoError(t, writeChordPro(&b, song))
+	assert.Equal(t, amazingGrace, b.String())
+
+	_, err = readChordPro(strings.NewReader("{t: Broken}\n[G]one [C\n"))
+	assert.EqualError(t, err, `line 2: chord "[C" has no closing bracket`)
+
+	song, err = readChordPro(strings.NewReader("{title : Amazing Grace}\n{subtitle Traditional}\n"))
+	assert.NoError(t, err)
+	title, _ = song.directive("title", "t")
+	assert.Equal(t, "Amazing Grace", title)
+	subtitle, _ := song.directive("subtitle", "st")
+	assert.Equal(t, "Traditional", subtitle)
+}
+
+func TestChordProKey(t *testing.T) {
+	tests := []struct {
+		song  string
+		key   string
+		scale string
+	}{
+		{amazingGrace, "G", "Major"},
+		{"[Am]one [Dm]two [E7]three [Am]four", "A", "Minor"},
+		{"[Bb]one [Eb]two [F7]three [Bb]four", "B♭", "Major"},
+		{"{key: F#m}\n[A]one [D]two", "F♯", "Minor"},
+		{"{key : G}\n[G]one [C]two", "G", "Major"},
+		{"{key: H}\n[Dbmaj7]one [Gb]two [Ab7]three [Db]four", "D♭", "Major"},
+	}
+
+	for _, e := range tests {
+		song, err := readChordPro(strings.NewReader(e.song))
+		assert.NoError(t, err)
+		key, scale, ok := song.key()
+		assert.True(t, ok)
+		assert.Equal(t, e.key, key)
+		assert.Equal(t, e.scale, scale)
+	}
+
+	_, _, ok := chordProSong{}.key()
+	assert.False(t, ok)
+}
+
+func TestTransposeChordPro(t *testing.T) {
+	tests := []struct {
+		song, from, to, scale string
+		chords                []string
+		key                   string
+	}{
+		{amazingGrace, "G", "E♭", "Major",
+			[]string{"Eb", "Eb7", "Ab", "Eb", "Eb", "Cm", "Bb", "Bb7", "Ab/C", "N.C."}, "Eb"},
+		{amazingGrace, "G", "F♯", "Major",
+			[]string{"F#", "F#7", "B", "F#", "F#", "D#m", "C#", "C#7", "B/D#", "N.C."}, "F#"},
+		{"[Am]one [F]two [G]three [E7]four [Bb]five", "A", "C♯", "Minor",
+			[]string{"C#m", "A", "B", "G#7", "D"}, "C#m"},
+		{"[C]one [Eb]two [Bb]three [F♯°7]four", "C", "E", "Major",
+			[]string{"E", "G", "D", "A♯°7"}, "E"},
+	}
+
+	for _, e := range tests {
+		song, err := readChordPro(strings.NewReader(e.song))
+		assert.NoError(t, err)
+		transposed := song.transpose(e.from, e.to, e.scale)
+		assert.Equal(t, e.chords, transposed.chords())
+		key, _ := transposed.directive("key")
+		assert.Equal(t, e.key, key)
+	}
+}
+
+func TestCapoSuggestions(t *testing.T) {
+	assert.Equal(t, []capoSuggestion{{1, "D"}, {3, "C"}, {6, "A"}}, capoSuggestions("E♭", "Major"))
+	assert.Equal(t, []capoSuggestion{{1, "E"}, {3, "D"}}, capoSuggestions("F", "Minor"))
+	assert.Equal(t, "Capo 3, C Shapes", capoSuggestion{3, "C"}.String())
+}
+
+func TestChordProText(t *testing.T) {
+	song, err := readChordPro(strings.NewReader(amazingGrace))
+	assert.NoError(t, err)
+	assert.Equal(t, `Verse:
+ G      G7         C         G
+Amazing grace, how sweet the sound
+     G       Em          D D7
+That saved a wretch like me
+
+e|--[3]--|
+
+C/E N.C.
+`, song.text())
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	circleSegments = 12
+
+	// Radii of the rings as a fraction of the radius of the circle.
+	minorRingInner = 0.32
+	majorRingInner = 0.62
+)
+
+type (
+	// circleOfFifths shows the major keys on an outer ring and their relative minors on an inner ring. The current key
+	// and its relative are highlighted and their neighbors, the closely related keys, are marked. Tapping a segment
+	// selects its key.
+	circleOfFifths struct {
+		widget.BaseWidget
+
+		position int  // segment of the current key, counted clockwise from C major
+		minor    bool // whether the current key is on the inner ring
+		keySig   keySignature
+
+		selected func(key, scale string)
+	}
+
+	circleRenderer struct {
+		c       *circleOfFifths
+		raster  *canvas.Raster
+		majors  []*canvas.Text
+		minors  []*canvas.Text
+		center  *canvas.Text
+		objects []fyne.CanvasObject
+	}
+)
+
+var (
+	// Keys of each segment, clockwise from the top. The labels also show the enharmonic keys at the bottom of the
+	// circle.
+	circleMajorKeys   = []string{"C", "G", "D", "A", "E", "B", "F♯", "D♭", "A♭", "E♭", "B♭", "F"}
+	circleMinorKeys   = []string{"A", "E", "B", "F♯", "C♯", "G♯", "E♭", "B♭", "F", "C", "G", "D"}
+	circleMajorLabels = []string{"C", "G", "D", "A", "E", "B/C♭", "F♯/G♭", "D♭/C♯", "A♭", "E♭", "B♭", "F"}
+	circleMinorLabels = []string{"Am", "Em", "Bm", "F♯m", "C♯m", "G♯m", "E♭m/D♯m", "B♭m", "Fm", "Cm", "Gm", "Dm"}
+
+	neighborHighlightColor = color.NRGBA{R: 0x90, G: 0xca, B: 0xf9, A: 0x60}
+)
+
+var (
+	_ fyne.Widget   = (*circleOfFifths)(nil)
+	_ fyne.Tappable = (*circleOfFifths)(nil)
+)
+
+func newCircleOfFifths(selected func(key, scale string)) *circleOfFifths {
+	c := &circleOfFifths{selected: selected}
+	c.ExtendBaseWidget(c)
+	return c
+}
+
+// circlePosition returns the segment of the key and whether it is on the minor ring. Enharmonic keys such as D♯
+// major share the segment of their usual spelling.
+func circlePosition(key, scale string) (int, bool) {
+	k := int(keySignatureFor(key, scale))
+	return (k%circleSegments + circleSegments) % circleSegments, scale == "Minor"
+}
+
+// keyName returns the entry of keyNames spelling the same note as the given name, which may use ASCII accidentals.
+func keyName(note string) (string, bool) {
+	step, alter, ok := parseNote(note)
+	if !ok {
+		return "", false
+	}
+	for _, k := range keyNames {
+		if s, a, _ := parseNote(k); s == step && a == alter {
+			return k, true
+		}
+	}
+
+	return "", false
+}
+
+func (c *circleOfFifths) setKey(key, scale string) {
+	c.position, c.minor = circlePosition(key, scale)
+	c.keySig = keySignatureFor(key, scale)
+	c.Refresh()
+}
+
+// segmentAt returns the segment and ring under the point of a circle of the given size, or false if the point is in
+// the center or outside the circle.
+func segmentAt(pos fyne.Position, size fyne.Size) (segment int, minor bool, ok bool) {
+	radius := float64(fyne.Min(size.Width, size.Height)) / 2
+	dx := float64(pos.X) - float64(size.Width)/2
+	dy := float64(pos.Y) - float64(size.Height)/2
+	r := math.Hypot(dx, dy) / radius
+	if r < minorRingInner || r > 1 {
+		return 0, false, false
+	}
+
+	angle := math.Atan2(dx, -dy) + math.Pi/circleSegments
+	if angle < 0 {
+		angle += 2 * math.Pi
+	}
+	segment = int(angle/(2*math.Pi/circleSegments)) % circleSegments
+
+	return segment, r < majorRingInner, true
+}
+
+func (c *circleOfFifths) Tapped(ev *fyne.PointEvent) {
+	segment, minor, ok := segmentAt(ev.Position, c.Size())
+	if !ok || c.selected == nil {
+		return
+	}
+
+	if minor {
+		c.selected(circleMinorKeys[segment], "Minor")
+	} else {
+		c.selected(circleMajorKeys[segment], "Major")
+	}
+}
+
+// segmentColor returns the fill of a segment: the current key, its relative, the closely related keys on either side
+// of them, or neither.
+func (c *circleOfFifths) segmentColor(segment int, minor bool) color.Color {
+	distance := (segment - c.position + circleSegments) % circleSegments
+	switch {
+	case distance == 0 && minor == c.minor:
+		return chordHighlightColor
+	case distance == 0:
+		return scaleHighlightColor
+	case distance == 1 || distance == circleSegments-1:
+		return neighborHighlightColor
+	}
+
+	return theme.InputBackgroundColor()
+}
+
+func (c *circleOfFifths) CreateRenderer() fyne.WidgetRenderer {
+	r := &circleRenderer{c: c}
+	r.raster = canvas.NewRasterWithPixels(r.pixel)
+	r.objects = append(r.objects, r.raster)
+	for i := 0; i < circleSegments; i++ {
+		major := canvas.NewText("", theme.ForegroundColor())
+		major.TextStyle.Bold = true
+		minor := canvas.NewText("", theme.ForegroundColor())
+		minor.TextSize = theme.TextSize() * 0.85
+		r.majors = append(r.majors, major)
+		r.minors = append(r.minors, minor)
+	}
+	r.center = canvas.NewText("", theme.ForegroundColor())
+	r.center.TextSize = theme.TextSize() * 1.5
+	r.Refresh()
+
+	return r
+}
+
+// pixel draws the rings, with a line between segments.
+func (r *circleRenderer) pixel(x, y, w, h int) color.Color {
+	size := fyne.NewSize(float32(w), float32(h))
+	pos := fyne.NewPos(float32(x)+0.5, float32(y)+0.5)
+	segment, minor, ok := segmentAt(pos, size)
+	if !ok {
+		// Not color.Transparent, which would make the raster an alpha mask.
+		return color.NRGBA{}
+	}
+
+	radius := float64(fyne.Min(size.Width, size.Height)) / 2
+	dx := float64(pos.X) - float64(size.Width)/2
+	dy := float64(pos.Y) - float64(size.Height)/2
+	dist := math.Hypot(dx, dy)
+	angle := math.Atan2(dx, -dy) + math.Pi/circleSegments
+	edge := math.Mod(angle+2*math.Pi, 2*math.Pi/circleSegments) * dist
+	if edge < 1 || math.Abs(dist-radius*majorRingInner) < 1 || radius-dist < 1 {
+		return theme.BackgroundColor()
+	}
+
+	return r.c.segmentColor(segment, minor)
+}
+
+// labelPosition returns the center of a segment label at the given fraction of the radius.
+func labelPosition(size fyne.Size, segment int, radius float64) fyne.Position {
+	angle := 2 * math.Pi * float64(segment) / circleSegments
+	r := radius * float64(fyne.Min(size.Width, size.Height)) / 2
+	return fyne.NewPos(size.Width/2+float32(r*math.Sin(angle)), size.Height/2-float32(r*math.Cos(angle)))
+}
+
+func (r *circleRenderer) Layout(size fyne.Size) {
+	r.raster.Resize(size)
+
+	place := func(t *canvas.Text, center fyne.Position, text string) {
+		t.Text = text
+		ts := t.MinSize()
+		t.Move(fyne.NewPos(center.X-ts.Width/2, center.Y-ts.Height/2))
+		t.Resize(ts)
+	}
+	for i := 0; i < circleSegments; i++ {
+		place(r.majors[i], labelPosition(size, i, (1+majorRingInner)/2), circleMajorLabels[i])
+		place(r.minors[i], labelPosition(size, i, (majorRingInner+minorRingInner)/2), circleMinorLabels[i])
+	}
+	place(r.center, fyne.NewPos(size.Width/2, size.Height/2), r.center.Text)
+}
+
+func (r *circleRenderer) MinSize() fyne.Size {
+	side := theme.TextSize() * 24
+	return fyne.NewSize(side, side)
+}
+
+func (r *circleRenderer) Refresh() {
+	for i := 0; i < circleSegments; i++ {
+		for _, t := range []*canvas.Text{r.majors[i], r.minors[i]} {
+			t.Color = theme.ForegroundColor()
+		}
+		if i == r.c.position {
+			r.majors[i].Color = color.Black
+			r.minors[i].Color = color.Black
+		}
+	}
+
+	// The center shows the number of sharps or flats, of the enharmonic key if the current key needs more than seven.
+	k := r.c.keySig
+	if !k.valid() {
+		k = keySignature(r.c.position)
+		if k > 6 {
+			k -= circleSegments
+		}
+	}
+	switch {
+	case k > 0:
+		r.center.Text = fmt.Sprintf("%d%s", k.sharps(), sharp)
+	case k < 0:
+		r.center.Text = fmt.Sprintf("%d%s", k.flats(), flat)
+	default:
+		r.center.Text = "0"
+	}
+	r.center.Color = theme.ForegroundColor()
+
+	r.objects = []fyne.CanvasObject{r.raster, r.center}
+	for i := 0; i < circleSegments; i++ {
+		r.objects = append(r.objects, r.majors[i], r.minors[i])
+	}
+	r.Layout(r.c.Size())
+	canvas.Refresh(r.c)
+}
+
+func (r *circleRenderer) Objects() []fyne.CanvasObject {
+	return r.objects
+}
+
+func (r *circleRenderer) Destroy() {}
+
+// buildCircle returns the circle of fifths with a description and staff of the current key signature.
+func (m *model) buildCircle() fyne.CanvasObject {
+	m.circle = newCircleOfFifths(func(key, scale string) {
+		name, ok := keyName(key)
+		if !ok {
+			return
+		}
+		m.scaleSelector.SetSelected(scale)
+		m.keySelector.SetSelected(name)
+	})
+	m.keySigLabel = widget.NewLabel("")
+	m.relatedLabel = widget.NewLabel("")
+	m.keySigStaff = newStaff()
+	m.keySigStaff.setClef(clefTreble)
+
+	return container.NewBorder(nil, nil, nil,
+		container.NewVBox(
+			widget.NewCard("", "Key Signature", container.NewVBox(m.keySigLabel, m.keySigStaff)),
+			widget.NewCard("", "Closely Related Keys", m.relatedLabel),
+		),
+		m.circle,
+	)
+}
+
+// refreshCircle highlights the current key on the circle and describes its signature and closely related keys.
+func (m *model) refreshCircle() {
+	m.circle.setKey(m.key, m.scale)
+
+	keySig := keySignatureFor(m.key, m.scale)
+	m.keySigLabel.SetText(keySig.describe())
+	m.keySigStaff.setKeySignature(keySig)
+
+	// The closely related keys are the relative key and the keys one fifth either side of the key and its relative.
+	position, minor := circlePosition(m.key, m.scale)
+	var related []string
+	for _, d := range []int{0, circleSegments - 1, 1} {
+		p := (position + d) % circleSegments
+		if d != 0 || minor {
+			related = append(related, circleMajorLabels[p])
+		}
+		if d != 0 || !minor {
+			related = append(related, circleMinorLabels[p])
+		}
+	}
+	m.relatedLabel.SetText(strings.Join(related, "  "))
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCirclePosition(t *testing.T) {
+	tests := []struct {
+		key      string
+		scale    string
+		position int
+		minor    bool
+	}{
+		{"C", "Major", 0, false},
+		{"A", "Minor", 0, true},
+		{"E", "Major", 4, false},
+		{"F", "Major", 11, false},
+		{"G♭", "Major", 6, false},
+		{"F♯", "Major", 6, false},
+		{"D♯", "Major", 9, false},
+		{"E♭", "Minor", 6, true},
+		{"G#", "Minor", 5, true},
+	}
+
+	for _, e := range tests {
+		position, minor := circlePosition(e.key, e.scale)
+		assert.Equal(t, e.position, position, "%s %s", e.key, e.scale)
+		assert.Equal(t, e.minor, minor, "%s %s", e.key, e.scale)
+	}
+}
+
+func TestSegmentAt(t *testing.T) {
+	size := fyne.NewSize(200, 200)
+	tests := []struct {
+		pos     fyne.Position
+		segment int
+		minor   bool
+		ok      bool
+	}{
+		{fyne.NewPos(100, 5), 0, false, true},
+		{fyne.NewPos(100, 50), 0, true, true},
+		{fyne.NewPos(195, 100), 3, false, true},
+		{fyne.NewPos(100, 195), 6, false, true},
+		{fyne.NewPos(5, 100), 9, false, true},
+		{fyne.NewPos(100, 100), 0, false, false},
+		{fyne.NewPos(0, 0), 0, false, false},
+	}
+
+	for _, e := range tests {
+		segment, minor, ok := segmentAt(e.pos, size)
+		assert.Equal(t, e.ok, ok, "%v", e.pos)
+		if ok {
+			assert.Equal(t, e.segment, segment, "%v", e.pos)
+			assert.Equal(t, e.minor, minor, "%v", e.pos)
+		}
+	}
+}
+
+func TestKeyName(t *testing.T) {
+	tests := []struct {
+		note string
+		key  string
+		ok   bool
+	}{
+		{"C", "C", true},
+		{"Eb", "E♭", true},
+		{"F#", "F♯", true},
+		{"G♯", "G♯", true},
+		{"G#", "G♯", true},
+		{"C♭", "", false},
+		{"X", "", false},
+	}
+
+	for _, e := range tests {
+		key, ok := keyName(e.note)
+		assert.Equal(t, e.ok, ok, e.note)
+		assert.Equal(t, e.key, key, e.note)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+const printCommand = "print"
+
+// asciiSymbols replaces every music symbol the app shows with ASCII, for terminals without a font that has them.
+var asciiSymbols = strings.NewReplacer(sharp, "#", flat, "b", doubleSharp, "##", doubleFlat, "bb", "°", "dim", "⁷", "7")
+
+// sectionFlag returns the name of the chord section on the command line, as in "secondary-dominants".
+func sectionFlag(title string) string {
+	return strings.ReplaceAll(strings.ToLower(title), " ", "-")
+}
+
+// utf8Locale reports whether the locale of the terminal is UTF-8, which is taken to mean it can show music symbols.
+func utf8Locale() bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			v = strings.ToLower(v)
+			return strings.Contains(v, "utf-8") || strings.Contains(v, "utf8")
+		}
+	}
+	return false
+}
+
+// runPrint runs the print command with its arguments, writing the scale and chord sections of a key and scale to w as
+// text tables, JSON or YAML, or the JSON Schema of the last two.
+func runPrint(args []string, w io.Writer) error {
+	titles := sectionFlags()
+
+	fs := flag.NewFlagSet(printCommand, flag.ContinueOnError)
+	key := fs.String("key", keyNames[0], "key, which may use ASCII accidentals, as in Eb")
+	scale := fs.String("scale", scaleNames[0], "scale: "+strings.Join(scaleNames, " or "))
+	sections := fs.String("sections", strings.Join(titles, ","),
+		"comma-separated chord sections: "+strings.Join(titles, ", "))
+	format := fs.String("format", formatText, "output format: "+strings.Join(formats, ", "))
+	ascii := fs.Bool("ascii", !utf8Locale(), "spell accidentals and symbols of the text format in ASCII")
+	schema := fs.Bool("schema", false, "write the JSON Schema of the json and yaml formats instead")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	if *schema {
+		_, err := w.Write(keySchema)
+		return err
+	}
+	if !contains(formats, *format) {
+		return fmt.Errorf("unknown format %q, expected one of %s", *format, strings.Join(formats, ", "))
+	}
+
+	m, err := keyModel(*key, *scale)
+	if err != nil {
+		return err
+	}
+	wanted, err := parseSections(*sections)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case formatJSON:
+		return writeJSON(w, m.keyData(wanted))
+	case formatYAML:
+		return writeYAML(w, m.keyData(wanted))
+	}
+	return m.writeTables(w, wanted, *ascii)
+}
+
+// writeTables writes the key, the scale and the chord sections named by their flags as tables with aligned columns,
+// spelling symbols in ASCII if asked to.
+func (m *model) writeTables(w io.Writer, sections []string, ascii bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	row := func(cells ...string) {
+		line := strings.Join(cells, "\t")
+		if ascii {
+			line = asciiSymbols.Replace(line)
+		}
+		fmt.Fprintln(tw, line)
+	}
+
+	row(m.key + " " + m.scale)
+	row("Scale", strings.Join(m.scaleNotes, " "))
+	for _, sec := range m.chordSections() {
+		if !contains(sections, sectionFlag(sec.title)) {
+			continue
+		}
+		row()
+		row(sec.title)
+		row("Position", "Chord", "Notes")
+		for _, c := range sec.chords {
+			row(c.position, c.name, strings.Join(c.notes, " "))
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunPrint(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{
+			[]string{"--key", "E♭", "--scale", "Minor", "--sections", "triads", "--ascii=false"},
+			`E♭ Minor
+Scale  E♭ F G♭ A♭ B♭ C♭ D♭
+
+Triads
+Position  Chord  Notes
+I         E♭m    E♭ G♭ B♭
+II        F°     F A♭ C♭
+III       G♭     G♭ B♭ D♭
+IV        A♭m    A♭ C♭ E♭
+V         B♭m    B♭ D♭ F
+VI        C♭     C♭ E♭ G♭
+VII       D♭     D♭ F A♭
+`,
+		},
+		{
+			[]string{"--key", "Eb", "--scale", "Minor", "--sections", "sevenths, tritone-substitution", "--ascii"},
+			`Eb Minor
+Scale  Eb F Gb Ab Bb Cb Db
+
+Sevenths
+Position  Chord  Notes
+I7        Ebm7   Eb Gb Bb Db
+II7       Fm7b5  F Ab Cb Eb
+III7      GbM7   Gb Bb Db F
+IV7       Abm7   Ab Cb Eb Gb
+V7        Bbm7   Bb Db F Ab
+VI7       CbM7   Cb Eb Gb Bb
+VII7      Db7    Db F Ab Cb
+
+Tritone Substitution
+Position       Chord  Notes
+sub VII7 / V7  E7     E Ab B D
+`,
+		},
+		{
+			[]string{"--key", "G#", "--scale", "Minor", "--sections", "triads", "--ascii=false"},
+			`G♯ Minor
+Scale  G♯ A♯ B C♯ D♯ E F♯
+
+Triads
+Position  Chord  Notes
+I         G♯m    G♯ B D♯
+II        A♯°    A♯ C♯ E
+III       B      B D♯ F♯
+IV        C♯m    C♯ E G♯
+V         D♯m    D♯ F♯ A♯
+VI        E      E G♯ B
+VII       F♯     F♯ A♯ C♯
+`,
+		},
+		{
+			[]string{"--sections", "secondary-dominants", "--ascii"},
+			`C Major
+Scale  C D E F G A B
+
+Secondary Dominants
+Position  Chord  Notes
+V7 / II   A7     A C# E G
+V7 / III  B7     B D# F# A
+V7 / IV   C7     C E G Bb
+V7 / V    D7     D F# A C
+V7 / VI   E7     E G# B D
+V7 / VII  F#7    F# A# C# E
+`,
+		},
+	}
+
+	for _, e := range tests {
+		var b strings.Builder
+		if assert.NoError(t, runPrint(e.args, &b), e.args) {
+			assert.Equal(t, e.expected, b.String(), e.args)
+		}
+	}
+}
+
+func TestRunPrintErrors(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--key", "H"}, `unknown key "H"`},
+		{[]string{"--scale", "Dorian"}, `unknown scale "Dorian"`},
+		{[]string{"--sections", "triads,ninths"}, `unknown section "ninths"`},
+		{[]string{"extra"}, `unexpected argument "extra"`},
+		{[]string{"--format", "xml"}, `unknown format "xml"`},
+	}
+
+	for _, e := range tests {
+		err := runPrint(e.args, &strings.Builder{})
+		if assert.Error(t, err, e.args) {
+			assert.Contains(t, err.Error(), e.expected, e.args)
+		}
+	}
+}
+
+func TestRunPrintSchema(t *testing.T) {
+	var b strings.Builder
+	if assert.NoError(t, runPrint([]string{"--schema"}, &b)) {
+		assert.Equal(t, string(keySchema), b.String())
+		assert.True(t, json.Valid([]byte(b.String())))
+	}
+}
+
+func TestRunPrintFormats(t *testing.T) {
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"json", `{
+  "version": 1,
+  "key": "C",
+  "scale": "Major",
+  "scaleNotes": [
+    "C",`},
+		{"yaml", `version: 1
+key: C
+scale: Major
+scaleNotes:
+  - C
+`},
+	}
+
+	for _, e := range tests {
+		var b strings.Builder
+		if assert.NoError(t, runPrint([]string{"--format", e.format, "--ascii"}, &b), e.format) {
+			assert.True(t, strings.HasPrefix(b.String(), e.expected), b.String())
+			assert.Contains(t, b.String(), "C♯", e.format)
+		}
+	}
+}
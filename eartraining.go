@@ -0,0 +1,339 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	earTrainingQuiz = "Ear Training"
+
+	// The kinds of ear-training questions.
+	earTriadQuality  = "Triad Quality"
+	earTriad         = "Triad"
+	earSeventh       = "Seventh"
+	earSecondaryDom  = "Secondary Dominant"
+	earTonicInterval = "Interval from Tonic"
+	earInterval      = "Interval"
+
+	earWindow    = 10 // answers at a level that decide whether to change level
+	earLevelUp   = 8  // correct answers in the window that move up a level
+	earLevelDown = 5  // correct answers in the window below which the quiz moves down a level
+)
+
+type (
+	// earLevel is a level of difficulty of the ear-training quiz, with the kinds of questions it asks.
+	earLevel struct {
+		name  string
+		kinds []string
+	}
+
+	// earQuestion is a question of the ear-training quiz: notes of the key to play, as a chord or one after the other,
+	// and the answer to pick among the choices. Questions on where a chord lies in the key play a cadence first so that
+	// the key can be heard.
+	earQuestion struct {
+		key     string
+		scale   string
+		kind    string
+		prompt  string
+		cadence [][]string // chords played before the notes, if any
+		notes   []string
+		melodic bool
+		quality string // of the chord played, empty for intervals
+		answer  string
+		choices []string
+		reveal  string // what was played, shown after the answer, as in "V (G)"
+	}
+
+	// earTrainer asks ear-training questions at a level that follows the accuracy of the recent answers.
+	earTrainer struct {
+		rand    *rand.Rand
+		level   int    // index into earLevels
+		recent  []bool // whether the answers at this level were correct, the latest last
+		results []practiceResult
+	}
+)
+
+var (
+	// earLevels are the levels of the ear-training quiz, easiest first.
+	earLevels = []earLevel{
+		{"Triad Qualities", []string{earTriadQuality}},
+		{"Triads", []string{earTriad}},
+		{"Triads, Sevenths and Intervals from the Tonic", []string{earTriad, earSeventh, earTonicInterval}},
+		{"All Chords and Intervals", []string{earTriad, earSeventh, earSecondaryDom, earInterval}},
+	}
+
+	// intervalNames are the names of the intervals within an octave by their number of semitones less one.
+	intervalNames = []string{
+		"Minor 2nd", "Major 2nd", "Minor 3rd", "Major 3rd", "Perfect 4th", "Tritone",
+		"Perfect 5th", "Minor 6th", "Major 6th", "Minor 7th", "Major 7th",
+	}
+
+	triadQualities = []string{"Major", "Minor", "Diminished"}
+)
+
+func newEarTrainer() earTrainer {
+	return earTrainer{rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// question returns a random question of the current level in the key of the model.
+func (t *earTrainer) question(m *model) earQuestion {
+	kinds := earLevels[t.level].kinds
+	return m.newEarQuestion(kinds[t.rand.Intn(len(kinds))], t.rand)
+}
+
+// record adds the result of an answer and moves up or down a level when the window of recent answers at this level
+// is full and accurate or inaccurate enough.
+func (t *earTrainer) record(r practiceResult) {
+	t.results = append(t.results, r)
+	t.recent = append(t.recent, r.correct)
+	if len(t.recent) < earWindow {
+		return
+	}
+
+	n := countCorrect(t.recent)
+	switch {
+	case n >= earLevelUp && t.level < len(earLevels)-1:
+		t.level++
+		t.recent = nil
+	case n < earLevelDown && t.level > 0:
+		t.level--
+		t.recent = nil
+	default:
+		t.recent = t.recent[1:]
+	}
+}
+
+func countCorrect(results []bool) int {
+	n := 0
+	for _, r := range results {
+		if r {
+			n++
+		}
+	}
+	return n
+}
+
+// stats describes the accuracy of all the answers, of the last earWindow of them and of each kind of question.
+func (t *earTrainer) stats() string {
+	if len(t.results) == 0 {
+		return "No answers yet"
+	}
+
+	var all, last []bool
+	counts := map[string][2]int{} // correct and total answers by kind
+	var kinds []string
+	for i, r := range t.results {
+		all = append(all, r.correct)
+		if i >= len(t.results)-earWindow {
+			last = append(last, r.correct)
+		}
+		c, found := counts[r.kind]
+		if !found {
+			kinds = append(kinds, r.kind)
+		}
+		if r.correct {
+			c[0]++
+		}
+		c[1]++
+		counts[r.kind] = c
+	}
+
+	lines := []string{fmt.Sprintf("%d of %d correct (%d%%), last %d: %d%%",
+		countCorrect(all), len(all), percent(countCorrect(all), len(all)),
+		len(last), percent(countCorrect(last), len(last)))}
+	for _, k := range kinds {
+		lines = append(lines, fmt.Sprintf("%s: %d of %d", k, counts[k][0], counts[k][1]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func percent(n, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return (n*100 + total/2) / total
+}
+
+// newEarQuestion returns a random question of the kind in the key of the model.
+func (m *model) newEarQuestion(kind string, r *rand.Rand) earQuestion {
+	q := earQuestion{key: m.key, scale: m.scale, kind: kind}
+	var chords []chord
+	switch kind {
+	case earTriadQuality, earTriad:
+		chords = m.buildTriads()
+	case earSeventh:
+		chords = m.buildSevenths()
+	case earSecondaryDom:
+		chords = m.buildSecondaryDoms()
+	case earTonicInterval, earInterval:
+		return m.earIntervalQuestion(kind, r)
+	}
+
+	c := chords[r.Intn(len(chords))]
+	q.notes = c.notes
+	q.quality = newChordData(c).Quality
+	q.reveal = fmt.Sprintf("%s (%s)", c.position, c.name)
+	if kind == earTriadQuality {
+		q.prompt = "What is the quality of this triad?"
+		q.answer = strings.ToUpper(q.quality[:1]) + q.quality[1:]
+		q.choices = triadQualities
+		return q
+	}
+
+	triads := m.buildTriads()
+	var cadence []string
+	for _, degree := range []int{0, 3, 4, 0} {
+		q.cadence = append(q.cadence, triads[degree].notes)
+		cadence = append(cadence, triads[degree].position)
+	}
+	q.prompt = fmt.Sprintf("After %s, which %s of %s %s is this?", strings.Join(cadence, "–"), strings.ToLower(kind),
+		m.key, m.scale)
+	q.answer = c.position
+	for _, c := range chords {
+		q.choices = append(q.choices, c.position)
+	}
+	return q
+}
+
+// earIntervalQuestion returns a question on the interval between two notes of the scale played upwards, the first of
+// them the tonic for earTonicInterval.
+func (m *model) earIntervalQuestion(kind string, r *rand.Rand) earQuestion {
+	q := earQuestion{key: m.key, scale: m.scale, kind: kind, melodic: true, prompt: "What is this interval?"}
+	from := 0
+	if kind == earInterval {
+		from = r.Intn(len(m.scaleNotes))
+	}
+	to := (from + 1 + r.Intn(len(m.scaleNotes)-1)) % len(m.scaleNotes)
+	q.notes = []string{m.scaleNotes[from], m.scaleNotes[to]}
+
+	pitches := voiceNotes(q.notes, chordOctave)
+	q.answer = intervalNames[pitches[1].midi()-pitches[0].midi()-1]
+	q.reveal = fmt.Sprintf("%s to %s", q.notes[0], q.notes[1])
+	if kind == earInterval {
+		q.choices = intervalNames
+		return q
+	}
+	for _, n := range m.scaleNotes[1:] {
+		pitches := voiceNotes([]string{m.scaleNotes[0], n}, chordOctave)
+		q.choices = append(q.choices, intervalNames[pitches[1].midi()-pitches[0].midi()-1])
+	}
+	return q
+}
+
+func (m *model) buildEarTraining() fyne.CanvasObject {
+	if m.ear.rand == nil {
+		m.ear = newEarTrainer()
+	}
+	m.earPrompt = widget.NewLabel("")
+	m.earLevel = widget.NewLabel("")
+	m.earFeedback = widget.NewLabel("")
+	m.earStats = widget.NewLabel("")
+	m.earChoices = container.NewGridWithColumns(4)
+
+	m.refreshEarTraining()
+
+	return container.NewVBox(
+		container.NewHBox(
+			widget.NewButton("Next Question", m.nextEarQuestion),
+			widget.NewButton("Play Again", m.playEarQuestion),
+			layout.NewSpacer(),
+			m.earLevel,
+		),
+		m.earPrompt,
+		m.earChoices,
+		m.earFeedback,
+		widget.NewCard("", "Accuracy", m.earStats),
+	)
+}
+
+// nextEarQuestion asks and plays a new question.
+func (m *model) nextEarQuestion() {
+	q := m.ear.question(m)
+	m.earQuestion = &q
+	m.earAsked = time.Now()
+	m.earAnswered = false
+	m.refreshEarTraining()
+	m.playEarQuestion()
+}
+
+// chords returns the MIDI notes of the chords played for a question that is not melodic: the cadence, if any, then
+// the chord asked about.
+func (q earQuestion) chords() [][]int {
+	var chords [][]int
+	for _, notes := range append(append([][]string{}, q.cadence...), q.notes) {
+		chords = append(chords, midiNotes(voiceNotes(notes, chordOctave)))
+	}
+	return chords
+}
+
+func (m *model) playEarQuestion() {
+	if m.earQuestion == nil {
+		return
+	}
+	if m.earQuestion.melodic {
+		m.play(m.synth.melodyNotes(midiNotes(voiceNotes(m.earQuestion.notes, chordOctave))))
+		return
+	}
+	m.play(m.synth.progressionNotes(m.earQuestion.chords()))
+}
+
+// answerEar records the first answer to the question and shows whether it was right.
+func (m *model) answerEar(answer string) {
+	q := m.earQuestion
+	if q == nil || m.earAnswered {
+		return
+	}
+	m.earAnswered = true
+	now := time.Now()
+	correct := answer == q.answer
+	r := practiceResult{
+		time: now, quiz: earTrainingQuiz, kind: q.kind, key: q.key, scale: q.scale, quality: q.quality,
+		question: q.prompt, answer: answer, correct: correct, elapsed: now.Sub(m.earAsked),
+	}
+	m.ear.record(r)
+	m.recordPractice(r)
+
+	m.refreshEarTraining()
+	if correct {
+		m.earFeedback.SetText(fmt.Sprintf("Correct, it was %s: %s", q.answer, q.reveal))
+	} else {
+		m.earFeedback.SetText(fmt.Sprintf("It was %s, not %s: %s", q.answer, answer, q.reveal))
+	}
+}
+
+// refreshEarTraining shows the question being asked, or asks for a new one after the key or scale changed.
+func (m *model) refreshEarTraining() {
+	if m.earChoices == nil {
+		return
+	}
+	if m.earQuestion != nil && (m.earQuestion.key != m.key || m.earQuestion.scale != m.scale) {
+		m.earQuestion = nil
+	}
+
+	m.earLevel.SetText(fmt.Sprintf("Level %d of %d: %s", m.ear.level+1, len(earLevels), earLevels[m.ear.level].name))
+	m.earStats.SetText(m.ear.stats())
+	m.earFeedback.SetText("")
+	m.earChoices.RemoveAll()
+	if m.earQuestion == nil {
+		m.earPrompt.SetText("Press Next Question to hear a chord or interval of the key")
+		return
+	}
+
+	m.earPrompt.SetText(m.earQuestion.prompt)
+	for _, c := range m.earQuestion.choices {
+		c := c
+		b := widget.NewButton(c, func() { m.answerEar(c) })
+		if m.earAnswered && c == m.earQuestion.answer {
+			b.Importance = widget.HighImportance
+		}
+		m.earChoices.Add(b)
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEarQuestion(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, scale := range scaleNames {
+		m := sheetModel("E♭", scale)
+		chords := map[string][]chord{
+			earTriadQuality: m.buildTriads(),
+			earTriad:        m.buildTriads(),
+			earSeventh:      m.buildSevenths(),
+			earSecondaryDom: m.buildSecondaryDoms(),
+		}
+		for _, kind := range []string{earTriadQuality, earTriad, earSeventh, earSecondaryDom, earTonicInterval,
+			earInterval} {
+			for i := 0; i < 20; i++ {
+				q := m.newEarQuestion(kind, r)
+				assert.Contains(t, q.choices, q.answer, "%s %s", kind, scale)
+				assert.Equal(t, "E♭", q.key)
+				if cs, ok := chords[kind]; ok {
+					assert.False(t, q.melodic)
+					found := false
+					for _, c := range cs {
+						if equalNotes(c.notes, q.notes) {
+							found = kind == earTriadQuality || c.position == q.answer
+						}
+					}
+					assert.True(t, found, "%s %v %s", kind, q.notes, q.answer)
+					if kind == earTriadQuality {
+						assert.Empty(t, q.cadence, "qualities are heard without the key")
+						continue
+					}
+					triads := chords[earTriad]
+					assert.Equal(t, [][]string{triads[0].notes, triads[3].notes, triads[4].notes, triads[0].notes},
+						q.cadence)
+					if kind == earTriad && scale == "Major" {
+						assert.Equal(t, "After I–IV–V–I, which triad of E♭ Major is this?", q.prompt)
+					}
+					played := q.chords()
+					assert.Len(t, played, 5)
+					assert.Equal(t, midiNotes(voiceNotes(triads[0].notes, chordOctave)), played[0],
+						"the cadence on the tonic is played first")
+					assert.Equal(t, midiNotes(voiceNotes(q.notes, chordOctave)), played[4],
+						"the chord asked about is played last")
+					continue
+				}
+				assert.True(t, q.melodic)
+				assert.Len(t, q.notes, 2)
+				assert.NotEqual(t, q.notes[0], q.notes[1])
+				if kind == earTonicInterval {
+					assert.Equal(t, "E♭", q.notes[0])
+					assert.Len(t, q.choices, 6)
+				}
+			}
+		}
+	}
+}
+
+func equalNotes(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestEarQuestionAnswers(t *testing.T) {
+	tests := []struct {
+		key, scale string
+		kind       string
+		notes      []string
+		answer     string
+	}{
+		{"C", "Major", earTriadQuality, []string{"B", "D", "F"}, "Diminished"},
+		{"C", "Minor", earTriadQuality, []string{"E♭", "G", "B♭"}, "Major"},
+		{"C", "Major", earTonicInterval, []string{"C", "G"}, "Perfect 5th"},
+		{"C", "Major", earInterval, []string{"F", "B"}, "Tritone"},
+		{"C", "Minor", earInterval, []string{"B♭", "E♭"}, "Perfect 4th"},
+	}
+	for _, tt := range tests {
+		m := sheetModel(tt.key, tt.scale)
+		r := rand.New(rand.NewSource(1))
+		found := false
+		for i := 0; i < 1000 && !found; i++ {
+			q := m.newEarQuestion(tt.kind, r)
+			if found = equalNotes(q.notes, tt.notes); found {
+				assert.Equal(t, tt.answer, q.answer, "%v", tt.notes)
+			}
+		}
+		assert.True(t, found, "%v", tt.notes)
+	}
+}
+
+func TestEarTrainerLevels(t *testing.T) {
+	tr := earTrainer{rand: rand.New(rand.NewSource(1))}
+	answer := func(n int, correct bool) {
+		for i := 0; i < n; i++ {
+			tr.record(practiceResult{kind: earTriad, correct: correct})
+		}
+	}
+
+	answer(earWindow-1, true)
+	assert.Equal(t, 0, tr.level, "the window is not full")
+	answer(1, true)
+	assert.Equal(t, 1, tr.level)
+	assert.Empty(t, tr.recent)
+
+	answer(6, true)
+	answer(4, false)
+	assert.Equal(t, 1, tr.level, "6 of 10 keeps the level")
+	answer(2, false)
+	assert.Equal(t, 0, tr.level, "4 of the last 10 moves down")
+
+	tr.level = len(earLevels) - 1
+	answer(earWindow, true)
+	assert.Equal(t, len(earLevels)-1, tr.level, "there is no level above the last")
+
+	m := sheetModel("C", "Major")
+	for i := 0; i < 20; i++ {
+		assert.Contains(t, earLevels[tr.level].kinds, tr.question(m).kind)
+	}
+}
+
+func TestEarTrainerStats(t *testing.T) {
+	tr := earTrainer{}
+	assert.Equal(t, "No answers yet", tr.stats())
+
+	for i := 0; i < 12; i++ {
+		tr.record(practiceResult{kind: earTriad, correct: i >= 2})
+	}
+	tr.record(practiceResult{kind: earInterval})
+	assert.Equal(t, "10 of 13 correct (77%), last 10: 90%\nTriad: 10 of 12\nInterval: 0 of 1", tr.stats())
+}
+
+func TestEarTraining(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	m := sheetModel("C", "Major")
+	m.buildEarTraining()
+	assert.Empty(t, m.earChoices.Objects)
+	assert.Equal(t, "Level 1 of 4: Triad Qualities", m.earLevel.Text)
+
+	m.nextEarQuestion()
+	assert.Equal(t, "What is the quality of this triad?", m.earPrompt.Text)
+	assert.Len(t, m.earChoices.Objects, len(triadQualities))
+	wrong := m.earChoices.Objects[0].(*widget.Button)
+	if wrong.Text == m.earQuestion.answer {
+		wrong = m.earChoices.Objects[1].(*widget.Button)
+	}
+	test.Tap(wrong)
+	assert.Contains(t, m.earFeedback.Text, "It was "+m.earQuestion.answer)
+	assert.Equal(t, "0 of 1 correct (0%), last 1: 0%\nTriad Quality: 0 of 1", m.earStats.Text)
+	for _, o := range m.earChoices.Objects {
+		b := o.(*widget.Button)
+		assert.Equal(t, b.Text == m.earQuestion.answer, b.Importance == widget.HighImportance, b.Text)
+	}
+	test.Tap(m.earChoices.Objects[0].(*widget.Button))
+	assert.Len(t, m.ear.results, 1, "only the first answer counts")
+
+	m.nextEarQuestion()
+	for _, o := range m.earChoices.Objects {
+		if b := o.(*widget.Button); b.Text == m.earQuestion.answer {
+			test.Tap(b)
+		}
+	}
+	assert.Contains(t, m.earFeedback.Text, "Correct")
+	assert.Len(t, m.ear.results, 2)
+	assert.Len(t, m.history, 2)
+	assert.Equal(t, earTrainingQuiz, m.history[1].quiz)
+	assert.True(t, m.history[1].correct)
+
+	m.setKey("D")
+	m.refreshEarTraining()
+	assert.Nil(t, m.earQuestion, "questions of another key are dropped")
+	assert.Empty(t, m.earChoices.Objects)
+}
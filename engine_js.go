@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"syscall/js"
+)
+
+// engineCommand runs the engine without a window, as with go.argv = ["chords-for-keys", "engine"], so that it can be
+// used from Node or by pages that draw their own. Such builds are made with the ci tag, which leaves out Fyne's web
+// driver and its need of a browser:
+//
+//	GOOS=js GOARCH=wasm go build -tags ci -o chords-for-keys-engine.wasm
+const engineCommand = "engine"
+
+// engineFunctions are the functions of the engine, named after the paths of the endpoints they answer.
+var engineFunctions = []string{"keys", "scale", "chords", "chord", "identify", "transpose"}
+
+func init() {
+	commands[engineCommand] = runEngine
+	exportEngine()
+}
+
+// runEngine keeps the engine running without a window until the page or process goes away.
+func runEngine([]string) error {
+	select {}
+}
+
+// exportEngine sets globalThis.chordsForKeys to the engine. In the browser build, made with
+//
+//	GOOS=js GOARCH=wasm go build -o chords-for-keys.wasm
+//
+// or packaged with its page by fyne package -os web, the app draws its window in the page with Fyne's web driver, and
+// the engine answers the requests of the HTTP API without a server. Each function takes the query parameters of its
+// endpoint in openapi.json as an object, with arrays for comma-separated lists, and returns the response object,
+// which has an error field if the request failed:
+//
+//	chordsForKeys.chords({key: "Eb", scale: "Minor", sections: ["triads", "sevenths"]})
+//	chordsForKeys.section("secondary-dominants", {key: "D"})
+//	chordsForKeys.identify({notes: [60, 64, 67, 70]})
+func exportEngine() {
+	engine := js.Global().Get("Object").New()
+	for _, path := range engineFunctions {
+		path := path
+		engine.Set(path, js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+			return callEngine(path, args)
+		}))
+	}
+	engine.Set("section", js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		if len(args) == 0 || args[0].Type() != js.TypeString {
+			return toJS(map[string]string{"error": "missing chord section"})
+		}
+		return callEngine("chords/"+args[0].String(), args[1:])
+	}))
+	engine.Set("version", schemaVersion)
+	js.Global().Set("chordsForKeys", engine)
+}
+
+// callEngine answers the request of the API path with the query parameters of the object that is the first argument.
+func callEngine(path string, args []js.Value) interface{} {
+	q := url.Values{}
+	if len(args) > 0 && args[0].Type() == js.TypeObject {
+		keys := js.Global().Get("Object").Call("keys", args[0])
+		for i := 0; i < keys.Length(); i++ {
+			k := keys.Index(i).String()
+			q.Set(k, jsParam(args[0].Get(k)))
+		}
+	}
+
+	h, ok := apiRoute(path)
+	if !ok {
+		return toJS(map[string]string{"error": "no endpoint " + path})
+	}
+	r := &http.Request{Method: http.MethodGet, URL: &url.URL{Path: apiPrefix + path, RawQuery: q.Encode()}}
+	_, v := h.answer(r)
+	return toJS(v)
+}
+
+// jsParam returns the query parameter of a JavaScript value: arrays are joined with commas and anything else is
+// converted to a string.
+func jsParam(v js.Value) string {
+	if js.Global().Get("Array").Call("isArray", v).Bool() {
+		return v.Call("join", ",").String()
+	}
+	return js.Global().Get("String").Invoke(v).String()
+}
+
+// toJS converts a value to a JavaScript object through JSON, with the same fields as the responses of the HTTP API.
+func toJS(v interface{}) js.Value {
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(map[string]string{"error": err.Error()})
+	}
+	return js.Global().Get("JSON").Call("parse", string(b))
+}
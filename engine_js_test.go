@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"syscall/js"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The engine is tested in the compiled WASM module under Node with
+//
+//	GOOS=js GOARCH=wasm go test -tags ci -exec "$(go env GOROOT)/lib/wasm/go_js_wasm_exec" -run Engine .
+
+func TestEngine(t *testing.T) {
+	engine := js.Global().Get("chordsForKeys")
+	object := func(values map[string]interface{}) js.Value {
+		return js.ValueOf(values)
+	}
+
+	tests := []struct {
+		name     string
+		args     []interface{}
+		expected string // JSON of the result
+	}{
+		{
+			"scale",
+			[]interface{}{object(map[string]interface{}{"key": "Eb", "scale": "Minor"})},
+			`{"version":1,"key":"E♭","scale":"Minor","scaleNotes":["E♭","F","G♭","A♭","B♭","C♭","D♭"],"sections":[]}`,
+		},
+		{
+			"section",
+			[]interface{}{"tritone-substitution", object(map[string]interface{}{"key": "G"})},
+			`{"id":"tritone-substitution","title":"Tritone Substitution","chords":[{"name":"A♭7","position":"sub VII⁷ / V⁷",
+				"root":"A♭","quality":"dominant seventh","notes":["A♭","C","E♭","G♭"],"pitchClasses":[8,0,3,6],
+				"intervals":[0,4,7,10]}]}`,
+		},
+		{
+			"chord",
+			[]interface{}{object(map[string]interface{}{"name": "F#maj7"})},
+			`{"name":"F♯M7","root":"F♯","quality":"major seventh","notes":["F♯","A♯","C♯","E♯"],"pitchClasses":[6,10,1,5],
+				"intervals":[0,4,7,11]}`,
+		},
+		{
+			"identify",
+			[]interface{}{object(map[string]interface{}{"notes": []interface{}{64, 67, 70, 72}})},
+			`{"chord":{"name":"C7/E","root":"C","bass":"E","quality":"dominant seventh","notes":["C","E","G","B♭"],
+				"pitchClasses":[0,4,7,10],"intervals":[0,4,7,10]},"noFifth":false}`,
+		},
+		{
+			"transpose",
+			[]interface{}{object(map[string]interface{}{"chords": []interface{}{"C", "Am7", "F/A"}, "from": "C", "to": "Eb"})},
+			`{"from":"C","to":"E♭","scale":"Major","chords":["E♭","Cm7","A♭/C"]}`,
+		},
+		{"scale", []interface{}{object(map[string]interface{}{"key": "H"})}, `{"error":"unknown key \"H\""}`},
+		{"section", []interface{}{"ninths"}, `{"error":"unknown section \"ninths\", expected one of triads, sevenths, ` +
+			`secondary-dominants, secondary-lead-tones, tritone-substitution"}`},
+		{"section", nil, `{"error":"missing chord section"}`},
+	}
+
+	for _, e := range tests {
+		result := js.Global().Get("JSON").Call("stringify", engine.Call(e.name, e.args...)).String()
+		var got, expected interface{}
+		assert.NoError(t, json.Unmarshal([]byte(result), &got), e.name)
+		if assert.NoError(t, json.Unmarshal([]byte(e.expected), &expected), e.name) {
+			assert.Equal(t, expected, got, e.name)
+		}
+	}
+
+	assert.Equal(t, schemaVersion, engine.Get("version").Int())
+	_, ok := commands[engineCommand]
+	assert.True(t, ok)
+}
@@ -0,0 +1,392 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	flashcardsQuiz    = "Flashcards"
+	flashcardsFile    = "flashcards.json"
+	flashcardsVersion = 1
+	flashcardLearned  = 3 // box from which a card counts as learned, due a day or more later
+
+	// The kinds of flashcards.
+	flashcardName    = "Chord Name"
+	flashcardNumeral = "Roman Numeral"
+)
+
+type (
+	// flashcard asks for the notes of a chord, named or as a Roman numeral in a key.
+	flashcard struct {
+		id      string
+		kind    string
+		prompt  string
+		key     string // of Roman numeral cards
+		scale   string
+		name    string
+		quality string
+		notes   []string
+	}
+
+	// flashcardReview is the spaced-repetition state of a card that has been answered.
+	flashcardReview struct {
+		Box     int       `json:"box"` // index into flashcardIntervals
+		Due     time.Time `json:"due"`
+		Reviews int       `json:"reviews"`
+		Lapses  int       `json:"lapses"` // wrong answers
+	}
+
+	// flashcardState is what is kept of the deck between sessions.
+	flashcardState struct {
+		Version int                         `json:"version"`
+		Reviews map[string]*flashcardReview `json:"reviews"`
+	}
+
+	// flashcardDeck schedules the cards with Leitner boxes: a right answer moves a card up a box and a wrong one back
+	// to the first, and each box waits longer before the card is due again.
+	flashcardDeck struct {
+		path    string // file the reviews are kept in, or empty to keep them for the session only
+		cards   []flashcard
+		reviews map[string]*flashcardReview
+		rand    *rand.Rand
+	}
+)
+
+// flashcardIntervals are the waits before a card is due again by its box.
+var flashcardIntervals = []time.Duration{
+	time.Minute, 10 * time.Minute, time.Hour, 24 * time.Hour, 3 * 24 * time.Hour, 7 * 24 * time.Hour,
+	16 * 24 * time.Hour, 35 * 24 * time.Hour,
+}
+
+// flashcards returns the cards of the triads and sevenths by name, and of the triads, sevenths and secondary
+// dominants by Roman numeral, in every key whose scale is spelled with each letter once. Other keys, which need
+// double sharps or flats, are left out, as are chords not spelled in thirds, since the cards grade spelling strictly.
+func flashcards() []flashcard {
+	var cards []flashcard
+	named := map[string]bool{}
+	for _, scale := range scaleNames {
+		for _, key := range keyNames {
+			m, err := keyModel(key, scale)
+			if err != nil || !spelledWithLetters(m.scaleNotes) {
+				continue
+			}
+			for i, chords := range [][]chord{m.buildTriads(), m.buildSevenths(), m.buildSecondaryDoms()} {
+				for _, c := range chords {
+					if !spelledInThirds(c.notes) {
+						continue
+					}
+					quality := newChordData(c).Quality
+					if i < 2 && !named[c.name] {
+						named[c.name] = true
+						cards = append(cards, flashcard{
+							id: c.name, kind: flashcardName, prompt: c.name, name: c.name, quality: quality,
+							notes: c.notes,
+						})
+					}
+					prompt := fmt.Sprintf("%s in %s %s", c.position, key, scale)
+					cards = append(cards, flashcard{
+						id: prompt, kind: flashcardNumeral, prompt: prompt, key: key, scale: scale, name: c.name,
+						quality: quality, notes: c.notes,
+					})
+				}
+			}
+		}
+	}
+	return cards
+}
+
+// spelledWithLetters reports whether the notes use each letter once.
+func spelledWithLetters(notes []string) bool {
+	letters := map[byte]bool{}
+	for _, n := range notes {
+		letters[n[0]] = true
+	}
+	return len(letters) == len(notes) && len(notes) == len(steps)
+}
+
+// spelledInThirds reports whether each note is spelled a third above the one before.
+func spelledInThirds(notes []string) bool {
+	for i := 1; i < len(notes); i++ {
+		if (strings.IndexByte(steps, notes[i][0])-strings.IndexByte(steps, notes[i-1][0])+len(steps))%len(steps) != 2 {
+			return false
+		}
+	}
+	return true
+}
+
+// loadFlashcards returns the deck with the reviews kept in the file at path, which may not exist yet.
+func loadFlashcards(path string) (*flashcardDeck, error) {
+	d := &flashcardDeck{
+		path:    path,
+		cards:   flashcards(),
+		reviews: map[string]*flashcardReview{},
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	if path == "" {
+		return d, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return d, nil
+	}
+	if err != nil {
+		return d, err
+	}
+	var state flashcardState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return d, fmt.Errorf("%s: %w", path, err)
+	}
+	if state.Version > flashcardsVersion {
+		return d, fmt.Errorf("%s: unknown version %d", path, state.Version)
+	}
+	for id, r := range state.Reviews {
+		if r != nil {
+			d.reviews[id] = r
+		}
+	}
+	return d, nil
+}
+
+// save writes the reviews to the file of the deck.
+func (d *flashcardDeck) save() error {
+	if d.path == "" {
+		return nil
+	}
+	b, err := json.MarshalIndent(flashcardState{Version: flashcardsVersion, Reviews: d.reviews}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(d.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(d.path, b, 0o644)
+}
+
+// next returns the card to ask: the most overdue card, or else a random new one, or else the card due soonest.
+func (d *flashcardDeck) next(now time.Time) flashcard {
+	var due, soonest *flashcard
+	var fresh []int
+	for i := range d.cards {
+		c := &d.cards[i]
+		r, ok := d.reviews[c.id]
+		if !ok {
+			fresh = append(fresh, i)
+			continue
+		}
+		if soonest == nil || r.Due.Before(d.reviews[soonest.id].Due) {
+			soonest = c
+		}
+		if !r.Due.After(now) && (due == nil || r.Due.Before(d.reviews[due.id].Due)) {
+			due = c
+		}
+	}
+
+	switch {
+	case due != nil:
+		return *due
+	case len(fresh) > 0:
+		return d.cards[fresh[d.rand.Intn(len(fresh))]]
+	default:
+		return *soonest
+	}
+}
+
+// review moves the card up a box after a right answer or back to the first after a wrong one.
+func (d *flashcardDeck) review(c flashcard, correct bool, now time.Time) {
+	r, ok := d.reviews[c.id]
+	if !ok {
+		r = &flashcardReview{Box: -1}
+		d.reviews[c.id] = r
+	}
+	r.Reviews++
+	if correct {
+		r.Box = minInt(r.Box+1, len(flashcardIntervals)-1)
+	} else {
+		r.Box = 0
+		r.Lapses++
+	}
+	r.Due = now.Add(flashcardIntervals[r.Box])
+}
+
+// stats describes how many cards are due, learned and new.
+func (d *flashcardDeck) stats(now time.Time) string {
+	due, learned, fresh := 0, 0, 0
+	for _, c := range d.cards {
+		r, ok := d.reviews[c.id]
+		if !ok {
+			fresh++
+			continue
+		}
+		if !r.Due.After(now) {
+			due++
+		}
+		if r.Box >= flashcardLearned {
+			learned++
+		}
+	}
+	return fmt.Sprintf("%d due, %d learned, %d new of %d cards", due, learned, fresh, len(d.cards))
+}
+
+// gradeSpelling grades the notes of the answer, separated by spaces or commas in any order, against the notes of a
+// chord. Notes must be spelled as in the chord: F♯ is not G♭. The message says what was wrong, if anything. An answer
+// that is not a list of notes is an error rather than a wrong answer.
+func gradeSpelling(want []string, answer string) (bool, string, error) {
+	fields := strings.FieldsFunc(answer, func(r rune) bool { return r == ' ' || r == ',' })
+	if len(fields) == 0 {
+		return false, "", errors.New("no notes were given")
+	}
+
+	var got []string
+	for _, f := range fields {
+		step, alter, ok := parseNote(f)
+		if !ok {
+			return false, "", fmt.Errorf("%s is not a note", f)
+		}
+		got = append(got, pitch{step: step, alter: alter}.name())
+	}
+
+	if sameNotes(got, want, func(n string) string { return n }) {
+		return true, "", nil
+	}
+	if sameNotes(got, want, func(n string) string { return fmt.Sprint(notePitchClass(n)) }) {
+		return false, "Right pitches, wrong spelling", nil
+	}
+	if len(got) != len(want) {
+		return false, fmt.Sprintf("The chord has %d notes", len(want)), nil
+	}
+	return false, "Wrong notes", nil
+}
+
+// sameNotes reports whether the notes are the same apart from their order, compared by the key of each.
+func sameNotes(a, b []string, key func(string) string) bool {
+	keys := func(notes []string) string {
+		var ks []string
+		for _, n := range notes {
+			ks = append(ks, key(n))
+		}
+		sort.Strings(ks)
+		return strings.Join(ks, " ")
+	}
+	return len(a) == len(b) && keys(a) == keys(b)
+}
+
+func (m *model) buildFlashcards() fyne.CanvasObject {
+	if m.flash == nil {
+		path, err := configPath(flashcardsFile)
+		if err != nil {
+			fyne.LogError("Unable to find where to keep flashcards, reviews last for this session", err)
+		}
+		m.flash, err = loadFlashcards(path)
+		if err != nil {
+			fyne.LogError("Unable to load flashcards", err)
+		}
+	}
+
+	m.flashPrompt = widget.NewLabelWithStyle("", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+	m.flashFeedback = widget.NewLabel("")
+	m.flashStats = widget.NewLabel("")
+	m.flashEntry = widget.NewEntry()
+	m.flashEntry.SetPlaceHolder("Notes from the root up, as in F♯ A C♯ or F# A C#")
+	m.flashEntry.OnSubmitted = func(string) {
+		if m.flashAnswered {
+			m.nextFlashcard()
+		} else {
+			m.checkFlashcard()
+		}
+	}
+
+	var noteButtons []fyne.CanvasObject
+	for _, letter := range []string{"C", "D", "E", "F", "G", "A", "B"} {
+		letter := letter
+		noteButtons = append(noteButtons, widget.NewButton(letter, func() {
+			text := strings.TrimSpace(m.flashEntry.Text)
+			if text != "" {
+				text += " "
+			}
+			m.flashEntry.SetText(text + letter)
+		}))
+	}
+	// Fyne's text rendering does not reach 𝄪 and 𝄫, so double accidentals are entered in ASCII.
+	for _, accidental := range []string{sharp, flat, "##", "bb"} {
+		accidental := accidental
+		noteButtons = append(noteButtons, widget.NewButton(accidental, func() {
+			m.flashEntry.SetText(strings.TrimSpace(m.flashEntry.Text) + accidental)
+		}))
+	}
+	noteButtons = append(noteButtons, widget.NewButton("Clear", func() { m.flashEntry.SetText("") }))
+
+	m.nextFlashcard()
+
+	return container.NewVBox(
+		m.flashPrompt,
+		m.flashEntry,
+		container.NewGridWithColumns(len(noteButtons), noteButtons...),
+		container.NewGridWithColumns(2,
+			widget.NewButton("Check", m.checkFlashcard),
+			widget.NewButton("Next Card", m.nextFlashcard),
+		),
+		m.flashFeedback,
+		widget.NewCard("", "Review", m.flashStats),
+	)
+}
+
+// nextFlashcard shows the next card of the deck.
+func (m *model) nextFlashcard() {
+	c := m.flash.next(time.Now())
+	m.flashCard = &c
+	m.flashAsked = time.Now()
+	m.flashAnswered = false
+	m.flashPrompt.SetText(c.prompt)
+	m.flashEntry.SetText("")
+	m.flashFeedback.SetText("")
+	m.flashStats.SetText(m.flash.stats(time.Now()))
+}
+
+// checkFlashcard grades the notes entered for the card, reviews it if it was not answered already and plays it.
+func (m *model) checkFlashcard() {
+	c := m.flashCard
+	if c == nil {
+		return
+	}
+	correct, message, err := gradeSpelling(c.notes, m.flashEntry.Text)
+	if err != nil {
+		m.flashFeedback.SetText(strings.ToUpper(err.Error()[:1]) + err.Error()[1:])
+		return
+	}
+
+	if !m.flashAnswered {
+		m.flashAnswered = true
+		now := time.Now()
+		m.flash.review(*c, correct, now)
+		m.recordPractice(practiceResult{
+			time: now, quiz: flashcardsQuiz, kind: c.kind, key: c.key, scale: c.scale, quality: c.quality,
+			question: c.prompt, answer: m.flashEntry.Text, correct: correct, elapsed: now.Sub(m.flashAsked),
+		})
+		if err := m.flash.save(); err != nil {
+			fyne.LogError("Unable to save flashcards", err)
+		}
+	}
+
+	answer := fmt.Sprintf("%s is %s", c.name, strings.Join(c.notes, " "))
+	if correct {
+		m.flashFeedback.SetText("Correct: " + answer)
+	} else {
+		m.flashFeedback.SetText(fmt.Sprintf("%s: %s", message, answer))
+	}
+	m.flashStats.SetText(m.flash.stats(time.Now()))
+	m.playChord(chord{notes: c.notes})
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlashcards(t *testing.T) {
+	cards := flashcards()
+	byID := map[string]flashcard{}
+	for _, c := range cards {
+		_, found := byID[c.id]
+		assert.False(t, found, "%s is not repeated", c.id)
+		byID[c.id] = c
+		assert.True(t, spelledInThirds(c.notes), "%s %v", c.id, c.notes)
+	}
+
+	assert.Equal(t, []string{"F♯", "A", "C♯", "E"}, byID["F♯m7"].notes)
+	assert.Equal(t, []string{"G♭", "B♭", "D♭"}, byID["I in G♭ Major"].notes)
+	assert.Equal(t, []string{"A", "C♯", "E", "G"}, byID["V⁷ / II in C Major"].notes)
+	assert.Equal(t, []string{"B", "D", "F", "A"}, byID["II⁷ in A Minor"].notes)
+	for _, id := range []string{"I in G♯ Major", "I in D♯ Major", "I in G♭ Minor"} {
+		_, found := byID[id]
+		assert.False(t, found, "%s needs double accidentals", id)
+	}
+}
+
+func TestGradeSpelling(t *testing.T) {
+	tests := []struct {
+		answer  string
+		correct bool
+		message string
+		err     string
+	}{
+		{"F♯ A C♯", true, "", ""},
+		{"F# A C#", true, "", ""},
+		{"C♯, F♯ A", true, "", ""},
+		{"G♭ A D♭", false, "Right pitches, wrong spelling", ""},
+		{"F♯ A", false, "The chord has 3 notes", ""},
+		{"F♯ A♯ C♯", false, "Wrong notes", ""},
+		{"F♯ H C♯", false, "", "H is not a note"},
+		{"  ", false, "", "no notes were given"},
+	}
+	for _, tt := range tests {
+		correct, message, err := gradeSpelling([]string{"F♯", "A", "C♯"}, tt.answer)
+		assert.Equal(t, tt.correct, correct, tt.answer)
+		assert.Equal(t, tt.message, message, tt.answer)
+		if tt.err != "" {
+			assert.EqualError(t, err, tt.err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
+
+func TestFlashcardDeck(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	d := &flashcardDeck{
+		cards: []flashcard{
+			{id: "C", notes: []string{"C", "E", "G"}},
+			{id: "Dm", notes: []string{"D", "F", "A"}},
+			{id: "Em", notes: []string{"E", "G", "B"}},
+		},
+		reviews: map[string]*flashcardReview{},
+		rand:    rand.New(rand.NewSource(1)),
+	}
+	assert.Equal(t, "0 due, 0 learned, 3 new of 3 cards", d.stats(now))
+
+	d.review(d.cards[0], true, now)
+	assert.Equal(t, flashcardReview{Box: 0, Due: now.Add(time.Minute), Reviews: 1}, *d.reviews["C"])
+	d.review(d.cards[1], false, now)
+	assert.Equal(t, flashcardReview{Box: 0, Due: now.Add(time.Minute), Reviews: 1, Lapses: 1}, *d.reviews["Dm"])
+	assert.Equal(t, "Em", d.next(now).id, "new cards come before cards not yet due")
+
+	d.review(d.cards[2], true, now.Add(time.Second))
+	assert.Equal(t, "C", d.next(now.Add(time.Hour)).id, "the most overdue card comes first")
+	assert.Equal(t, "C", d.next(now).id, "the card due soonest comes when none is due or new")
+
+	for i := 0; i < 4; i++ {
+		d.review(d.cards[0], true, now)
+	}
+	assert.Equal(t, now.Add(3*24*time.Hour), d.reviews["C"].Due)
+	assert.Equal(t, "2 due, 1 learned, 0 new of 3 cards", d.stats(now.Add(time.Hour)))
+
+	for i := 0; i < 10; i++ {
+		d.review(d.cards[0], true, now)
+	}
+	assert.Equal(t, len(flashcardIntervals)-1, d.reviews["C"].Box, "there is no box above the last")
+	d.review(d.cards[0], false, now)
+	assert.Equal(t, 0, d.reviews["C"].Box)
+}
+
+func TestFlashcardsSaved(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chords-for-keys", flashcardsFile)
+	d, err := loadFlashcards(path)
+	assert.NoError(t, err)
+	assert.Empty(t, d.reviews)
+
+	now := time.Now().Round(0)
+	d.review(d.cards[0], true, now)
+	d.review(d.cards[1], false, now)
+	assert.NoError(t, d.save())
+
+	loaded, err := loadFlashcards(path)
+	assert.NoError(t, err)
+	assert.Equal(t, d.reviews[d.cards[0].id].Due.Unix(), loaded.reviews[d.cards[0].id].Due.Unix())
+	assert.Equal(t, 1, loaded.reviews[d.cards[1].id].Lapses)
+	assert.Len(t, loaded.reviews, 2)
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"version": 2}`), 0o644))
+	_, err = loadFlashcards(path)
+	assert.EqualError(t, err, path+": unknown version 2")
+}
+
+func TestFlashcardsUI(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	m := sheetModel("C", "Major")
+	m.buildFlashcards()
+	c := *m.flashCard
+	assert.Equal(t, c.prompt, m.flashPrompt.Text)
+
+	m.checkFlashcard()
+	assert.Equal(t, "No notes were given", m.flashFeedback.Text)
+	assert.Empty(t, m.flash.reviews)
+
+	test.Type(m.flashEntry, "X")
+	m.flashEntry.OnSubmitted(m.flashEntry.Text)
+	assert.Equal(t, "X is not a note", m.flashFeedback.Text)
+	assert.Empty(t, m.flash.reviews)
+
+	m.flashEntry.SetText("C C C")
+	m.flashEntry.OnSubmitted(m.flashEntry.Text)
+	assert.Contains(t, m.flashFeedback.Text, c.name+" is ")
+	assert.Equal(t, 1, m.flash.reviews[c.id].Lapses)
+
+	m.flashEntry.SetText("")
+	for _, n := range c.notes {
+		m.flashEntry.SetText(m.flashEntry.Text + " " + n)
+	}
+	m.checkFlashcard()
+	assert.Contains(t, m.flashFeedback.Text, "Correct")
+	assert.Equal(t, 1, m.flash.reviews[c.id].Reviews, "a card is reviewed once")
+
+	path, err := configPath(flashcardsFile)
+	assert.NoError(t, err)
+	loaded, err := loadFlashcards(path)
+	assert.NoError(t, err)
+	assert.Len(t, loaded.reviews, 1)
+
+	m.flashEntry.OnSubmitted("")
+	assert.False(t, m.flashAnswered)
+	assert.Empty(t, m.flashEntry.Text)
+}
@@ -0,0 +1,541 @@
+package main
+
+import (
+	"image/color"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	fretboardFrets = 22
+	chordSpan      = 4  // frets a chord shape may cover
+	maxChordFret   = 12 // highest fret a chord shape may start on
+	mutedString    = -1
+
+	labelNotes   = "Notes"
+	labelDegrees = "Degrees"
+
+	positionAll = "All"
+)
+
+type (
+	// tuning is a stringed instrument tuning. Strings are listed lowest first as MIDI note numbers.
+	tuning struct {
+		name    string
+		strings []int
+	}
+
+	// fretSpot is a single string and fret on the neck. Strings are numbered from 0, the lowest string.
+	fretSpot struct {
+		str  int
+		fret int
+	}
+
+	// fretNote is a scale note found on the neck.
+	fretNote struct {
+		fretSpot
+		note   string
+		degree int // 0 based index into the scale
+	}
+
+	// cagedShape is one of the five CAGED positions, given as a fret window relative to the root on the lowest
+	// string of the E shape.
+	cagedShape struct {
+		name     string
+		from, to int
+	}
+
+	// fretboard shows every position of the current scale on the neck of a stringed instrument.
+	fretboard struct {
+		widget.BaseWidget
+
+		tuning     tuning
+		scaleNotes []string
+		intervals  []int
+		position   string
+		labels     string
+
+		chord map[int]bool // pitch classes of the selected chord
+		root  int          // pitch class of the selected chord root, or -1 if there is no chord
+	}
+
+	fretboardRenderer struct {
+		f          *fretboard
+		background *canvas.Rectangle
+		nut        *canvas.Line
+		strings    []*canvas.Line
+		frets      []*canvas.Line
+		markers    []*canvas.Circle
+		notes      []fretNote
+		dots       []*canvas.Circle
+		texts      []*canvas.Text
+	}
+)
+
+var (
+	tunings = []tuning{
+		{"Guitar (Standard)", []int{40, 45, 50, 55, 59, 64}},
+		{"Guitar (Drop D)", []int{38, 45, 50, 55, 59, 64}},
+		{"Guitar (DADGAD)", []int{38, 45, 50, 55, 57, 62}},
+		{"Bass (Standard)", []int{28, 33, 38, 43}},
+	}
+
+	cagedShapes = []cagedShape{
+		{"E", -1, 2},
+		{"D", 1, 5},
+		{"C", 4, 7},
+		{"A", 6, 9},
+		{"G", 9, 12},
+	}
+
+	fretMarkers = []int{3, 5, 7, 9, 12, 15, 17, 19, 21}
+
+	fretboardLabels = []string{labelNotes, labelDegrees}
+	fretPositions   []string
+)
+
+var _ fyne.Widget = (*fretboard)(nil)
+
+func init() {
+	fretPositions = append(fretPositions, positionAll)
+	for _, s := range cagedShapes {
+		fretPositions = append(fretPositions, "CAGED "+s.name)
+	}
+	for i := 1; i <= 7; i++ {
+		fretPositions = append(fretPositions, "3NPS "+strconv.Itoa(i))
+	}
+}
+
+func newFretboard() *fretboard {
+	f := &fretboard{
+		tuning:   tunings[0],
+		position: positionAll,
+		labels:   labelNotes,
+		root:     -1,
+	}
+	f.ExtendBaseWidget(f)
+	return f
+}
+
+func (f *fretboard) setScale(notes []string, intervals []int) {
+	f.scaleNotes = notes
+	f.intervals = intervals
+	f.Refresh()
+}
+
+// setChord highlights the tones of c. A nil chord clears the highlight.
+func (f *fretboard) setChord(c *chord) {
+	f.chord = make(map[int]bool)
+	f.root = -1
+	if c != nil && len(c.notes) > 0 {
+		for _, n := range c.notes {
+			f.chord[noteIndexes[n]] = true
+		}
+		f.root = noteIndexes[c.notes[0]]
+	}
+	f.Refresh()
+}
+
+func (f *fretboard) setTuning(name string) {
+	for _, t := range tunings {
+		if t.name == name {
+			f.tuning = t
+		}
+	}
+	f.Refresh()
+}
+
+func (f *fretboard) setPosition(position string) {
+	f.position = position
+	f.Refresh()
+}
+
+func (f *fretboard) setLabels(labels string) {
+	f.labels = labels
+	f.Refresh()
+}
+
+// fretboardNotes finds every note of the scale on every string from the open string up to the given fret.
+func fretboardNotes(t tuning, scaleNotes []string, frets int) []fretNote {
+	degrees := make(map[int]int)
+	for i, n := range scaleNotes {
+		degrees[noteIndexes[n]] = i
+	}
+
+	var notes []fretNote
+	for s, open := range t.strings {
+		for fret := 0; fret <= frets; fret++ {
+			d, ok := degrees[(open+fret)%chromaticScaleLen]
+			if !ok {
+				continue
+			}
+			notes = append(notes, fretNote{fretSpot{s, fret}, scaleNotes[d], d})
+		}
+	}
+
+	return notes
+}
+
+// majorRoot returns the index of the scale note that starts the major scale sharing the notes of the given scale
+// (for example the third degree of a minor scale), or 0 if there is none.
+func majorRoot(intervals []int) int {
+	steps := append(append([]int{}, intervals...), chromaticScaleLen-sum(intervals))
+	for i := range steps {
+		match := true
+		for j, m := range majorIntervals {
+			if steps[(i+j)%len(steps)] != m {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+
+	return 0
+}
+
+func sum(values []int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+// cagedSpots returns the frets covered by the named CAGED shape of the given scale on every octave of the neck.
+// Shapes are taken from the major scale sharing the notes of the scale, so A minor uses the shapes of C major.
+func cagedSpots(t tuning, scaleNotes []string, intervals []int, frets int, name string) map[fretSpot]bool {
+	var shape cagedShape
+	for _, s := range cagedShapes {
+		if s.name == name {
+			shape = s
+		}
+	}
+
+	root := noteIndexes[scaleNotes[majorRoot(intervals)]]
+	rootFret := (root - t.strings[0]%chromaticScaleLen + chromaticScaleLen) % chromaticScaleLen
+
+	spots := make(map[fretSpot]bool)
+	for octave := -chromaticScaleLen; octave <= frets; octave += chromaticScaleLen {
+		for fret := rootFret + shape.from + octave; fret <= rootFret+shape.to+octave; fret++ {
+			if fret < 0 || fret > frets {
+				continue
+			}
+			for s := range t.strings {
+				spots[fretSpot{s, fret}] = true
+			}
+		}
+	}
+
+	return spots
+}
+
+// threeNotesPerStringSpots returns the frets of the three-notes-per-string pattern that starts on the given scale
+// degree (0 based) of the lowest string, and its repeat an octave higher when it fits on the neck.
+func threeNotesPerStringSpots(t tuning, scaleNotes []string, frets int, degree int) map[fretSpot]bool {
+	spots := make(map[fretSpot]bool)
+	if len(scaleNotes) == 0 {
+		return spots
+	}
+
+	low := t.strings[0]
+	start := (noteIndexes[scaleNotes[degree%len(scaleNotes)]] - low%chromaticScaleLen + chromaticScaleLen) %
+		chromaticScaleLen
+	pitch := low + start
+	d := degree
+	for s, open := range t.strings {
+		for i := 0; i < 3; i++ {
+			fret := pitch - open
+			for _, f := range []int{fret, fret + chromaticScaleLen} {
+				if f >= 0 && f <= frets {
+					spots[fretSpot{s, f}] = true
+				}
+			}
+			next := noteIndexes[scaleNotes[(d+1)%len(scaleNotes)]]
+			pitch += (next - pitch%chromaticScaleLen + chromaticScaleLen) % chromaticScaleLen
+			d++
+		}
+	}
+
+	return spots
+}
+
+// chordFrets returns the fret played on each string, or mutedString, for the lowest shape of the chord that covers at
+// most chordSpan frets, with open strings only in the first position. The lowest string played sounds the root, no
+// string is muted between played strings, and every tone is played except perhaps the fifth of a chord of four or more
+// tones.
+func chordFrets(t tuning, notes []string) ([]int, bool) {
+	if len(notes) == 0 {
+		return nil, false
+	}
+	tones := make(map[int]bool)
+	for _, n := range notes {
+		tones[notePitchClass(n)] = true
+	}
+	root := notePitchClass(notes[0])
+	required := notes
+	if len(notes) >= 4 {
+		required = append(append([]string{}, notes[:2]...), notes[3:]...)
+	}
+
+	for start := 1; start <= maxChordFret; start++ {
+		frets := make([]int, len(t.strings))
+		played := make(map[int]bool)
+		rooted, gap, ok := false, false, true
+		for s, open := range t.strings {
+			frets[s] = mutedString
+			candidates := make([]int, 0, chordSpan+1)
+			if start == 1 {
+				candidates = append(candidates, 0)
+			}
+			for f := start; f < start+chordSpan; f++ {
+				candidates = append(candidates, f)
+			}
+			for _, f := range candidates {
+				pc := pitchClass(open + f)
+				if rooted && tones[pc] || !rooted && pc == root {
+					frets[s] = f
+					played[pc] = true
+					break
+				}
+			}
+			switch {
+			case frets[s] == mutedString && rooted:
+				gap = true
+			case frets[s] != mutedString && gap:
+				ok = false
+			case frets[s] != mutedString:
+				rooted = true
+			}
+		}
+		for _, n := range required {
+			ok = ok && played[notePitchClass(n)]
+		}
+		if ok {
+			return frets, true
+		}
+	}
+
+	return nil, false
+}
+
+// visibleNotes returns the scale notes on the neck filtered by the selected position.
+func (f *fretboard) visibleNotes() []fretNote {
+	if len(f.scaleNotes) == 0 {
+		return nil
+	}
+
+	notes := fretboardNotes(f.tuning, f.scaleNotes, fretboardFrets)
+
+	var spots map[fretSpot]bool
+	for i, p := range fretPositions {
+		switch {
+		case p != f.position || p == positionAll:
+			continue
+		case i <= len(cagedShapes):
+			spots = cagedSpots(f.tuning, f.scaleNotes, f.intervals, fretboardFrets, cagedShapes[i-1].name)
+		default:
+			spots = threeNotesPerStringSpots(f.tuning, f.scaleNotes, fretboardFrets, i-len(cagedShapes)-1)
+		}
+	}
+	if spots == nil {
+		return notes
+	}
+
+	var filtered []fretNote
+	for _, n := range notes {
+		if spots[n.fretSpot] {
+			filtered = append(filtered, n)
+		}
+	}
+
+	return filtered
+}
+
+func (f *fretboard) CreateRenderer() fyne.WidgetRenderer {
+	r := &fretboardRenderer{
+		f:          f,
+		background: canvas.NewRectangle(color.NRGBA{R: 0x5d, G: 0x40, B: 0x37, A: 0xff}),
+		nut:        canvas.NewLine(color.NRGBA{R: 0xee, G: 0xee, B: 0xdd, A: 0xff}),
+	}
+	r.nut.StrokeWidth = 4
+	for i := 1; i <= fretboardFrets; i++ {
+		fret := canvas.NewLine(color.NRGBA{R: 0xbd, G: 0xbd, B: 0xbd, A: 0xff})
+		fret.StrokeWidth = 2
+		r.frets = append(r.frets, fret)
+	}
+	for _, m := range fretMarkers {
+		r.markers = append(r.markers, canvas.NewCircle(color.NRGBA{R: 0xee, G: 0xee, B: 0xdd, A: 0x80}))
+		if m == 12 {
+			r.markers = append(r.markers, canvas.NewCircle(color.NRGBA{R: 0xee, G: 0xee, B: 0xdd, A: 0x80}))
+		}
+	}
+	r.Refresh()
+
+	return r
+}
+
+// fretWidth returns the width of a fret, including the open string column left of the nut.
+func (r *fretboardRenderer) fretWidth(size fyne.Size) float32 {
+	return size.Width / (fretboardFrets + 1)
+}
+
+// stringHeight returns the distance between strings, drawn with the lowest string at the bottom.
+func (r *fretboardRenderer) stringHeight(size fyne.Size) float32 {
+	return size.Height / float32(len(r.strings))
+}
+
+// spotCenter returns the center of the given spot, left of the nut for open strings.
+func (r *fretboardRenderer) spotCenter(size fyne.Size, s fretSpot) fyne.Position {
+	fw := r.fretWidth(size)
+	sh := r.stringHeight(size)
+	return fyne.NewPos(fw*(float32(s.fret)+0.5), size.Height-sh*(float32(s.str)+0.5))
+}
+
+func (r *fretboardRenderer) Layout(size fyne.Size) {
+	fw := r.fretWidth(size)
+	sh := r.stringHeight(size)
+
+	r.background.Move(fyne.NewPos(fw, 0))
+	r.background.Resize(fyne.NewSize(size.Width-fw, size.Height))
+	r.nut.Position1 = fyne.NewPos(fw, 0)
+	r.nut.Position2 = fyne.NewPos(fw, size.Height)
+	for i, fret := range r.frets {
+		x := fw * float32(i+2)
+		fret.Position1 = fyne.NewPos(x, 0)
+		fret.Position2 = fyne.NewPos(x, size.Height)
+	}
+	for i, str := range r.strings {
+		y := size.Height - sh*(float32(i)+0.5)
+		str.Position1 = fyne.NewPos(fw, y)
+		str.Position2 = fyne.NewPos(size.Width, y)
+	}
+
+	markerSize := fw / 4
+	i := 0
+	for _, m := range fretMarkers {
+		ys := []float32{size.Height / 2}
+		if m == 12 {
+			ys = []float32{size.Height / 4, size.Height * 3 / 4}
+		}
+		for _, y := range ys {
+			r.markers[i].Move(fyne.NewPos(fw*(float32(m)+0.5)-markerSize/2, y-markerSize/2))
+			r.markers[i].Resize(fyne.NewSize(markerSize, markerSize))
+			i++
+		}
+	}
+
+	dotSize := fyne.Min(fw, sh) * 0.8
+	for i, n := range r.notes {
+		c := r.spotCenter(size, n.fretSpot)
+		r.dots[i].Move(fyne.NewPos(c.X-dotSize/2, c.Y-dotSize/2))
+		r.dots[i].Resize(fyne.NewSize(dotSize, dotSize))
+		r.texts[i].TextSize = dotSize / 2
+		ts := r.texts[i].MinSize()
+		r.texts[i].Move(fyne.NewPos(c.X-ts.Width/2, c.Y-ts.Height/2))
+		r.texts[i].Resize(ts)
+	}
+}
+
+func (r *fretboardRenderer) MinSize() fyne.Size {
+	return fyne.NewSize((fretboardFrets+1)*theme.IconInlineSize()*1.5, float32(len(r.f.tuning.strings))*
+		theme.IconInlineSize()*1.5)
+}
+
+func (r *fretboardRenderer) Refresh() {
+	f := r.f
+
+	r.strings = nil
+	for range f.tuning.strings {
+		str := canvas.NewLine(color.NRGBA{R: 0xe0, G: 0xe0, B: 0xe0, A: 0xff})
+		str.StrokeWidth = 1.5
+		r.strings = append(r.strings, str)
+	}
+
+	r.notes = f.visibleNotes()
+	r.dots = make([]*canvas.Circle, len(r.notes))
+	r.texts = make([]*canvas.Text, len(r.notes))
+	for i, n := range r.notes {
+		pc := noteIndexes[n.note]
+		fill := color.Color(scaleHighlightColor)
+		switch {
+		case pc == f.root:
+			fill = rootHighlightColor
+		case f.chord[pc]:
+			fill = chordHighlightColor
+		}
+		r.dots[i] = canvas.NewCircle(fill)
+
+		label := n.note
+		if f.labels == labelDegrees {
+			label = strconv.Itoa(n.degree + 1)
+		}
+		r.texts[i] = canvas.NewText(label, color.Black)
+	}
+
+	r.Layout(f.Size())
+	canvas.Refresh(f)
+}
+
+func (r *fretboardRenderer) Objects() []fyne.CanvasObject {
+	objects := []fyne.CanvasObject{r.background}
+	for _, m := range r.markers {
+		objects = append(objects, m)
+	}
+	objects = append(objects, r.nut)
+	for _, fret := range r.frets {
+		objects = append(objects, fret)
+	}
+	for _, str := range r.strings {
+		objects = append(objects, str)
+	}
+	for i := range r.notes {
+		objects = append(objects, r.dots[i], r.texts[i])
+	}
+
+	return objects
+}
+
+func (r *fretboardRenderer) Destroy() {}
+
+// buildFretboard returns the fretboard together with its tuning, position and label selectors.
+func (m *model) buildFretboard() fyne.CanvasObject {
+	m.fretboard = newFretboard()
+
+	var tuningNames []string
+	for _, t := range tunings {
+		tuningNames = append(tuningNames, t.name)
+	}
+	tuningSelector := widget.NewSelect(tuningNames, m.fretboard.setTuning)
+	tuningSelector.SetSelectedIndex(0)
+
+	positionSelector := widget.NewSelect(fretPositions, m.fretboard.setPosition)
+	positionSelector.SetSelectedIndex(0)
+
+	labelSelector := widget.NewRadioGroup(fretboardLabels, m.fretboard.setLabels)
+	labelSelector.Horizontal = true
+	labelSelector.SetSelected(labelNotes)
+
+	return container.NewBorder(
+		container.NewHBox(
+			widget.NewLabel("Tuning"),
+			tuningSelector,
+			layout.NewSpacer(),
+			widget.NewLabel("Position"),
+			positionSelector,
+			layout.NewSpacer(),
+			widget.NewLabel("Labels"),
+			labelSelector,
+		),
+		nil, nil, nil,
+		m.fretboard,
+	)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMajorRoot(t *testing.T) {
+	assert.Equal(t, 0, majorRoot(scaleIntervals["Major"]))
+	assert.Equal(t, 2, majorRoot(scaleIntervals["Minor"]))
+}
+
+func TestFretboardNotes(t *testing.T) {
+	notes := fretboardNotes(tunings[0], enumerateScale("C", scaleIntervals["Major"]), 12)
+
+	// Each string has 7 scale notes in an octave, plus the octave of the open string (all open strings of a guitar
+	// are in C major).
+	assert.Equal(t, 6*8, len(notes))
+	for _, n := range notes {
+		if n.str == 0 && n.fret == 8 {
+			assert.Equal(t, "C", n.note)
+			assert.Equal(t, 0, n.degree)
+		}
+	}
+}
+
+func spotFrets(spots map[fretSpot]bool, str int) []int {
+	var frets []int
+	for s := range spots {
+		if s.str == str {
+			frets = append(frets, s.fret)
+		}
+	}
+	sort.Ints(frets)
+	return frets
+}
+
+func TestCagedSpots(t *testing.T) {
+	tests := []struct {
+		key   string
+		scale string
+		shape string
+		frets []int
+	}{
+		{"G", "Major", "E", []int{2, 3, 4, 5, 14, 15, 16, 17}},
+		{"G", "Major", "G", []int{0, 1, 2, 3, 12, 13, 14, 15}},
+		{"C", "Major", "C", []int{0, 1, 2, 3, 12, 13, 14, 15}},
+		{"E", "Minor", "E", []int{2, 3, 4, 5, 14, 15, 16, 17}},
+	}
+
+	for _, e := range tests {
+		spots := cagedSpots(tunings[0], enumerateScale(e.key, scaleIntervals[e.scale]), scaleIntervals[e.scale], 17,
+			e.shape)
+		assert.Equal(t, e.frets, spotFrets(spots, 0), "%s %s, shape %s", e.key, e.scale, e.shape)
+		assert.Equal(t, e.frets, spotFrets(spots, 5), "%s %s, shape %s", e.key, e.scale, e.shape)
+	}
+}
+
+func TestThreeNotesPerStringSpots(t *testing.T) {
+	spots := threeNotesPerStringSpots(tunings[0], enumerateScale("G", scaleIntervals["Major"]), 12, 0)
+
+	expected := [][]int{
+		{3, 5, 7},
+		{3, 5, 7},
+		{4, 5, 7},
+		{4, 5, 7},
+		{5, 7, 8},
+		{5, 7, 8},
+	}
+	for str, frets := range expected {
+		assert.Equal(t, frets, spotFrets(spots, str), "string %d", str)
+	}
+}
+
+func TestChordFrets(t *testing.T) {
+	tests := []struct {
+		notes []string
+		frets []int
+	}{
+		{[]string{"C", "E", "G"}, []int{-1, 3, 2, 0, 1, 0}},
+		{[]string{"G", "B", "D"}, []int{3, 2, 0, 0, 0, 3}},
+		{[]string{"E", "G", "B"}, []int{0, 2, 2, 0, 0, 0}},
+		{[]string{"D", "F♯", "A"}, []int{-1, -1, 0, 2, 3, 2}},
+		{[]string{"E♭", "G", "B♭"}, []int{-1, 6, 5, 3, 4, 3}},
+		{[]string{"A", "C♯", "E", "G"}, []int{-1, 0, 2, 0, 2, 0}},
+	}
+
+	for _, e := range tests {
+		frets, ok := chordFrets(tunings[0], e.notes)
+		assert.True(t, ok, "%v", e.notes)
+		assert.Equal(t, e.frets, frets, "%v", e.notes)
+	}
+
+	_, ok := chordFrets(tunings[0], nil)
+	assert.False(t, ok)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// glyphOversample is the number of pixels rasterized for each unit of canvas size, so that glyphs stay sharp on high
+// density displays.
+const glyphOversample = 2
+
+type glyphKey struct {
+	r      rune
+	height int
+}
+
+var (
+	musicFont     *sfnt.Font
+	musicFontErr  error
+	musicFontOnce sync.Once
+
+	glyphMasks   = make(map[glyphKey]*image.Alpha)
+	glyphMasksMu sync.Mutex
+)
+
+// glyphMask rasterizes the outline of r from the bundled music font, scaled so that the outline is height pixels
+// tall. Fyne's text rendering only reaches the basic multilingual plane, which leaves out symbols such as the clefs.
+func glyphMask(r rune, height int) (*image.Alpha, error) {
+	musicFontOnce.Do(func() {
+		musicFont, musicFontErr = sfnt.Parse(resourceNotoSansRegularMusicTtf.StaticContent)
+	})
+	if musicFontErr != nil {
+		return nil, musicFontErr
+	}
+
+	glyphMasksMu.Lock()
+	defer glyphMasksMu.Unlock()
+	key := glyphKey{r, height}
+	if mask, ok := glyphMasks[key]; ok {
+		return mask, nil
+	}
+
+	var buf sfnt.Buffer
+	index, err := musicFont.GlyphIndex(&buf, r)
+	if err != nil {
+		return nil, err
+	}
+	ppem := fixed.Int26_6(musicFont.UnitsPerEm()) << 6
+	segments, err := musicFont.LoadGlyph(&buf, index, ppem, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := segments.Bounds()
+	unitHeight := float32(bounds.Max.Y-bounds.Min.Y) / 64
+	if unitHeight <= 0 {
+		unitHeight = 1
+	}
+	scale := float32(height) / unitHeight
+	width := int(math.Ceil(float64(float32(bounds.Max.X-bounds.Min.X) / 64 * scale)))
+	if width < 1 {
+		width = 1
+	}
+
+	point := func(p fixed.Point26_6) (float32, float32) {
+		return float32(p.X-bounds.Min.X) / 64 * scale, float32(p.Y-bounds.Min.Y) / 64 * scale
+	}
+	z := vector.NewRasterizer(width, height)
+	for _, s := range segments {
+		switch s.Op {
+		case sfnt.SegmentOpMoveTo:
+			z.MoveTo(point(s.Args[0]))
+		case sfnt.SegmentOpLineTo:
+			z.LineTo(point(s.Args[0]))
+		case sfnt.SegmentOpQuadTo:
+			x1, y1 := point(s.Args[0])
+			x2, y2 := point(s.Args[1])
+			z.QuadTo(x1, y1, x2, y2)
+		case sfnt.SegmentOpCubeTo:
+			x1, y1 := point(s.Args[0])
+			x2, y2 := point(s.Args[1])
+			x3, y3 := point(s.Args[2])
+			z.CubeTo(x1, y1, x2, y2, x3, y3)
+		}
+	}
+	mask := image.NewAlpha(image.Rect(0, 0, width, height))
+	z.Draw(mask, mask.Bounds(), image.Opaque, image.Point{})
+	glyphMasks[key] = mask
+
+	return mask, nil
+}
+
+// glyphImage returns r drawn in the given color, scaled so that its outline is height pixels tall.
+func glyphImage(r rune, height int, c color.Color) (image.Image, error) {
+	mask, err := glyphMask(r, height)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewNRGBA(mask.Bounds())
+	draw.DrawMask(img, img.Bounds(), image.NewUniform(c), image.Point{}, mask, image.Point{}, draw.Over)
+	return img, nil
+}
+
+// newGlyph returns a canvas image of the symbol r whose outline is height units tall, centered horizontally on x and
+// placed so that the point anchor (0 is the top of the outline, 1 the bottom) lies on y.
+func newGlyph(r rune, height float32, anchor float32, x, y float32, c color.Color) fyne.CanvasObject {
+	img, err := glyphImage(r, int(height*glyphOversample), c)
+	if err != nil {
+		fyne.LogError("Unable to draw music symbol", err)
+		return canvas.NewRectangle(color.Transparent)
+	}
+
+	size := img.Bounds().Size()
+	width := float32(size.X) / glyphOversample
+	g := canvas.NewImageFromImage(img)
+	g.FillMode = canvas.ImageFillStretch
+	g.Move(fyne.NewPos(x-width/2, y-anchor*height))
+	g.Resize(fyne.NewSize(width, height))
+
+	return g
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const imageWindow = "Window"
+
+var imageScales = []string{"1", "1.5", "2", "3"}
+
+// imageItems returns what can be exported as an image: the whole window or one of the chord sections.
+func (m *model) imageItems() []string {
+	items := []string{imageWindow}
+	for _, sec := range m.chordSections() {
+		items = append(items, sec.title)
+	}
+	return items
+}
+
+// snapshotModel returns a model of the same key, scale, sound and progression as m, whose view can be laid out and
+// rendered apart from the window.
+func (m *model) snapshotModel() *model {
+	return &model{
+		key:            m.key,
+		scale:          m.scale,
+		scaleNotes:     m.scaleNotes,
+		scaleIntervals: m.scaleIntervals,
+		synth:          m.synth,
+		progression:    m.progression,
+	}
+}
+
+// imageContent builds the view of the item to render: the window content, showing the tab that is selected, or the
+// card of a chord section with all of its chords in one row. Building the window selects the first key and scale, so
+// they are selected again.
+func (m *model) imageContent(item string) fyne.CanvasObject {
+	if item == imageWindow {
+		s := m.snapshotModel()
+		content := s.buildUI()
+		s.scaleSelector.SetSelected(m.scale)
+		s.keySelector.SetSelected(m.key)
+		s.refreshProgression()
+		if m.tabs != nil {
+			s.tabs.SelectIndex(m.tabs.SelectedIndex())
+		}
+		return content
+	}
+
+	for _, sec := range m.chordSections() {
+		if sec.title == item {
+			grid := container.NewGridWithColumns(maxInt(len(sec.chords), 1))
+			m.fillChordGrid(sec.chords, grid)
+			return widget.NewCard("", sec.title, grid)
+		}
+	}
+	return widget.NewLabel("")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// showImageExport asks what to render and at what scale, then asks where to save the PNG image.
+func (m *model) showImageExport() {
+	itemSelector := widget.NewSelect(m.imageItems(), nil)
+	itemSelector.SetSelected(imageWindow)
+	scaleSelector := widget.NewSelect(imageScales, nil)
+	scaleSelector.SetSelected("2")
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Export", itemSelector),
+		widget.NewFormItem("Scale", scaleSelector),
+	}
+	dialog.ShowForm("Export Image", "Export", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		item := itemSelector.Selected
+		scale, _ := strconv.ParseFloat(scaleSelector.Selected, 32)
+
+		m.saveFile(fmt.Sprintf("%s %s %s.png", m.key, m.scale, item), ".png", func(w io.Writer) error {
+			return m.writeImage(w, item, float32(scale))
+		})
+	}, m.window)
+}
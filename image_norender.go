@@ -0,0 +1,22 @@
+//go:build !images
+
+package main
+
+import (
+	"errors"
+	"io"
+)
+
+// imageExport reports whether the build can export PNG images. Rendering them needs Fyne's software renderer, which
+// links its test driver into the binary, so release builds leave it out; build with -tags images to export them.
+const imageExport = false
+
+var errNoImageExport = errors.New("PNG images are only exported by builds with -tags images")
+
+func (m *model) writeImage(io.Writer, string, float32) error {
+	return errNoImageExport
+}
+
+func writeImages(string, string, float32) error {
+	return errNoImageExport
+}
@@ -0,0 +1,14 @@
+//go:build !images
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteImagesUnsupported(t *testing.T) {
+	dir := t.TempDir()
+	assert.ErrorIs(t, writeImages(dir, imageWindow, 1), errNoImageExport)
+}
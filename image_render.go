@@ -0,0 +1,74 @@
+//go:build images
+
+package main
+
+import (
+	"fmt"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/software"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/theme"
+)
+
+// imageExport reports whether the build can export PNG images.
+const imageExport = true
+
+// renderPNG renders the content with the software renderer on a canvas of the given size, or of the minimum size of
+// the content if that is larger, and writes it as PNG. The scale multiplies the size of the image.
+func renderPNG(w io.Writer, content fyne.CanvasObject, size fyne.Size, scale float32) error {
+	c := software.NewCanvas()
+	c.SetScale(scale)
+	c.SetContent(content)
+	c.Resize(size.Max(content.MinSize().Add(fyne.NewSize(theme.Padding()*2, theme.Padding()*2))))
+
+	return png.Encode(w, c.Capture())
+}
+
+// writeImage renders the item at the scale and writes it as PNG. The window is rendered at its current size.
+func (m *model) writeImage(w io.Writer, item string, scale float32) error {
+	var size fyne.Size
+	if item == imageWindow && m.window != nil {
+		size = m.window.Canvas().Size()
+	}
+	return renderPNG(w, m.imageContent(item), size, scale)
+}
+
+// writeImages renders the item of every key and scale without a display and writes them as PNG files named after the
+// key and scale to the directory.
+func writeImages(dir, item string, scale float32) error {
+	a := test.NewApp()
+	defer a.Quit()
+	a.Settings().SetTheme(&myTheme{})
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, key := range keyNames {
+		for _, s := range scaleNames {
+			m := model{key: key, scale: s, scaleIntervals: scaleIntervals[s], synth: newSynth()}
+			m.scaleNotes = enumerateScale(m.key, m.scaleIntervals)
+			if !contains(m.imageItems(), item) {
+				return fmt.Errorf("unknown image %q", item)
+			}
+
+			f, err := os.Create(filepath.Join(dir, fmt.Sprintf("%s %s.png", key, s)))
+			if err != nil {
+				return err
+			}
+			if err := m.writeImage(f, item, scale); err != nil {
+				_ = f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+//go:build images
+
+package main
+
+import (
+	"bytes"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImageContent(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	m := sheetModel("E♭", "Minor")
+	assert.Equal(t, []string{imageWindow, "Triads", "Sevenths", "Secondary Dominants", "Secondary Lead Tones",
+		"Tritone Substitution"}, m.imageItems())
+
+	card := m.imageContent("Sevenths").(*widget.Card)
+	assert.Equal(t, "Sevenths", card.Subtitle)
+
+	m.imageContent(imageWindow)
+	assert.Equal(t, "E♭", m.key, "the window is built apart from the model")
+}
+
+func TestWriteImage(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	m := sheetModel("A", "Major")
+	var small, large bytes.Buffer
+	assert.NoError(t, m.writeImage(&small, "Triads", 1))
+	assert.NoError(t, m.writeImage(&large, "Triads", 2))
+
+	one, err := png.Decode(&small)
+	assert.NoError(t, err)
+	two, err := png.Decode(&large)
+	assert.NoError(t, err)
+	assert.InDelta(t, 2*one.Bounds().Dx(), two.Bounds().Dx(), 2)
+	assert.InDelta(t, 2*one.Bounds().Dy(), two.Bounds().Dy(), 2)
+}
+
+func TestWriteImages(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, writeImages(dir, "Tritone Substitution", 0.5))
+	files, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, len(keyNames)*len(scaleNames))
+	_, err = os.Stat(filepath.Join(dir, "F♯ Minor.png"))
+	assert.NoError(t, err)
+
+	assert.EqualError(t, writeImages(dir, "Ninths", 1), `unknown image "Ninths"`)
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	// The jazz piano voicings, besides voicingClose and voicingDrop2.
+	voicingShell     = "Shell"
+	voicingRootlessA = "Rootless A"
+	voicingRootlessB = "Rootless B"
+	voicingDrop3     = "Drop 3"
+	voicingQuartal   = "Quartal"
+	voicingUpper     = "Upper Structure"
+
+	jazzBass = 43 // lowest root of shell voicings: G2
+	jazzLow  = 50 // lowest bottom note of the other voicings: D3
+)
+
+// jazzChord is a chord as a jazz pianist reads it, with its tones and tensions spelled from the root. Triads are read
+// as sixth chords when their fifth is perfect, as half-diminished sevenths when it is diminished and as major sevenths
+// when it is augmented.
+type jazzChord struct {
+	root, third, fifth, seventh string
+	ninth, eleventh, thirteenth string // the eleventh is sharp over a major third
+
+	majorThird bool
+	fifthSize  int // semitones from the root
+	dominant   bool
+}
+
+var jazzVoicings = []string{
+	voicingClose, voicingShell, voicingRootlessA, voicingRootlessB, voicingDrop2, voicingDrop3, voicingQuartal,
+	voicingUpper,
+}
+
+// readJazzChord reads the chord as a jazz chord, or returns false if it has no third, such as a suspended chord.
+func readJazzChord(c chord) (jazzChord, bool) {
+	tones := chordTones(c)
+	if len(tones) < 3 {
+		return jazzChord{}, false
+	}
+	third, fifth := intervalAbove(tones, 1), intervalAbove(tones, 2)
+	if (third != 3 && third != 4) || fifth < 6 || fifth > 8 {
+		return jazzChord{}, false
+	}
+
+	j := jazzChord{root: tones[0], third: tones[1], fifth: tones[2], majorThird: third == 4, fifthSize: fifth}
+	if len(tones) > 3 {
+		j.seventh = tones[3]
+	} else {
+		seventh := map[int]int{6: 10, 7: 9, 8: 11}[fifth]
+		degree := 6
+		if seventh == 9 {
+			degree = 5
+		}
+		j.seventh = spellChord(j.root, chordQuality{intervals: []int{seventh}, degrees: []int{degree}})[0]
+	}
+	j.dominant = j.majorThird && fifth == 7 && intervalAbove([]string{j.root, j.seventh}, 1) == 10
+
+	eleventh := 5
+	if j.majorThird {
+		eleventh = 6
+	}
+	tensions := spellChord(j.root, chordQuality{intervals: []int{2, eleventh, 9}, degrees: []int{1, 3, 5}})
+	j.ninth, j.eleventh, j.thirteenth = tensions[0], tensions[1], tensions[2]
+	return j, true
+}
+
+// jazzVoicing voices the chord in the style, or returns false if the style does not suit the chord:
+//
+//   - close stacks the chord tones upwards from the root in chordOctave
+//   - shell plays the root, third and seventh
+//   - rootless A plays the third, fifth, seventh and ninth, and rootless B the seventh, ninth, third and fifth, with
+//     the thirteenth in place of the fifth of dominant chords
+//   - drop 2 and drop 3 lower the second and third highest tones of the close seventh chord by an octave
+//   - quartal stacks fourths from the root of minor chords and from the third of major chords, or from the third over
+//     the seventh of dominant chords
+//   - upper structure plays a triad on the ninth over the third and seventh of a seventh chord; triads are left out,
+//     as the sixth read as their seventh is the thirteenth of the upper triad
+//
+// Voicings other than close are placed so that the root of a shell is from jazzBass and the bottom note of the others
+// from jazzLow.
+func jazzVoicing(c chord, style string) ([]pitch, bool) {
+	if style == voicingClose {
+		return voiceNotes(c.notes, chordOctave), true
+	}
+	j, ok := readJazzChord(c)
+	if !ok {
+		return nil, false
+	}
+
+	fifth := j.fifth
+	if j.dominant {
+		fifth = j.thirteenth
+	}
+	var pitches []pitch
+	low := jazzLow
+	switch style {
+	case voicingShell:
+		pitches = voiceNotes([]string{j.root, j.third, j.seventh}, 0)
+		low = jazzBass
+	case voicingRootlessA:
+		pitches = voiceNotes([]string{j.third, fifth, j.seventh, j.ninth}, 0)
+	case voicingRootlessB:
+		pitches = voiceNotes([]string{j.seventh, j.ninth, j.third, fifth}, 0)
+	case voicingDrop2:
+		pitches = voice([]string{j.root, j.third, j.fifth, j.seventh}, 1, voicingDrop2)
+	case voicingDrop3:
+		pitches = voiceNotes([]string{j.root, j.third, j.fifth, j.seventh}, 1)
+		pitches[1].octave--
+		pitches[0], pitches[1] = pitches[1], pitches[0]
+	case voicingQuartal:
+		switch {
+		case j.dominant:
+			pitches = voiceNotes([]string{j.seventh, j.third, j.thirteenth, j.ninth}, 0)
+		case j.majorThird && j.fifthSize == 7:
+			pitches = voiceNotes([]string{j.third, j.thirteenth, j.ninth, j.fifth}, 0)
+		case !j.majorThird && intervalAbove([]string{j.root, j.seventh}, 1) == 10:
+			pitches = voiceNotes([]string{j.root, j.eleventh, j.seventh, j.third}, 0)
+		default:
+			return nil, false
+		}
+	case voicingUpper:
+		if j.fifthSize != 7 || j.seventh == j.thirteenth {
+			return nil, false
+		}
+		pitches = voiceNotes([]string{j.third, j.seventh, j.ninth, j.eleventh, j.thirteenth}, 0)
+	default:
+		return nil, false
+	}
+
+	shift := (low - pitches[0].midi() + chromaticScaleLen - 1) / chromaticScaleLen
+	for i := range pitches {
+		pitches[i].octave += shift
+	}
+	return pitches, true
+}
+
+// voiceChord returns the pitches of the chord in the current voicing, in close position if the voicing does not suit
+// the chord.
+func (m *model) voiceChord(c chord) []pitch {
+	if pitches, ok := jazzVoicing(c, m.voicing); ok {
+		return pitches
+	}
+	return voiceNotes(c.notes, chordOctave)
+}
+
+// describeVoicing names the pitches of the chord in the current voicing, such as "G7 Rootless A: B3 E4 F4 A4".
+func (m *model) describeVoicing(c chord) string {
+	if m.voicing == voicingClose || m.voicing == "" {
+		return ""
+	}
+	if _, ok := jazzVoicing(c, m.voicing); !ok {
+		return fmt.Sprintf("No %s voicing for %s", strings.ToLower(m.voicing), c.name)
+	}
+	var names []string
+	for _, p := range m.voiceChord(c) {
+		names = append(names, p.String())
+	}
+	return fmt.Sprintf("%s %s: %s", c.name, m.voicing, strings.Join(names, " "))
+}
+
+// playVoicedChord plays the chord in the current voicing.
+func (m *model) playVoicedChord(c chord) {
+	m.play(m.synth.chordNotes(midiNotes(m.voiceChord(c))))
+}
+
+// selectVoicing makes style the voicing of chords and shows the selected chord in it.
+func (m *model) selectVoicing(style string) {
+	m.viewMu.Lock()
+	m.voicing = style
+	m.viewMu.Unlock()
+	m.showChord(m.selected)
+}
+
+// buildVoicingControls returns the choice of how chords are voiced on the keyboard and played when tapped, with the
+// pitches of the chord shown.
+func (m *model) buildVoicingControls() fyne.CanvasObject {
+	m.voicing = voicingClose
+	m.voicingLabel = widget.NewLabel("")
+
+	voicingSelector := widget.NewSelect(jazzVoicings, m.selectVoicing)
+	voicingSelector.SetSelected(m.voicing)
+
+	return container.NewHBox(widget.NewLabel("Voicing"), voicingSelector, m.voicingLabel)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJazzVoicing(t *testing.T) {
+	c7 := chord{name: "C7", notes: []string{"C", "E", "G", "B♭"}}
+	cM7 := chord{name: "CM7", notes: []string{"C", "E", "G", "B"}}
+	dm7 := chord{name: "Dm7", notes: []string{"D", "F", "A", "C"}}
+	c := chord{name: "C", notes: []string{"C", "E", "G"}}
+	bDim := chord{name: "B°", notes: []string{"B", "D", "F"}}
+	bDim7 := chord{name: "B°7", notes: []string{"B", "D", "F", "A♭"}}
+	sus := chord{name: "Csus4", notes: []string{"C", "F", "G"}}
+
+	tests := []struct {
+		c       chord
+		style   string
+		pitches []string
+	}{
+		{c7, voicingClose, []string{"C4", "E4", "G4", "B♭4"}},
+		{c7, voicingShell, []string{"C3", "E3", "B♭3"}},
+		{c7, voicingRootlessA, []string{"E3", "A3", "B♭3", "D4"}},
+		{c7, voicingRootlessB, []string{"B♭3", "D4", "E4", "A4"}},
+		{c7, voicingDrop2, []string{"G3", "C4", "E4", "B♭4"}},
+		{c7, voicingDrop3, []string{"E3", "C4", "G4", "B♭4"}},
+		{c7, voicingQuartal, []string{"B♭3", "E4", "A4", "D5"}},
+		{c7, voicingUpper, []string{"E3", "B♭3", "D4", "F♯4", "A4"}},
+		{cM7, voicingRootlessA, []string{"E3", "G3", "B3", "D4"}},
+		{cM7, voicingQuartal, []string{"E3", "A3", "D4", "G4"}},
+		{dm7, voicingShell, []string{"D3", "F3", "C4"}},
+		{dm7, voicingRootlessB, []string{"C4", "E4", "F4", "A4"}},
+		{dm7, voicingQuartal, []string{"D3", "G3", "C4", "F4"}},
+		{dm7, voicingUpper, []string{"F3", "C4", "E4", "G4", "B4"}},
+		{c, voicingShell, []string{"C3", "E3", "A3"}},
+		{c, voicingRootlessA, []string{"E3", "G3", "A3", "D4"}},
+		{c, voicingUpper, nil},
+		{chord{name: "Dm", notes: []string{"D", "F", "A"}}, voicingUpper, nil},
+		{bDim, voicingShell, []string{"B2", "D3", "A3"}},
+		{bDim, voicingQuartal, []string{"B3", "E4", "A4", "D5"}},
+		{bDim, voicingUpper, nil},
+		{bDim7, voicingRootlessA, []string{"D3", "F3", "A♭3", "C♯4"}},
+		{bDim7, voicingQuartal, nil},
+		{sus, voicingShell, nil},
+		{sus, voicingClose, []string{"C4", "F4", "G4"}},
+	}
+
+	for _, e := range tests {
+		pitches, ok := jazzVoicing(e.c, e.style)
+		assert.Equal(t, e.pitches != nil, ok, "%s %s", e.c.name, e.style)
+		assert.Equal(t, e.pitches, pitchNames(pitches), "%s %s", e.c.name, e.style)
+	}
+}
+
+func TestReadJazzChord(t *testing.T) {
+	j, ok := readJazzChord(chord{notes: []string{"A♭", "C", "E♭"}})
+	assert.True(t, ok)
+	assert.Equal(t, jazzChord{
+		root: "A♭", third: "C", fifth: "E♭", seventh: "F", ninth: "B♭", eleventh: "D", thirteenth: "F",
+		majorThird: true, fifthSize: 7,
+	}, j)
+
+	j, ok = readJazzChord(chord{notes: []string{"F♯", "A", "C", "E"}})
+	assert.True(t, ok)
+	assert.Equal(t, "G♯", j.ninth)
+	assert.Equal(t, "B", j.eleventh)
+	assert.False(t, j.dominant)
+
+	j, _ = readJazzChord(chord{notes: []string{"D", "F♯", "A", "C", "E"}})
+	assert.True(t, j.dominant)
+	assert.Equal(t, "C", j.seventh)
+	assert.Equal(t, "G♯", j.eleventh)
+
+	_, ok = readJazzChord(chord{notes: []string{"C", "G"}})
+	assert.False(t, ok)
+}
+
+func TestShowVoicing(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	m := sheetModel("C", "Major")
+	m.keyboard = newKeyboard()
+	m.fretboard = newFretboard()
+	m.buildVoicingControls()
+	g7 := m.buildSevenths()[4]
+
+	m.selectChord(g7)
+	assert.Equal(t, map[int]bool{7: true, 11: true, 14: true, 17: true}, m.keyboard.chordKeys)
+	assert.Empty(t, m.voicingLabel.Text)
+
+	m.voicing = voicingRootlessA
+	m.showChord(&g7)
+	assert.Equal(t, "G7 Rootless A: B3 E4 F4 A4", m.voicingLabel.Text)
+	assert.Equal(t, map[int]bool{11: true, 16: true, 17: true, 21: true}, m.keyboard.chordKeys)
+	assert.Equal(t, -1, m.keyboard.rootKey, "a rootless voicing has no root to show")
+
+	m.voicing = voicingShell
+	m.showChord(&g7)
+	assert.Equal(t, map[int]bool{7: true, 11: true, 17: true}, m.keyboard.chordKeys)
+	assert.Equal(t, 7, m.keyboard.rootKey)
+
+	m.voicing = voicingDrop3
+	m.showChord(&g7)
+	assert.Equal(t, "G7 Drop 3: B3 G4 D5 F5", m.voicingLabel.Text)
+	assert.Equal(t, 3, m.keyboard.octaves)
+	assert.Equal(t, map[int]bool{11: true, 19: true, 26: true, 29: true}, m.keyboard.chordKeys,
+		"the keyboard starts on C3 and shows the voicing at its own pitches")
+	assert.Len(t, test.WidgetRenderer(m.keyboard).Objects(), 3*chromaticScaleLen)
+
+	m.voicing = voicingUpper
+	m.showChord(&g7)
+	assert.Equal(t, "G7 Upper Structure: B3 F4 A4 C♯5 E5", m.voicingLabel.Text)
+	assert.Equal(t, map[int]bool{11: true, 17: true, 21: true, 25: true, 28: true}, m.keyboard.chordKeys)
+
+	m.showChord(&chord{name: "Csus4", notes: []string{"C", "F", "G"}})
+	assert.Equal(t, "No upper structure voicing for Csus4", m.voicingLabel.Text)
+	assert.Equal(t, map[int]bool{0: true, 5: true, 7: true}, m.keyboard.chordKeys)
+	assert.Equal(t, keyboardOctaves, m.keyboard.octaves)
+
+	m.showChord(nil)
+	assert.Empty(t, m.voicingLabel.Text)
+	assert.Empty(t, m.keyboard.chordKeys)
+}
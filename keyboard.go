@@ -0,0 +1,247 @@
+package main
+
+import (
+	"image/color"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	keyboardOctaves = 2
+	keyboardKeys    = keyboardOctaves * chromaticScaleLen
+)
+
+type (
+	// keyboard is a piano keyboard of two octaves or more, starting on C, that highlights the notes of the current
+	// scale and the tones of the selected chord.
+	keyboard struct {
+		widget.BaseWidget
+
+		// mu guards the fields below and the keys of the renderer, as notes played on the MIDI input are shown from
+		// the goroutine reading it.
+		mu        sync.Mutex
+		octaves   int          // shown, keyboardOctaves unless a voicing needs more
+		scale     map[int]bool // pitch classes in the scale
+		chordKeys map[int]bool // key indexes (0 is the lowest C) sounding in the chord
+		rootKey   int          // key index of the chord root, or -1 if there is no chord
+	}
+
+	keyboardRenderer struct {
+		k       *keyboard
+		keys    []*canvas.Rectangle // indexed by key, 0 is the lowest C
+		objects []fyne.CanvasObject
+	}
+)
+
+var (
+	blackKeys = map[int]bool{1: true, 3: true, 6: true, 8: true, 10: true}
+
+	scaleHighlightColor = color.NRGBA{R: 0x90, G: 0xca, B: 0xf9, A: 0xff}
+	chordHighlightColor = color.NRGBA{R: 0x1e, G: 0x88, B: 0xe5, A: 0xff}
+	rootHighlightColor  = color.NRGBA{R: 0xe5, G: 0x39, B: 0x35, A: 0xff}
+)
+
+var _ fyne.Widget = (*keyboard)(nil)
+
+func newKeyboard() *keyboard {
+	k := &keyboard{octaves: keyboardOctaves, rootKey: -1}
+	k.ExtendBaseWidget(k)
+	return k
+}
+
+// setScale highlights the given scale notes in every octave.
+func (k *keyboard) setScale(notes []string) {
+	scale := make(map[int]bool)
+	for _, n := range notes {
+		scale[noteIndexes[n]] = true
+	}
+	k.mu.Lock()
+	k.scale = scale
+	k.mu.Unlock()
+	k.Refresh()
+}
+
+// setChord highlights the tones of c, voiced upwards from its root in the lowest octave. A nil chord clears the
+// highlight.
+func (k *keyboard) setChord(c *chord) {
+	chordKeys := make(map[int]bool)
+	rootKey := -1
+	if c != nil {
+		keys := keyboardVoicing(c.notes)
+		for _, i := range keys {
+			chordKeys[i] = true
+		}
+		if len(keys) > 0 {
+			rootKey = keys[0]
+		}
+	}
+	k.setKeys(keyboardOctaves, chordKeys, rootKey)
+}
+
+// setNotes highlights the keys of the MIDI notes, with the lowest C of the keyboard placed on the octave of the lowest
+// note and higher notes moved down by octaves until they fit. The lowest key of the root pitch class is highlighted
+// as the root; a negative root highlights none.
+func (k *keyboard) setNotes(midis []int, root int) {
+	k.placeNotes(midis, root, keyboardOctaves)
+}
+
+// setVoicing highlights the keys of the MIDI notes of a voicing at their own pitches, with the lowest C of the
+// keyboard placed on the octave of the lowest note and as many octaves as the voicing spans. The lowest key of the root
+// pitch class is highlighted as the root; a negative root highlights none.
+func (k *keyboard) setVoicing(midis []int, root int) {
+	octaves := keyboardOctaves
+	if len(midis) > 0 {
+		low, high := midis[0], midis[0]
+		for _, n := range midis {
+			low, high = minInt(low, n), maxInt(high, n)
+		}
+		octaves = maxInt(octaves, (high-(low-pitchClass(low)))/chromaticScaleLen+1)
+	}
+	k.placeNotes(midis, root, octaves)
+}
+
+// placeNotes shows the given number of octaves from the C at or below the lowest of the MIDI notes and highlights
+// their keys, moving notes above the keyboard down by octaves until they fit.
+func (k *keyboard) placeNotes(midis []int, root, octaves int) {
+	chordKeys := make(map[int]bool)
+	rootKey := -1
+	if len(midis) > 0 {
+		low := midis[0]
+		for _, n := range midis {
+			low = minInt(low, n)
+		}
+		base := low - pitchClass(low)
+		for _, n := range midis {
+			i := n - base
+			for i >= octaves*chromaticScaleLen {
+				i -= chromaticScaleLen
+			}
+			chordKeys[i] = true
+			if pitchClass(n) == root && (rootKey < 0 || i < rootKey) {
+				rootKey = i
+			}
+		}
+	}
+	k.setKeys(octaves, chordKeys, rootKey)
+}
+
+// setKeys shows the octaves with the chord keys and root key highlighted.
+func (k *keyboard) setKeys(octaves int, chordKeys map[int]bool, rootKey int) {
+	k.mu.Lock()
+	k.octaves, k.chordKeys, k.rootKey = octaves, chordKeys, rootKey
+	k.mu.Unlock()
+	k.Refresh()
+}
+
+// keyboardVoicing places each note on the first key above the previous one, starting from the lowest octave, and
+// returns the key indexes. Notes that do not fit on the keyboard are dropped.
+func keyboardVoicing(notes []string) []int {
+	var keys []int
+	last := -1
+	for _, n := range notes {
+		pc := noteIndexes[n]
+		i := pc
+		for i <= last {
+			i += chromaticScaleLen
+		}
+		if i >= keyboardKeys {
+			break
+		}
+		keys = append(keys, i)
+		last = i
+	}
+
+	return keys
+}
+
+func (k *keyboard) CreateRenderer() fyne.WidgetRenderer {
+	r := &keyboardRenderer{k: k}
+	r.Refresh()
+
+	return r
+}
+
+// buildKeys makes the keys of the octaves shown.
+func (r *keyboardRenderer) buildKeys() {
+	r.keys = make([]*canvas.Rectangle, r.k.octaves*chromaticScaleLen)
+	r.objects = nil
+
+	// White keys are added first so that the black keys are drawn on top of them.
+	for _, black := range []bool{false, true} {
+		for i := range r.keys {
+			if blackKeys[i%chromaticScaleLen] != black {
+				continue
+			}
+			key := canvas.NewRectangle(color.White)
+			key.StrokeColor = color.Black
+			key.StrokeWidth = 1
+			r.keys[i] = key
+			r.objects = append(r.objects, key)
+		}
+	}
+	r.layout(r.k.Size())
+}
+
+func (r *keyboardRenderer) Layout(size fyne.Size) {
+	r.k.mu.Lock()
+	defer r.k.mu.Unlock()
+	r.layout(size)
+}
+
+func (r *keyboardRenderer) layout(size fyne.Size) {
+	whiteWidth := size.Width / float32(len(r.keys)/chromaticScaleLen*7)
+	blackWidth := whiteWidth * 0.6
+	blackHeight := size.Height * 0.6
+
+	white := 0
+	for i, key := range r.keys {
+		if blackKeys[i%chromaticScaleLen] {
+			key.Move(fyne.NewPos(float32(white)*whiteWidth-blackWidth/2, 0))
+			key.Resize(fyne.NewSize(blackWidth, blackHeight))
+			continue
+		}
+		key.Move(fyne.NewPos(float32(white)*whiteWidth, 0))
+		key.Resize(fyne.NewSize(whiteWidth, size.Height))
+		white++
+	}
+}
+
+func (r *keyboardRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(keyboardOctaves*7*theme.IconInlineSize()*1.5, theme.IconInlineSize()*5)
+}
+
+func (r *keyboardRenderer) Refresh() {
+	r.k.mu.Lock()
+	defer r.k.mu.Unlock()
+	if len(r.keys) != r.k.octaves*chromaticScaleLen {
+		r.buildKeys()
+	}
+	for i, key := range r.keys {
+		pc := i % chromaticScaleLen
+		switch {
+		case i == r.k.rootKey:
+			key.FillColor = rootHighlightColor
+		case r.k.chordKeys[i]:
+			key.FillColor = chordHighlightColor
+		case r.k.scale[pc]:
+			key.FillColor = scaleHighlightColor
+		case blackKeys[pc]:
+			key.FillColor = color.Black
+		default:
+			key.FillColor = color.White
+		}
+		key.Refresh()
+	}
+}
+
+func (r *keyboardRenderer) Objects() []fyne.CanvasObject {
+	r.k.mu.Lock()
+	defer r.k.mu.Unlock()
+	return r.objects
+}
+
+func (r *keyboardRenderer) Destroy() {}
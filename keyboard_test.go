@@ -0,0 +1,27 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKeyboardVoicing(t *testing.T) {
+	tests := []struct {
+		notes []string
+		keys  []int
+	}{
+		{[]string{"C", "E", "G"}, []int{0, 4, 7}},
+		{[]string{"A", "C", "E"}, []int{9, 12, 16}},
+		{[]string{"B", "D♯", "F♯", "A"}, []int{11, 15, 18, 21}},
+		{[]string{"B♭", "D", "F", "A♭"}, []int{10, 14, 17, 20}},
+		{[]string{"B", "D", "F", "A", "C", "E"}, []int{11, 14, 17, 21}},
+		{nil, nil},
+	}
+
+	for _, e := range tests {
+		k := keyboardVoicing(e.notes)
+		if !reflect.DeepEqual(e.keys, k) {
+			t.Errorf("Voicing not equal: for chord %v expected %v, got %v", e.notes, e.keys, k)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// keySignature is counted in fifths from C major: positive values are sharps and negative values are flats.
+type keySignature int
+
+var (
+	stepFifths = map[byte]int{'F': -1, 'C': 0, 'G': 1, 'D': 2, 'A': 3, 'E': 4, 'B': 5}
+
+	sharpOrder = "FCGDAEB"
+	flatOrder  = "BEADGCF"
+)
+
+// keySignatureFor returns the key signature of the key and scale. Keys such as D♯ major need more than seven sharps
+// or flats; their signature is returned anyway and reported by valid as not writable.
+func keySignatureFor(key, scale string) keySignature {
+	step, alter, _ := parseNote(key)
+	fifths := stepFifths[step] + alter*len(steps)
+	if scale == "Minor" {
+		fifths -= 3
+	}
+
+	return keySignature(fifths)
+}
+
+// valid reports whether the signature can be written with at most seven sharps or flats.
+func (k keySignature) valid() bool {
+	return k >= -7 && k <= 7
+}
+
+func (k keySignature) sharps() int {
+	if k > 0 {
+		return int(k)
+	}
+	return 0
+}
+
+func (k keySignature) flats() int {
+	if k < 0 {
+		return -int(k)
+	}
+	return 0
+}
+
+// steps returns the altered letters in the order they are written.
+func (k keySignature) steps() []byte {
+	if k >= 0 {
+		return []byte(sharpOrder[:minInt(k.sharps(), len(steps))])
+	}
+	return []byte(flatOrder[:minInt(k.flats(), len(steps))])
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// notes returns the altered notes in the order they are written, such as "F♯ C♯" for D major.
+func (k keySignature) notes() []string {
+	var notes []string
+	for _, s := range k.steps() {
+		notes = append(notes, pitch{step: s, alter: k.alter(s)}.name())
+	}
+	return notes
+}
+
+// alter returns the alteration the signature applies to the letter.
+func (k keySignature) alter(step byte) int {
+	switch {
+	case k > 0:
+		return (int(k) + len(steps) - 1 - strings.IndexByte(sharpOrder, step)) / len(steps)
+	case k < 0:
+		return -(-int(k) + len(steps) - 1 - strings.IndexByte(flatOrder, step)) / len(steps)
+	}
+	return 0
+}
+
+// needsAccidental reports whether p must be written with an accidental in a bar where the alterations in altered, by
+// diatonic position, are already in force, and records the alteration of p there. A signature that is not valid is
+// not written, so every alteration needs an accidental.
+func (k keySignature) needsAccidental(p pitch, altered map[int]int) bool {
+	expected, ok := altered[p.diatonic()]
+	if !ok && k.valid() {
+		expected = k.alter(p.step)
+	}
+	altered[p.diatonic()] = p.alter
+
+	return p.alter != expected
+}
+
+// describe summarizes the signature, such as "2 sharps: F♯ C♯".
+func (k keySignature) describe() string {
+	var count string
+	switch {
+	case k.sharps() == 1:
+		count = "1 sharp"
+	case k.sharps() > 1:
+		count = strconv.Itoa(k.sharps()) + " sharps"
+	case k.flats() == 1:
+		count = "1 flat"
+	case k.flats() > 1:
+		count = strconv.Itoa(k.flats()) + " flats"
+	default:
+		return "no sharps or flats"
+	}
+
+	return count + ": " + strings.Join(k.notes(), " ")
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeySignatureFor(t *testing.T) {
+	tests := []struct {
+		key    string
+		scale  string
+		keySig keySignature
+		valid  bool
+		notes  []string
+	}{
+		{"C", "Major", 0, true, nil},
+		{"D", "Major", 2, true, []string{"F♯", "C♯"}},
+		{"C♯", "Major", 7, true, []string{"F♯", "C♯", "G♯", "D♯", "A♯", "E♯", "B♯"}},
+		{"E♭", "Major", -3, true, []string{"B♭", "E♭", "A♭"}},
+		{"A", "Minor", 0, true, nil},
+		{"E♭", "Minor", -6, true, []string{"B♭", "E♭", "A♭", "D♭", "G♭", "C♭"}},
+		{"G#", "Minor", 5, true, []string{"F♯", "C♯", "G♯", "D♯", "A♯"}},
+		{"D♯", "Major", 9, false, []string{"F𝄪", "C𝄪", "G♯", "D♯", "A♯", "E♯", "B♯"}},
+		{"D♭", "Minor", -8, false, []string{"B𝄫", "E♭", "A♭", "D♭", "G♭", "C♭", "F♭"}},
+	}
+
+	for _, e := range tests {
+		k := keySignatureFor(e.key, e.scale)
+		assert.Equal(t, e.keySig, k, "%s %s", e.key, e.scale)
+		assert.Equal(t, e.valid, k.valid(), "%s %s", e.key, e.scale)
+		assert.Equal(t, e.notes, k.notes(), "%s %s", e.key, e.scale)
+	}
+}
+
+func TestKeySignatureDescribe(t *testing.T) {
+	assert.Equal(t, "no sharps or flats", keySignature(0).describe())
+	assert.Equal(t, "1 sharp: F♯", keySignature(1).describe())
+	assert.Equal(t, "4 flats: B♭ E♭ A♭ D♭", keySignature(-4).describe())
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const lilyPondVersion = "2.24.0"
+
+var (
+	// lilyPondAlters are the suffixes of the default (Dutch) note names, as in "cis" and "bes".
+	lilyPondAlters = map[int]string{-2: "eses", -1: "es", 0: "", 1: "is", 2: "isis"}
+
+	// lilyPondChords are the chord mode modifiers of the chord suffixes.
+	lilyPondChords = map[string]string{
+		"":      "",
+		"m":     ":m",
+		"°":     ":dim",
+		"+":     ":aug",
+		"sus2":  ":sus2",
+		"sus4":  ":sus4",
+		"7":     ":7",
+		"M7":    ":maj7",
+		"m7":    ":m7",
+		"m7♭5":  ":m7.5-",
+		"°7":    ":dim7",
+		"mM7":   ":m7+",
+		"+M7":   ":maj7.5+",
+		"7sus4": ":7sus4",
+		"6":     ":6",
+		"m6":    ":m6",
+		"add9":  ":5.9",
+		"9":     ":9",
+		"M9":    ":maj9",
+		"m9":    ":m9",
+		"5":     ":1.5",
+	}
+)
+
+// lilyPondNote returns the LilyPond name of a note without an octave, such as "fis".
+func lilyPondNote(step byte, alter int) string {
+	return strings.ToLower(string(step)) + lilyPondAlters[alter]
+}
+
+// lilyPondPitch returns the LilyPond name of p in absolute octave mode, where c is C3 and c' is middle C.
+func lilyPondPitch(p pitch) string {
+	octave := ""
+	switch {
+	case p.octave > 3:
+		octave = strings.Repeat("'", p.octave-3)
+	case p.octave < 3:
+		octave = strings.Repeat(",", 3-p.octave)
+	}
+	return lilyPondNote(p.step, p.alter) + octave
+}
+
+// lilyPondChordName returns the chord mode name of the named chord lasting a whole note, or a skip if the chord cannot
+// be named in chord mode.
+func lilyPondChordName(name string) string {
+	root, suffix, bass, ok := splitChordName(name)
+	modifier, known := lilyPondChords[suffix]
+	if !ok || !known {
+		return "s1"
+	}
+	step, alter, _ := parseNote(root)
+	text := lilyPondNote(step, alter) + "1" + modifier
+	if bass != "" {
+		step, alter, _ := parseNote(bass)
+		text += "/" + lilyPondNote(step, alter)
+	}
+
+	return text
+}
+
+// lilyPondString quotes s as a LilyPond string.
+func lilyPondString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// writeLilyPond writes the sections as LilyPond source with a score for each. The scale is written in quarter notes.
+// Chords are written a bar each, with a line of chord names above the staff and a line of their positions below.
+func (m *model) writeLilyPond(w io.Writer, sections []scoreSection) error {
+	tonic, _ := newPitch(m.key, 0)
+	key := fmt.Sprintf(`\key %s \%s`, lilyPondNote(tonic.step, tonic.alter), strings.ToLower(m.scale))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\\version %s\n\n", lilyPondString(lilyPondVersion))
+	fmt.Fprintf(&b, "\\header {\n  title = %s\n  tagline = ##f\n}\n", lilyPondString(m.key+" "+m.scale))
+
+	for _, sec := range sections {
+		var names, notes, positions []string
+		for i, p := range sec.melody {
+			note := lilyPondPitch(p)
+			if i == 0 {
+				note += "4"
+			}
+			notes = append(notes, note)
+		}
+		for _, c := range sec.chords {
+			var tones []string
+			for _, p := range voiceNotes(c.notes, scoreOctave) {
+				tones = append(tones, lilyPondPitch(p))
+			}
+			names = append(names, lilyPondChordName(c.name))
+			notes = append(notes, "<"+strings.Join(tones, " ")+">1")
+			positions = append(positions, lilyPondString(c.position)+"1")
+		}
+		if len(notes) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "\n\\score {\n  \\header {\n    piece = %s\n  }\n  <<\n", lilyPondString(sec.title))
+		if len(names) > 0 {
+			fmt.Fprintf(&b, "    \\new ChordNames \\chordmode { %s }\n", strings.Join(names, " "))
+		}
+		fmt.Fprintf(&b, "    \\new Staff { %s \\time 4/4 %s \\bar \"|.\" }\n", key, strings.Join(notes, " "))
+		if len(positions) > 0 {
+			fmt.Fprintf(&b, "    \\new Lyrics \\lyricmode { %s }\n", strings.Join(positions, " "))
+		}
+		b.WriteString("  >>\n  \\layout { }\n}\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// showLilyPondExport asks what to export and where to save it as LilyPond source.
+func (m *model) showLilyPondExport() {
+	m.showScoreExport("LilyPond", ".ly", m.writeLilyPond)
+}
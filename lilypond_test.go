@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLilyPondPitch(t *testing.T) {
+	tests := []struct {
+		p    pitch
+		name string
+	}{
+		{pitch{'C', 0, 4}, "c'"},
+		{pitch{'C', 0, 3}, "c"},
+		{pitch{'B', -1, 2}, "bes,"},
+		{pitch{'E', 1, 5}, "eis''"},
+		{pitch{'F', 2, 4}, "fisis'"},
+		{pitch{'B', -2, 1}, "beses,,"},
+	}
+
+	for _, e := range tests {
+		assert.Equal(t, e.name, lilyPondPitch(e.p))
+	}
+}
+
+func TestLilyPondChordName(t *testing.T) {
+	tests := []struct {
+		name  string
+		chord string
+	}{
+		{"C", "c1"},
+		{"F♯m7♭5", "fis1:m7.5-"},
+		{"B♭M7", "bes1:maj7"},
+		{"C♯°", "cis1:dim"},
+		{"C/E", "c1/e"},
+		{"?", "s1"},
+		{"Cwhatever", "s1"},
+	}
+
+	for _, e := range tests {
+		assert.Equal(t, e.chord, lilyPondChordName(e.name))
+	}
+}
+
+func TestWriteLilyPond(t *testing.T) {
+	m := model{key: "F♯", scale: "Major", scaleIntervals: scaleIntervals["Major"]}
+	m.scaleNotes = enumerateScale(m.key, m.scaleIntervals)
+
+	var b strings.Builder
+	assert.NoError(t, m.writeLilyPond(&b, m.scoreSections(exportScale)))
+	assert.Contains(t, b.String(), `\version "2.24.0"`)
+	assert.Contains(t, b.String(), `title = "F♯ Major"`)
+	assert.Contains(t, b.String(), `\key fis \major \time 4/4 fis'4 gis' ais' b' cis'' dis'' eis'' fis'' \bar "|."`)
+	assert.NotContains(t, b.String(), "ChordNames")
+
+	b.Reset()
+	assert.NoError(t, m.writeLilyPond(&b, m.scoreSections("Triads")))
+	assert.Contains(t, b.String(), `piece = "Triads"`)
+	assert.Contains(t, b.String(), `\new ChordNames \chordmode { fis1 gis1:m ais1:m b1 cis1 dis1:m eis1:dim }`)
+	assert.Contains(t, b.String(), `<eis' gis' b'>1 \bar "|."`)
+	assert.Contains(t, b.String(), `\new Lyrics \lyricmode { "I"1 "II"1 "III"1 "IV"1 "V"1 "VI"1 "VII"1 }`)
+}
@@ -1,12 +1,20 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"log"
+	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/widget"
 )
@@ -25,9 +33,101 @@ type (
 		scaleNotes     []string
 		scaleIntervals []int
 
-		scaleLabel    *widget.Label
-		keySelector   *widget.Select
-		scaleSelector *widget.Select
+		window fyne.Window
+		synth  synth
+		audio  audioOutput
+
+		progression []chord
+		song        *midiSong // imported MIDI file, if any
+		songName    string
+		analysis    analysis
+
+		chordPro        *chordProSong // imported ChordPro song, if any
+		chordProName    string
+		chordProKey     string
+		chordProScale   string
+		capoSuggestions []capoSuggestion
+
+		midiPorts []midiPort
+		midiIn    io.Closer
+		heldMu    sync.Mutex
+		held      map[int]bool // MIDI notes held on the MIDI input
+		midiOut   *midiOutput
+
+		// viewMu guards the key, scale, voicing, selected chord and chord grids, which the goroutine reading the MIDI
+		// input reads.
+		viewMu sync.Mutex
+
+		midiOutChannel  int
+		midiOutOctave   int
+		midiOutVelocity int
+
+		scaleLabel      *tappableLabel
+		keySelector     *widget.Select
+		scaleSelector   *widget.Select
+		keyboard        *keyboard
+		voicing         string // of chords shown on the keyboard and played when tapped
+		voicingLabel    *widget.Label
+		progressionGrid *fyne.Container
+		leadStyle       string // voicing of the progression on the voice-leading staff
+		leadStaff       *staff
+		leadIssues      *widget.Label
+		fretboard       *fretboard
+		scaleStaff      *staff
+		chordStaffs     []*staff
+		circle          *circleOfFifths
+		keySigLabel     *widget.Label
+		keySigStaff     *staff
+		relatedLabel    *widget.Label
+		tabs            *container.AppTabs
+		analysisTab     *container.TabItem
+		analysisGrid    *fyne.Container
+		analysisLabel   *widget.Label
+		segmentSelector *widget.Select
+		midiInSelector  *widget.Select
+		midiOutSelector *widget.Select
+		liveText        binding.String // description of the notes held on the MIDI input
+
+		chordProTab       *container.TabItem
+		chordProLabel     *widget.Label
+		chordProText      *widget.Label
+		transposeSelector *widget.Select
+		capoSelector      *widget.Select
+
+		ear         earTrainer
+		earQuestion *earQuestion // question being asked, if any
+		earAnswered bool         // the question being asked was answered
+		earAsked    time.Time    // when the question being asked was shown
+		earPrompt   *widget.Label
+		earLevel    *widget.Label
+		earChoices  *fyne.Container
+		earFeedback *widget.Label
+		earStats    *widget.Label
+
+		flash         *flashcardDeck
+		flashCard     *flashcard // card being asked, if any
+		flashAnswered bool       // the card being asked was answered
+		flashAsked    time.Time  // when the card being asked was shown
+		flashPrompt   *widget.Label
+		flashEntry    *widget.Entry
+		flashFeedback *widget.Label
+		flashStats    *widget.Label
+
+		quizKind     string        // kind of quiz questions asked, or quizAll
+		quizQuestion *quizQuestion // question being asked, if any
+		quizAsked    time.Time     // when the question being asked was shown
+		quizPrompt   *widget.Label
+		quizChoices  *fyne.Container
+		quizFeedback *widget.Label
+		quizStats    *widget.Label
+
+		history     []practiceResult // answers to practice questions, the latest last
+		practiceLog string           // path of the practice log, or empty to keep answers for the session only
+		historyList *widget.List
+		statsLabel  *widget.Label
+		statsCharts []*accuracyChart // by key, by chord quality and by day
+
+		selected *chord // chord tapped by the user, if any
 
 		triadGrid         *fyne.Container
 		seventhGrid       *fyne.Container
@@ -44,7 +144,7 @@ type (
 )
 
 var (
-	keyNames       = []string{"C", "C♯", "D♭", "D", "D♯", "E♭", "E", "F", "F♯", "G♭", "G", "G#", "A♭", "A", "A♯", "B♭", "B"}
+	keyNames       = []string{"C", "C♯", "D♭", "D", "D♯", "E♭", "E", "F", "F♯", "G♭", "G", "G♯", "A♭", "A", "A♯", "B♭", "B"}
 	chromaticScale = []string{"C", "D♭", "D", "E♭", "E", "F", "G♭", "G", "A♭", "A", "B♭", "B"}
 
 	noteEquivalents = [][]string{
@@ -90,7 +190,55 @@ func init() {
 	}
 }
 
+// commands are run instead of the window when their name is the first argument.
+var commands = map[string]func(args []string) error{
+	printCommand: func(args []string) error { return runPrint(args, os.Stdout) },
+	serveCommand: runServe,
+	tuiCommand:   runTUI,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if run, ok := commands[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil && !errors.Is(err, flag.ErrHelp) {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+
+	wavPath := flag.String("wav", "", "write played chords and scales to this WAV file instead of the audio device")
+	sheetPath := flag.String("sheet", "", "write the chord sheet of -key and -scale to this PDF or SVG file and exit")
+	sheetKey := flag.String("key", keyNames[0], "key of the chord sheet")
+	sheetScale := flag.String("scale", scaleNames[0], "scale of the chord sheet")
+	sheetPaper := flag.String("paper", papers[0].name, "paper size of the chord sheet: A4 or Letter")
+	keyboards := flag.Bool("keyboards", true, "draw keyboards on the chord sheet")
+	diagrams := flag.Bool("diagrams", true, "draw guitar chord diagrams on the chord sheet")
+	imageDir := flag.String("images", "",
+		"write a PNG image of every key and scale to this directory and exit, in builds with -tags images")
+	imageItem := flag.String("image", imageWindow, "what the images show: Window or the title of a chord section")
+	imageScale := flag.Float64("image-scale", 1, "scale factor of the images")
+	flag.Parse()
+
+	if *sheetPath != "" {
+		opts := sheetOptions{keyboards: *keyboards, diagrams: *diagrams}
+		paper, ok := paperNamed(*sheetPaper)
+		if !ok {
+			log.Fatalf("unknown paper size %q", *sheetPaper)
+		}
+		opts.paper = paper
+		if err := writeSheetFile(*sheetPath, *sheetKey, *sheetScale, opts); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if *imageDir != "" {
+		if err := writeImages(*imageDir, *imageItem, float32(*imageScale)); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	a := app.New()
 	a.Settings().SetTheme(&myTheme{})
 
@@ -101,9 +249,18 @@ func main() {
 		scale:          scale,
 		scaleNotes:     enumerateScale(key, scaleIntervals[scale]),
 		scaleIntervals: scaleIntervals[scale],
+		synth:          newSynth(),
+	}
+
+	audio, err := newAudioOutput(*wavPath)
+	if err != nil {
+		fyne.LogError("Unable to open audio output, playback is disabled", err)
 	}
+	m.audio = audio
 
 	w := a.NewWindow(fmt.Sprintf("Chords for Keys - v%s", fyne.CurrentApp().Metadata().Version))
+	m.window = w
+	w.SetMainMenu(m.buildMainMenu())
 	w.SetContent(m.buildUI())
 	w.ShowAndRun()
 }
@@ -134,16 +291,78 @@ func enumerateScale(note string, intervals []int) []string {
 	return notes
 }
 
-func fillChordGrid(chords []chord, grid *fyne.Container) {
+func (m *model) fillChordGrid(chords []chord, grid *fyne.Container) {
+	m.viewMu.Lock()
+	defer m.viewMu.Unlock()
 	grid.RemoveAll()
 	for _, c := range chords {
-		card := widget.NewCard(c.name, c.position, widget.NewLabel(strings.Join(c.notes, " ")))
-		grid.Add(card)
+		grid.Add(newChordCard(c, m.hoverChord, m.tapChord))
+	}
+}
+
+// hoverChord shows the hovered chord, or the selected chord when c is nil.
+func (m *model) hoverChord(c *chord) {
+	if c == nil {
+		c = m.selected
+	}
+	m.showChord(c)
+}
+
+// selectChord makes c the selected chord, or clears the selection if c is already selected.
+func (m *model) selectChord(c chord) {
+	m.viewMu.Lock()
+	if m.selected != nil && m.selected.name == c.name && m.selected.position == c.position {
+		m.selected = nil
+	} else {
+		m.selected = &c
+	}
+	m.viewMu.Unlock()
+	m.showChord(m.selected)
+}
+
+// tapChord selects and plays the chord in the current voicing.
+func (m *model) tapChord(c chord) {
+	m.selectChord(c)
+	m.playVoicedChord(c)
+}
+
+// showChord shows the chord on the keyboard, in the current voicing, and on the fretboard, which the terminal UI does
+// not have.
+func (m *model) showChord(c *chord) {
+	if m.keyboard == nil {
+		return
+	}
+	m.showOnKeyboard(c)
+	if m.voicingLabel != nil {
+		text := ""
+		if c != nil {
+			text = m.describeVoicing(*c)
+		}
+		m.voicingLabel.SetText(text)
+	}
+	m.fretboard.setChord(c)
+}
+
+// showOnKeyboard shows the chord on the keyboard in the current voicing.
+func (m *model) showOnKeyboard(c *chord) {
+	switch {
+	case c == nil || len(c.notes) == 0 || m.voicing == voicingClose:
+		m.keyboard.setChord(c)
+	default:
+		m.keyboard.setVoicing(midiNotes(m.voiceChord(*c)), notePitchClass(c.notes[0]))
 	}
 }
 
 func (m *model) buildUI() *fyne.Container {
-	m.scaleLabel = widget.NewLabel(strings.Join(m.scaleNotes, " "))
+	m.scaleLabel = newTappableLabel(strings.Join(m.scaleNotes, " "), m.playScale)
+	m.keyboard = newKeyboard()
+	fretboard := m.buildFretboard()
+	staffs := m.buildStaffs()
+	circle := m.buildCircle()
+	progression := m.buildProgression()
+	m.analysisTab = container.NewTabItem("Analysis", m.buildAnalysis())
+	m.chordProTab = container.NewTabItem("Song", m.buildChordPro())
+	practice := m.buildPractice()
 
 	m.triadGrid = container.NewGridWithColumns(7)
 	m.seventhGrid = container.NewGridWithColumns(7)
@@ -152,18 +371,34 @@ func (m *model) buildUI() *fyne.Container {
 	m.tritoneSubGrid = container.NewGridWithColumns(1)
 
 	m.keySelector = widget.NewSelect(keyNames, func(s string) {
-		m.key = s
+		m.setKey(s)
 		m.refreshUI()
 	})
 	m.keySelector.SetSelectedIndex(0)
 
 	m.scaleSelector = widget.NewSelect(scaleNames, func(s string) {
-		m.scale = s
-		m.scaleIntervals = scaleIntervals[s]
+		m.setScale(s)
 		m.refreshUI()
 	})
 	m.scaleSelector.SetSelectedIndex(0)
 
+	m.tabs = container.NewAppTabs(
+		container.NewTabItem("Chords", container.NewVBox(
+			widget.NewCard("", "Triads", m.triadGrid),
+			widget.NewCard("", "Sevenths", m.seventhGrid),
+			widget.NewCard("", "Secondary Dominants", m.secondaryDomGrid),
+			widget.NewCard("", "Secondary Lead Tones", m.secondaryLeadGrid),
+			widget.NewCard("", "Tritone Substitution", m.tritoneSubGrid),
+		)),
+		container.NewTabItem("Fretboard", fretboard),
+		container.NewTabItem("Staff", staffs),
+		container.NewTabItem("Circle of Fifths", circle),
+		container.NewTabItem("Progression", progression),
+		m.analysisTab,
+		m.chordProTab,
+		container.NewTabItem("Practice", practice),
+	)
+
 	m.refreshUI()
 
 	return container.NewPadded(
@@ -178,27 +413,51 @@ func (m *model) buildUI() *fyne.Container {
 				widget.NewLabel("Scale Notes"),
 				m.scaleLabel,
 			),
+			m.buildSoundControls(),
+			m.buildMIDIControls(),
+			m.buildVoicingControls(),
+			m.keyboard,
 			widget.NewSeparator(),
-			container.NewVBox(
-				widget.NewCard("", "Triads", m.triadGrid),
-				widget.NewCard("", "Sevenths", m.seventhGrid),
-				widget.NewCard("", "Secondary Dominants", m.secondaryDomGrid),
-				widget.NewCard("", "Secondary Lead Tones", m.secondaryLeadGrid),
-				widget.NewCard("", "Tritone Substitution", m.tritoneSubGrid),
-			),
+			m.tabs,
 		),
 	)
 }
 
-func (m *model) refreshUI() {
+// setKey makes key the key of the model and spells its scale. The views are refreshed separately.
+func (m *model) setKey(key string) {
+	m.viewMu.Lock()
+	defer m.viewMu.Unlock()
+	m.key = key
 	m.scaleNotes = enumerateScale(m.key, m.scaleIntervals)
-	m.scaleLabel.SetText(strings.Join(m.scaleNotes, " "))
+}
 
-	fillChordGrid(m.buildTriads(), m.triadGrid)
-	fillChordGrid(m.buildSevenths(), m.seventhGrid)
-	fillChordGrid(m.buildSecondaryDoms(), m.secondaryDomGrid)
-	fillChordGrid(m.buildSecondaryLeads(), m.secondaryLeadGrid)
-	fillChordGrid(m.buildTritoneSubstition(), m.tritoneSubGrid)
+// setScale makes scale the scale of the model and spells it. The views are refreshed separately.
+func (m *model) setScale(scale string) {
+	m.viewMu.Lock()
+	defer m.viewMu.Unlock()
+	m.scale = scale
+	m.scaleIntervals = scaleIntervals[scale]
+	m.scaleNotes = enumerateScale(m.key, m.scaleIntervals)
+}
+
+func (m *model) refreshUI() {
+	m.scaleLabel.SetText(strings.Join(m.scaleNotes, " "))
+	m.keyboard.setScale(m.scaleNotes)
+	m.fretboard.setScale(m.scaleNotes, m.scaleIntervals)
+	m.viewMu.Lock()
+	m.selected = nil
+	m.viewMu.Unlock()
+	m.showChord(nil)
+
+	m.fillChordGrid(m.buildTriads(), m.triadGrid)
+	m.fillChordGrid(m.buildSevenths(), m.seventhGrid)
+	m.fillChordGrid(m.buildSecondaryDoms(), m.secondaryDomGrid)
+	m.fillChordGrid(m.buildSecondaryLeads(), m.secondaryLeadGrid)
+	m.fillChordGrid(m.buildTritoneSubstition(), m.tritoneSubGrid)
+	m.refreshStaffs()
+	m.refreshCircle()
+	m.refreshVoiceLeading()
+	m.refreshEarTraining()
 }
 
 func (m *model) buildChords(pattern []int, suffixes []string, positionNames []string) []chord {
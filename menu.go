@@ -0,0 +1,80 @@
+package main
+
+import (
+	"io"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+)
+
+// buildMainMenu returns the window menu holding the import and export actions. Images are only exported by builds
+// that can render them.
+func (m *model) buildMainMenu() *fyne.MainMenu {
+	file := []*fyne.MenuItem{
+		fyne.NewMenuItem("Import MIDI…", m.showMIDIImport),
+		fyne.NewMenuItem("Import ChordPro…", m.showChordProImport),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Export MIDI…", m.showMIDIExport),
+		fyne.NewMenuItem("Export MusicXML…", m.showMusicXMLExport),
+		fyne.NewMenuItem("Export LilyPond…", m.showLilyPondExport),
+		fyne.NewMenuItem("Export ABC…", m.showABCExport),
+		fyne.NewMenuItem("Export ChordPro…", m.showChordProExport),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Export Chord Sheet…", m.showSheetExport),
+	}
+	if imageExport {
+		file = append(file, fyne.NewMenuItem("Export Image…", m.showImageExport))
+	}
+	file = append(file, fyne.NewMenuItemSeparator(), fyne.NewMenuItem("Export Practice CSV…", m.showPracticeExport))
+
+	return fyne.NewMainMenu(
+		fyne.NewMenu("File", file...),
+		fyne.NewMenu("Edit",
+			fyne.NewMenuItem("Copy as JSON", m.copyJSON),
+		),
+	)
+}
+
+// saveFile asks where to save a file with the given extension and writes it.
+func (m *model) saveFile(name, extension string, write func(w io.Writer) error) {
+	d := dialog.NewFileSave(func(uc fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, m.window)
+			return
+		}
+		if uc == nil {
+			return
+		}
+		if err := write(uc); err != nil {
+			_ = uc.Close()
+			dialog.ShowError(err, m.window)
+			return
+		}
+		if err := uc.Close(); err != nil {
+			dialog.ShowError(err, m.window)
+		}
+	}, m.window)
+	d.SetFileName(name)
+	d.SetFilter(storage.NewExtensionFileFilter([]string{extension}))
+	d.Show()
+}
+
+// openFile asks for a file with one of the given extensions and reads it.
+func (m *model) openFile(extensions []string, read func(r io.Reader, name string) error) {
+	d := dialog.NewFileOpen(func(uc fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, m.window)
+			return
+		}
+		if uc == nil {
+			return
+		}
+		defer uc.Close()
+		if err := read(uc, uc.URI().Name()); err != nil {
+			dialog.ShowError(err, m.window)
+		}
+	}, m.window)
+	d.SetFilter(storage.NewExtensionFileFilter(extensions))
+	d.Show()
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	exportScale       = "Scale"
+	exportProgression = "Progression"
+
+	voicingClose  = "Close"
+	voicingDrop2  = "Drop 2"
+	voicingSpread = "Spread"
+
+	defaultVelocity = 90
+)
+
+// midiOptions controls how notes are placed in an exported MIDI file.
+type midiOptions struct {
+	tempo    int     // beats per minute
+	octave   int     // octave of the scale tonic or chord roots, other than in voice-leading voicings
+	length   float64 // beats given to each note or chord
+	velocity int
+	voicing  string
+}
+
+var (
+	voicings    = append([]string{voicingClose, voicingDrop2, voicingSpread}, leadVoicings...)
+	noteLengths = []string{"0.5", "1", "2", "4"}
+	octaves     = []string{"2", "3", "4", "5", "6"}
+)
+
+func defaultMIDIOptions() midiOptions {
+	return midiOptions{
+		tempo:    defaultTempo,
+		octave:   chordOctave,
+		length:   chordBeats,
+		velocity: defaultVelocity,
+		voicing:  voicingClose,
+	}
+}
+
+// voice spells the chord tones upwards from the root in the given octave and rearranges them: drop 2 lowers the second
+// highest note by an octave, spread lowers the root by an octave below the close voicing of the other tones.
+func voice(notes []string, octave int, voicing string) []pitch {
+	pitches := voiceNotes(notes, octave)
+	switch {
+	case voicing == voicingDrop2 && len(pitches) >= 3:
+		dropped := pitches[len(pitches)-2]
+		dropped.octave--
+		rest := append(append([]pitch{}, pitches[:len(pitches)-2]...), pitches[len(pitches)-1])
+		pitches = append([]pitch{dropped}, rest...)
+	case voicing == voicingSpread && len(pitches) >= 2:
+		pitches[0].octave--
+	}
+
+	return pitches
+}
+
+// exportItems lists what can be exported: the scale, every chord section and the progression.
+func (m *model) exportItems() []string {
+	items := []string{exportScale}
+	for _, sec := range m.chordSections() {
+		items = append(items, sec.title)
+	}
+	return append(items, exportProgression)
+}
+
+// exportChords returns the chords of an export item other than the scale.
+func (m *model) exportChords(item string) []chord {
+	if item == exportProgression {
+		return m.progression
+	}
+	for _, sec := range m.chordSections() {
+		if sec.title == item {
+			return sec.chords
+		}
+	}
+
+	return nil
+}
+
+// midiTracks returns a conductor track with the tempo, time and key signatures, and a track playing the scale, one
+// note after the other, or the chords of the item, one after the other with their names as markers.
+func (m *model) midiTracks(item string, opts midiOptions) []midiTrack {
+	length := int(opts.length * ticksPerBeat)
+	conductor := midiTrack{
+		name: fmt.Sprintf("%s %s", m.key, m.scale),
+		events: []midiEvent{
+			tempoEvent(0, opts.tempo),
+			timeSigEvent(0, 4),
+		},
+	}
+	if k := keySignatureFor(m.key, m.scale); k.valid() {
+		conductor.events = append(conductor.events, keySigEvent(0, k, m.scale == "Minor"))
+	}
+
+	notes := midiTrack{name: item}
+	if item == exportScale {
+		scale := append(append([]string{}, m.scaleNotes...), m.scaleNotes[0])
+		for i, p := range voiceNotes(scale, opts.octave) {
+			notes.events = append(notes.events, noteEvents(i*length, length, 0, p.midi(), opts.velocity)...)
+		}
+	} else {
+		chords := m.exportChords(item)
+		for i, pitches := range voiceChords(chords, m.key, opts.octave, opts.voicing) {
+			conductor.events = append(conductor.events, midiEvent{i * length, metaData(metaMarker, []byte(chords[i].name))})
+			for _, p := range pitches {
+				notes.events = append(notes.events, noteEvents(i*length, length, 0, p.midi(), opts.velocity)...)
+			}
+		}
+	}
+
+	return []midiTrack{conductor, notes}
+}
+
+// exportMIDI writes the item as a Standard MIDI File.
+func (m *model) exportMIDI(w io.Writer, item string, opts midiOptions) error {
+	return writeSMF(w, m.midiTracks(item, opts))
+}
+
+// showMIDIExport asks what to export and how, then asks where to save the MIDI file.
+func (m *model) showMIDIExport() {
+	opts := defaultMIDIOptions()
+	opts.tempo = m.synth.tempo
+
+	itemSelector := widget.NewSelect(m.exportItems(), nil)
+	itemSelector.SetSelectedIndex(0)
+	tempoEntry := widget.NewEntry()
+	tempoEntry.SetText(strconv.Itoa(opts.tempo))
+	tempoEntry.Validator = func(s string) error {
+		if v, err := strconv.Atoi(s); err != nil || v < minTempo || v > maxTempo {
+			return fmt.Errorf("tempo must be between %d and %d", minTempo, maxTempo)
+		}
+		return nil
+	}
+	octaveSelector := widget.NewSelect(octaves, nil)
+	octaveSelector.SetSelected(strconv.Itoa(opts.octave))
+	lengthSelector := widget.NewSelect(noteLengths, nil)
+	lengthSelector.SetSelected(strconv.Itoa(chordBeats))
+	velocitySlider := widget.NewSlider(1, 127)
+	velocitySlider.SetValue(float64(opts.velocity))
+	voicingSelector := widget.NewSelect(voicings, nil)
+	voicingSelector.SetSelected(opts.voicing)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Export", itemSelector),
+		widget.NewFormItem("Tempo", tempoEntry),
+		widget.NewFormItem("Octave", octaveSelector),
+		widget.NewFormItem("Beats Each", lengthSelector),
+		widget.NewFormItem("Velocity", velocitySlider),
+		widget.NewFormItem("Voicing", voicingSelector),
+	}
+	dialog.ShowForm("Export MIDI", "Export", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		opts.tempo, _ = strconv.Atoi(tempoEntry.Text)
+		opts.octave, _ = strconv.Atoi(octaveSelector.Selected)
+		opts.length, _ = strconv.ParseFloat(lengthSelector.Selected, 64)
+		opts.velocity = int(velocitySlider.Value)
+		opts.voicing = voicingSelector.Selected
+		item := itemSelector.Selected
+
+		m.saveFile(fmt.Sprintf("%s %s %s.mid", m.key, m.scale, item), ".mid", func(w io.Writer) error {
+			return m.exportMIDI(w, item, opts)
+		})
+	}, m.window)
+}
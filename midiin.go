@@ -0,0 +1,301 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const noMIDIPort = "None"
+
+type (
+	// midiPort is a port of the ALSA sequencer, or a raw MIDI device, that can be opened for input, output or both.
+	midiPort struct {
+		name          string
+		path          string // of the raw MIDI device, or "" for a sequencer port
+		client, port  int    // address of the sequencer port
+		input, output bool
+	}
+
+	// midiParser splits a stream of MIDI bytes into channel messages, following running status.
+	midiParser struct {
+		status byte
+		data   []byte
+	}
+)
+
+// messageLength returns the number of data bytes of a channel message.
+func messageLength(status byte) int {
+	if kind := status & 0xf0; kind == programChange || kind == channelPressure {
+		return 1
+	}
+	return 2
+}
+
+// feed adds a byte of the stream and returns the channel message it completes, if any. System exclusive and common
+// messages are skipped. Realtime messages, which may come between the bytes of another message, are ignored.
+func (p *midiParser) feed(b byte) ([]byte, bool) {
+	switch {
+	case b >= 0xf8:
+		return nil, false
+	case b >= sysex:
+		p.status = 0
+		return nil, false
+	case b >= noteOff:
+		p.status, p.data = b, p.data[:0]
+		return nil, false
+	case p.status == 0:
+		return nil, false
+	}
+
+	p.data = append(p.data, b)
+	if len(p.data) < messageLength(p.status) {
+		return nil, false
+	}
+	msg := append([]byte{p.status}, p.data...)
+	p.data = p.data[:0]
+
+	return msg, true
+}
+
+// readMIDI reads MIDI bytes until r fails, reporting every note that starts or stops on any channel.
+func readMIDI(r io.Reader, note func(note int, on bool)) error {
+	var p midiParser
+	buf := make([]byte, 256)
+	for {
+		n, err := r.Read(buf)
+		for _, b := range buf[:n] {
+			msg, ok := p.feed(b)
+			if !ok {
+				continue
+			}
+			switch msg[0] & 0xf0 {
+			case noteOn:
+				note(int(msg[1]), msg[2] > 0)
+			case noteOff:
+				note(int(msg[1]), false)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// diatonic reports whether every note is in the scale.
+func diatonic(notes []int, scaleNotes []string) bool {
+	var scale int
+	for _, n := range scaleNotes {
+		scale |= 1 << notePitchClass(n)
+	}
+	for _, n := range notes {
+		if scale&(1<<pitchClass(n)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// describeHeld names the chord of the held notes in the current key and says whether it is diatonic, such as
+// "C E G: C, I, diatonic". The notes are spelled as tones of the chord when there is one.
+func (m *model) describeHeld(notes []int) (string, *chordMatch) {
+	match, ok := identifyChord(notes)
+	var c, numeral chord
+	if ok {
+		c = match.spell(spellPitchClass(match.root, m.scaleNotes), m.scaleNotes)
+		var found bool
+		if numeral, found = romanNumeral(match, m.scaleNotes, m.chordSections()); found {
+			c = chord{name: numeral.name + match.slash(numeral.notes, m.scaleNotes), notes: numeral.notes}
+		}
+	}
+
+	var names []string
+	seen := make(map[int]bool)
+	for _, n := range notes {
+		pc := pitchClass(n)
+		if seen[pc] {
+			continue
+		}
+		seen[pc] = true
+		name := spellPitchClass(pc, m.scaleNotes)
+		for _, cn := range c.notes {
+			if notePitchClass(cn) == pc {
+				name = cn
+			}
+		}
+		names = append(names, name)
+	}
+
+	text := strings.Join(names, " ")
+	if ok {
+		text += ": " + c.name
+		if numeral.position != "" {
+			text += ", " + numeral.position
+		}
+	}
+	if diatonic(notes, m.scaleNotes) {
+		text += ", diatonic"
+	} else {
+		text += ", not diatonic"
+	}
+
+	if !ok {
+		return text, nil
+	}
+	return text, &match
+}
+
+// buildMIDIControls returns the MIDI input selector with the description of the notes held on it, and the MIDI output
+// controls.
+func (m *model) buildMIDIControls() fyne.CanvasObject {
+	m.held = make(map[int]bool)
+	m.liveText = binding.NewString()
+	m.midiInSelector = widget.NewSelect(nil, m.connectMIDIIn)
+	rescan := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), m.scanMIDIPorts)
+	out := m.buildMIDIOutControls()
+	m.scanMIDIPorts()
+
+	return container.NewVBox(
+		container.NewHBox(widget.NewLabel("MIDI In"), m.midiInSelector, rescan, widget.NewLabelWithData(m.liveText)),
+		out,
+	)
+}
+
+// scanMIDIPorts lists the MIDI ports in the input and output selectors.
+func (m *model) scanMIDIPorts() {
+	ports, err := midiPorts()
+	if err != nil {
+		fyne.LogError("Unable to list MIDI ports", err)
+	}
+	m.midiPorts = ports
+
+	inputs, outputs := []string{noMIDIPort}, []string{noMIDIPort}
+	for _, p := range ports {
+		if p.input {
+			inputs = append(inputs, p.name)
+		}
+		if p.output {
+			outputs = append(outputs, p.name)
+		}
+	}
+	m.midiInSelector.Options = inputs
+	m.midiInSelector.Refresh()
+	if m.midiIn == nil {
+		m.midiInSelector.SetSelected(noMIDIPort)
+	}
+	m.midiOutSelector.Options = outputs
+	m.midiOutSelector.Refresh()
+	if m.midiOut == nil {
+		m.midiOutSelector.SetSelected(noMIDIPort)
+	}
+}
+
+// connectMIDIIn closes the open MIDI input, if any, and starts listening to the named port.
+func (m *model) connectMIDIIn(name string) {
+	if m.midiIn != nil {
+		_ = m.midiIn.Close()
+		m.midiIn = nil
+	}
+	m.heldMu.Lock()
+	m.held = make(map[int]bool)
+	m.heldMu.Unlock()
+	m.refreshLive()
+
+	for _, p := range m.midiPorts {
+		if p.name != name || !p.input {
+			continue
+		}
+		in, err := openMIDIIn(p)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("unable to open MIDI input %s: %w", name, err), m.window)
+			m.midiInSelector.SetSelected(noMIDIPort)
+			return
+		}
+		m.midiIn = in
+		go m.listen(in)
+	}
+}
+
+// listen follows the notes played on the MIDI input until it is closed.
+func (m *model) listen(in io.Reader) {
+	err := readMIDI(in, func(note int, on bool) {
+		m.heldMu.Lock()
+		if on {
+			m.held[note] = true
+		} else {
+			delete(m.held, note)
+		}
+		m.heldMu.Unlock()
+		m.refreshLive()
+	})
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, os.ErrClosed) {
+		fyne.LogError("Unable to read MIDI input", err)
+	}
+}
+
+// liveView returns a copy of the key, scale, voicing and selected chord of the model, with its keyboard, for the
+// goroutine reading the MIDI input to work from while the window changes them.
+func (m *model) liveView() *model {
+	m.viewMu.Lock()
+	defer m.viewMu.Unlock()
+	return &model{
+		key:            m.key,
+		scale:          m.scale,
+		scaleNotes:     m.scaleNotes,
+		scaleIntervals: m.scaleIntervals,
+		voicing:        m.voicing,
+		selected:       m.selected,
+		keyboard:       m.keyboard,
+	}
+}
+
+// refreshLive shows the held notes on the keyboard, describes them and highlights the triad and seventh cards of
+// their chord. Once every note is released, the selected chord is shown again. It is called from the goroutine reading
+// the MIDI input, so it works from a liveView of the model, sets the description through a binding, only changes
+// widgets that guard their own state and holds viewMu while reading the chord grids.
+func (m *model) refreshLive() {
+	m.heldMu.Lock()
+	notes := make([]int, 0, len(m.held))
+	for n := range m.held {
+		notes = append(notes, n)
+	}
+	m.heldMu.Unlock()
+	sort.Ints(notes)
+
+	view := m.liveView()
+	var match *chordMatch
+	if len(notes) == 0 {
+		_ = m.liveText.Set("")
+		view.showOnKeyboard(view.selected)
+	} else {
+		var text string
+		text, match = view.describeHeld(notes)
+		_ = m.liveText.Set(text)
+		root := -1
+		if match != nil {
+			root = match.root
+		}
+		m.keyboard.setNotes(notes, root)
+	}
+
+	m.viewMu.Lock()
+	defer m.viewMu.Unlock()
+	for _, grid := range []*fyne.Container{m.triadGrid, m.seventhGrid} {
+		for _, o := range grid.Objects {
+			if card, ok := o.(*chordCard); ok {
+				card.setHighlighted(match != nil && match.matches(card.chord))
+			}
+		}
+	}
+}
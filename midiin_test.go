@@ -0,0 +1,155 @@
+package main
+
+import (
+	"io"
+	"testing"
+
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMIDIParser(t *testing.T) {
+	stream := []byte{
+		0x90, 60, 100, // note on
+		64, 100, // running status
+		0xf8,               // clock between messages
+		0x90, 67, 0xfe, 90, // active sensing within a message
+		0xf0, 0x7e, 0x7f, 0x06, 0x01, 0xf7, // sysex is skipped
+		70, 1, // with no running status after sysex
+		0xc0, 5, // program change has one data byte
+		0x80, 60, 0,
+	}
+
+	var p midiParser
+	var msgs [][]byte
+	for _, b := range stream {
+		if msg, ok := p.feed(b); ok {
+			msgs = append(msgs, msg)
+		}
+	}
+	assert.Equal(t, [][]byte{
+		{0x90, 60, 100},
+		{0x90, 64, 100},
+		{0x90, 67, 90},
+		{0xc0, 5},
+		{0x80, 60, 0},
+	}, msgs)
+}
+
+func TestReadMIDI(t *testing.T) {
+	r, w := io.Pipe()
+	go func() {
+		_, _ = w.Write([]byte{0x91, 60, 100, 64, 100})
+		_, _ = w.Write([]byte{67, 100, 0x81, 64, 0, 0x91, 60, 0})
+		_ = w.Close()
+	}()
+
+	held := make(map[int]bool)
+	err := readMIDI(r, func(note int, on bool) {
+		if on {
+			held[note] = true
+		} else {
+			delete(held, note)
+		}
+	})
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, map[int]bool{67: true}, held)
+}
+
+func TestDiatonic(t *testing.T) {
+	scale := enumerateScale("A", scaleIntervals["Minor"])
+	assert.True(t, diatonic([]int{57, 60, 64}, scale))
+	assert.False(t, diatonic([]int{64, 68, 71}, scale))
+}
+
+func TestDescribeHeld(t *testing.T) {
+	m := model{key: "G", scale: "Major", scaleIntervals: scaleIntervals["Major"]}
+	m.scaleNotes = enumerateScale(m.key, m.scaleIntervals)
+
+	tests := []struct {
+		notes []int
+		text  string
+		root  int
+	}{
+		{[]int{55, 59, 62}, "G B D: G, I, diatonic", 7},
+		{[]int{50, 54, 57, 60}, "D F♯ A C: D7, V⁷, diatonic", 2},
+		{[]int{57, 61, 64, 67}, "A C♯ E G: A7, V⁷ / V, not diatonic", 9},
+		{[]int{59, 67, 74}, "B G D: G/B, I, diatonic", 7},
+		{[]int{58, 62, 65}, "B♭ D F: B♭, ♭III, not diatonic", 10},
+		{[]int{60, 61}, "C D♭, not diatonic", -1},
+	}
+
+	for _, e := range tests {
+		text, match := m.describeHeld(e.notes)
+		assert.Equal(t, e.text, text)
+		if e.root < 0 {
+			assert.Nil(t, match)
+		} else if assert.NotNil(t, match) {
+			assert.Equal(t, e.root, match.root)
+		}
+	}
+}
+
+func TestKeyboardSetNotes(t *testing.T) {
+	k := newKeyboard()
+	k.setNotes([]int{52, 60, 67, 88}, 0)
+	assert.Equal(t, map[int]bool{4: true, 12: true, 19: true, 16: true}, k.chordKeys)
+	assert.Equal(t, 12, k.rootKey)
+
+	k.setNotes(nil, -1)
+	assert.Empty(t, k.chordKeys)
+	assert.Equal(t, -1, k.rootKey)
+}
+
+// TestListenWhileSelecting plays notes on the MIDI input while the key, scale and voicing change, chords are selected
+// and the chord grids are filled, for the race detector to check that the goroutine reading the input shares the
+// model, keyboard and cards safely.
+func TestListenWhileSelecting(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	m := sheetModel("C", "Major")
+	m.keyboard = newKeyboard()
+	m.fretboard = newFretboard()
+	m.liveText = binding.NewString()
+	m.triadGrid = container.NewGridWithColumns(7)
+	m.seventhGrid = container.NewGridWithColumns(7)
+	m.held = make(map[int]bool)
+	w := test.NewWindow(container.NewVBox(m.keyboard, m.triadGrid))
+	defer w.Close()
+
+	r, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		m.listen(r)
+		close(done)
+	}()
+	go func() {
+		for i := 0; i < 100; i++ {
+			for _, n := range []byte{60, 64, 67} {
+				_, _ = pw.Write([]byte{noteOn, n, 100})
+			}
+			for _, n := range []byte{60, 64, 67} {
+				_, _ = pw.Write([]byte{noteOff, n, 0})
+			}
+		}
+		_ = pw.Close()
+	}()
+
+	for i := 0; i < 100; i++ {
+		m.setKey(keyNames[i%len(keyNames)])
+		m.setScale(scaleNames[i%len(scaleNames)])
+		m.selectVoicing(jazzVoicings[i%len(jazzVoicings)])
+		triads := m.buildTriads()
+		m.fillChordGrid(triads, m.triadGrid)
+		m.selectChord(triads[i%len(triads)])
+		m.refreshLive()
+	}
+	<-done
+	m.refreshLive()
+	text, err := m.liveText.Get()
+	assert.NoError(t, err)
+	assert.Empty(t, text)
+}
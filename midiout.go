@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const midiChannels = 16
+
+type (
+	// midiMessage is a MIDI message to be sent at a time from the start of playback.
+	midiMessage struct {
+		at   time.Duration
+		data []byte
+	}
+
+	// midiOutput plays notes on a MIDI port, replacing anything it is still playing.
+	midiOutput struct {
+		mu       sync.Mutex
+		w        io.WriteCloser
+		channel  int // 0 to 15
+		velocity int
+		octave   int // octave of the root of chords, as chordOctave is for the synth
+		stop     chan struct{}
+		done     chan struct{}
+	}
+)
+
+var midiChannelNames []string
+
+func init() {
+	for i := 1; i <= midiChannels; i++ {
+		midiChannelNames = append(midiChannelNames, strconv.Itoa(i))
+	}
+}
+
+// midiSchedule returns the note on and off messages of the notes, in the order they are to be sent. The notes are
+// moved by transpose semitones; any that fall outside the MIDI range are dropped.
+func midiSchedule(notes []synthNote, channel, velocity, transpose int) []midiMessage {
+	var msgs []midiMessage
+	for _, n := range notes {
+		note := n.midi + transpose
+		if note < 0 || note > 127 {
+			continue
+		}
+		start := time.Duration(n.start * float64(time.Second))
+		end := time.Duration((n.start + n.length) * float64(time.Second))
+		for _, e := range noteEvents(0, 0, channel, note, velocity) {
+			at := start
+			if e.data[0]&0xf0 == noteOff {
+				at = end
+			}
+			msgs = append(msgs, midiMessage{at, e.data})
+		}
+	}
+	sort.SliceStable(msgs, func(i, j int) bool {
+		if msgs[i].at != msgs[j].at {
+			return msgs[i].at < msgs[j].at
+		}
+		return msgs[i].data[0]&0xf0 == noteOff && msgs[j].data[0]&0xf0 != noteOff
+	})
+
+	return msgs
+}
+
+func newMIDIOutput(w io.WriteCloser) *midiOutput {
+	return &midiOutput{w: w, velocity: defaultVelocity, octave: chordOctave}
+}
+
+// play sends the notes, which are voiced around chordOctave, in the background. Anything still playing is stopped
+// first.
+func (o *midiOutput) play(notes []synthNote) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.halt()
+	msgs := midiSchedule(notes, o.channel, o.velocity, (o.octave-chordOctave)*chromaticScaleLen)
+	o.stop, o.done = make(chan struct{}), make(chan struct{})
+	go o.send(msgs, o.stop, o.done)
+}
+
+// halt stops playback and waits for its notes to be released. The caller must hold mu.
+func (o *midiOutput) halt() {
+	if o.stop == nil {
+		return
+	}
+	close(o.stop)
+	<-o.done
+	o.stop, o.done = nil, nil
+}
+
+// send writes each message at its time until stopped, then releases any notes left sounding.
+func (o *midiOutput) send(msgs []midiMessage, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	sounding := make(map[[2]byte]bool)
+	defer func() {
+		for n := range sounding {
+			_, _ = o.w.Write([]byte{noteOff | n[0], n[1], 0})
+		}
+	}()
+
+	start := time.Now()
+	for _, msg := range msgs {
+		if wait := msg.at - time.Since(start); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+		if _, err := o.w.Write(msg.data); err != nil {
+			fyne.LogError("Unable to write to MIDI output", err)
+			return
+		}
+		n := [2]byte{msg.data[0] & 0x0f, msg.data[1]}
+		if msg.data[0]&0xf0 == noteOn {
+			sounding[n] = true
+		} else {
+			delete(sounding, n)
+		}
+	}
+}
+
+// close stops playback and closes the port.
+func (o *midiOutput) close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.halt()
+	return o.w.Close()
+}
+
+// buildMIDIOutControls returns the MIDI output selector with the channel, octave and velocity of the notes sent.
+func (m *model) buildMIDIOutControls() fyne.CanvasObject {
+	m.midiOutSelector = widget.NewSelect(nil, m.connectMIDIOut)
+	channelSelector := widget.NewSelect(midiChannelNames, func(s string) {
+		m.midiOutChannel, _ = strconv.Atoi(s)
+		m.midiOutChannel--
+		m.configureMIDIOut()
+	})
+	channelSelector.SetSelectedIndex(0)
+	octaveSelector := widget.NewSelect(octaves, func(s string) {
+		m.midiOutOctave, _ = strconv.Atoi(s)
+		m.configureMIDIOut()
+	})
+	octaveSelector.SetSelected(strconv.Itoa(chordOctave))
+
+	velocityLabel := widget.NewLabel("")
+	velocitySlider := widget.NewSlider(1, 127)
+	velocitySlider.OnChanged = func(v float64) {
+		m.midiOutVelocity = int(v)
+		velocityLabel.SetText(strconv.Itoa(m.midiOutVelocity))
+		m.configureMIDIOut()
+	}
+	velocitySlider.SetValue(defaultVelocity)
+
+	return container.NewBorder(nil, nil,
+		container.NewHBox(
+			widget.NewLabel("MIDI Out"),
+			m.midiOutSelector,
+			widget.NewLabel("Channel"),
+			channelSelector,
+			widget.NewLabel("Octave"),
+			octaveSelector,
+			widget.NewLabel("Velocity"),
+		),
+		velocityLabel,
+		velocitySlider,
+	)
+}
+
+// configureMIDIOut applies the channel, octave and velocity to the open MIDI output.
+func (m *model) configureMIDIOut() {
+	if m.midiOut == nil {
+		return
+	}
+	m.midiOut.mu.Lock()
+	m.midiOut.channel, m.midiOut.octave, m.midiOut.velocity = m.midiOutChannel, m.midiOutOctave, m.midiOutVelocity
+	m.midiOut.mu.Unlock()
+}
+
+// connectMIDIOut closes the open MIDI output, if any, and opens the named port.
+func (m *model) connectMIDIOut(name string) {
+	if m.midiOut != nil {
+		if err := m.midiOut.close(); err != nil {
+			fyne.LogError("Unable to close MIDI output", err)
+		}
+		m.midiOut = nil
+	}
+
+	for _, p := range m.midiPorts {
+		if p.name != name || !p.output {
+			continue
+		}
+		w, err := openMIDIOut(p)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("unable to open MIDI output %s: %w", name, err), m.window)
+			m.midiOutSelector.SetSelected(noMIDIPort)
+			return
+		}
+		m.midiOut = newMIDIOutput(w)
+		m.configureMIDIOut()
+	}
+}
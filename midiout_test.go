@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder is a MIDI port that records what is written to it.
+type recorder struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	closed bool
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.buf.Write(p)
+}
+
+func (r *recorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func (r *recorder) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buf.Reset()
+}
+
+func (r *recorder) bytes() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]byte{}, r.buf.Bytes()...)
+}
+
+func TestMIDISchedule(t *testing.T) {
+	notes := []synthNote{{60, 0, 1}, {64, 0.5, 0.5}, {60, 1, 1}, {130, 0, 1}}
+	assert.Equal(t, []midiMessage{
+		{0, []byte{0x92, 72, 80}},
+		{500 * time.Millisecond, []byte{0x92, 76, 80}},
+		{time.Second, []byte{0x82, 72, 0}},
+		{time.Second, []byte{0x82, 76, 0}},
+		{time.Second, []byte{0x92, 72, 80}},
+		{2 * time.Second, []byte{0x82, 72, 0}},
+	}, midiSchedule(notes, 2, 80, 12))
+}
+
+func TestMIDIOutputPlay(t *testing.T) {
+	r := &recorder{}
+	o := newMIDIOutput(r)
+	o.channel, o.velocity = 1, 100
+
+	o.play([]synthNote{{60, 0, 0.01}, {64, 0, 0.01}})
+	o.mu.Lock()
+	done := o.done
+	o.mu.Unlock()
+	<-done
+	assert.Equal(t, []byte{0x91, 60, 100, 0x91, 64, 100, 0x81, 60, 0, 0x81, 64, 0}, r.bytes())
+
+	// Playing again stops the notes still sounding. The first note is sent at once, so it has started by then.
+	r.reset()
+	o.octave = chordOctave - 1
+	o.play([]synthNote{{60, 0, 10}})
+	o.play(nil)
+	assert.Equal(t, []byte{0x91, 48, 100, 0x81, 48, 0}, r.bytes())
+
+	assert.NoError(t, o.close())
+	assert.True(t, r.closed)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Raw MIDI devices of the ALSA sound cards, used for input and output when the sequencer is not available, as when
+// the snd-seq module is not loaded.
+var (
+	midiDeviceDir = "/dev/snd"
+	soundCardDir  = "/proc/asound"
+)
+
+// midiPorts lists the ports of the ALSA sequencer, which include those of sound cards as well as of software such as
+// VMPK and fluidsynth, or the raw MIDI devices without the sequencer.
+func midiPorts() ([]midiPort, error) {
+	if ports, err := seqPorts(); err == nil {
+		return ports, nil
+	}
+	return rawMIDIPorts()
+}
+
+// rawMIDIPorts lists the raw MIDI devices.
+func rawMIDIPorts() ([]midiPort, error) {
+	paths, err := filepath.Glob(filepath.Join(midiDeviceDir, "midiC*D*"))
+	if err != nil {
+		return nil, err
+	}
+
+	var ports []midiPort
+	for _, p := range paths {
+		var card, device int
+		if _, err := fmt.Sscanf(filepath.Base(p), "midiC%dD%d", &card, &device); err != nil {
+			continue
+		}
+		name := fmt.Sprintf("hw:%d,%d", card, device)
+		if id, err := os.ReadFile(filepath.Join(soundCardDir, fmt.Sprintf("card%d", card), "id")); err == nil {
+			name = fmt.Sprintf("%s %d (%s)", strings.TrimSpace(string(id)), device, name)
+		}
+		ports = append(ports, midiPort{name: name, path: p, input: true, output: true})
+	}
+
+	return ports, nil
+}
+
+// openMIDIIn opens the port for reading MIDI bytes.
+func openMIDIIn(p midiPort) (io.ReadCloser, error) {
+	if p.path == "" {
+		return openSeqIn(p)
+	}
+	return os.OpenFile(p.path, os.O_RDONLY, 0)
+}
+
+// openMIDIOut opens the port for writing MIDI bytes.
+func openMIDIOut(p midiPort) (io.WriteCloser, error) {
+	if p.path == "" {
+		return openSeqOut(p)
+	}
+	return os.OpenFile(p.path, os.O_WRONLY, 0)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMIDIPorts stands a FIFO in for the raw MIDI device of a virtual port, without the sequencer.
+func TestMIDIPorts(t *testing.T) {
+	dir := t.TempDir()
+	defer func(devices, cards, seq string) {
+		midiDeviceDir, soundCardDir, seqDevice = devices, cards, seq
+	}(midiDeviceDir, soundCardDir, seqDevice)
+	midiDeviceDir, soundCardDir, seqDevice = dir, dir, filepath.Join(dir, "seq")
+
+	assert.NoError(t, syscall.Mkfifo(filepath.Join(dir, "midiC1D0"), 0o600))
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "card1"), 0o700))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "card1", "id"), []byte("VirMIDI\n"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "midiC2D1"), nil, 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "pcmC0D0p"), nil, 0o600))
+
+	ports, err := midiPorts()
+	assert.NoError(t, err)
+	assert.Equal(t, []midiPort{
+		{name: "VirMIDI 0 (hw:1,0)", path: filepath.Join(dir, "midiC1D0"), input: true, output: true},
+		{name: "hw:2,1", path: filepath.Join(dir, "midiC2D1"), input: true, output: true},
+	}, ports)
+
+	go func() {
+		f, err := os.OpenFile(ports[0].path, os.O_WRONLY, 0)
+		if err != nil {
+			return
+		}
+		_, _ = f.Write([]byte{0x90, 60, 100, 64, 100, 67, 100})
+		_ = f.Close()
+	}()
+	in, err := openMIDIIn(ports[0])
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer in.Close()
+
+	var notes []int
+	_ = readMIDI(in, func(note int, on bool) {
+		notes = append(notes, note)
+	})
+	assert.Equal(t, []int{60, 64, 67}, notes)
+}
+
+func TestMIDIOut(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "midiC1D0")
+	assert.NoError(t, syscall.Mkfifo(path, 0o600))
+
+	received := make(chan []byte)
+	go func() {
+		f, err := os.Open(path)
+		if err != nil {
+			close(received)
+			return
+		}
+		data, _ := io.ReadAll(f)
+		_ = f.Close()
+		received <- data
+	}()
+
+	w, err := openMIDIOut(midiPort{name: "hw:1,0", path: path})
+	if !assert.NoError(t, err) {
+		return
+	}
+	o := newMIDIOutput(w)
+	o.play([]synthNote{{62, 0, 0.01}})
+	assert.NoError(t, o.close())
+	assert.Equal(t, []byte{0x90, 62, defaultVelocity, 0x80, 62, 0}, <-received)
+}
@@ -0,0 +1,23 @@
+//go:build !linux
+
+package main
+
+import (
+	"errors"
+	"io"
+)
+
+var errNoMIDIPorts = errors.New("MIDI ports are only supported on Linux")
+
+// midiPorts finds no ports outside Linux.
+func midiPorts() ([]midiPort, error) {
+	return nil, nil
+}
+
+func openMIDIIn(midiPort) (io.ReadCloser, error) {
+	return nil, errNoMIDIPorts
+}
+
+func openMIDIOut(midiPort) (io.WriteCloser, error) {
+	return nil, errNoMIDIPorts
+}
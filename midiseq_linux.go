@@ -0,0 +1,388 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// The kernel interface of the ALSA sequencer, from <sound/asequencer.h>.
+const (
+	seqCapRead      = 1 << 0
+	seqCapWrite     = 1 << 1
+	seqCapSubsRead  = 1 << 5
+	seqCapSubsWrite = 1 << 6
+	seqCapNoExport  = 1 << 7
+
+	seqPortTypeMIDIGeneric = 1 << 1
+	seqPortTypeApplication = 1 << 20
+
+	seqEventNoteOn     = 6
+	seqEventNoteOff    = 7
+	seqEventKeyPress   = 8
+	seqEventController = 10
+	seqEventPgmChange  = 11
+	seqEventChanPress  = 12
+	seqEventPitchBend  = 13
+
+	seqEventLengthMask     = 3 << 2
+	seqEventLengthVariable = 1 << 2
+	seqExtMask             = 0xc0000000
+
+	seqQueueDirect        = 253
+	seqAddressUnknown     = 253
+	seqAddressSubscribers = 254
+
+	seqClientSystem = 0
+	seqClientName   = "Chords for Keys" // of the app's clients and their ports
+
+	polyPressure  = 0xa0
+	controlChange = 0xb0
+	pitchBend     = 0xe0
+)
+
+type (
+	// seqAddr is the address of a sequencer port.
+	seqAddr struct {
+		client, port uint8
+	}
+
+	// seqClientInfo is struct snd_seq_client_info.
+	seqClientInfo struct {
+		client          int32
+		kind            int32
+		name            [64]byte
+		filter          uint32
+		multicastFilter [8]byte
+		eventFilter     [32]byte
+		numPorts        int32
+		eventLost       int32
+		card            int32
+		pid             int32
+		reserved        [56]byte
+	}
+
+	// seqPortInfo is struct snd_seq_port_info.
+	seqPortInfo struct {
+		addr         seqAddr
+		name         [64]byte
+		capability   uint32
+		kind         uint32
+		midiChannels int32
+		midiVoices   int32
+		synthVoices  int32
+		readUse      int32
+		writeUse     int32
+		kernel       uintptr
+		flags        uint32
+		timeQueue    uint8
+		reserved     [59]byte
+	}
+
+	// seqPortSubscribe is struct snd_seq_port_subscribe.
+	seqPortSubscribe struct {
+		sender   seqAddr
+		dest     seqAddr
+		voices   uint32
+		flags    uint32
+		queue    uint8
+		pad      [3]byte
+		reserved [64]byte
+	}
+
+	// seqEvent is struct snd_seq_event, with the data of the MIDI channel events: a note and its velocities, or the
+	// parameter and value of a control. The duration of notes is in param.
+	seqEvent struct {
+		kind   uint8
+		flags  uint8
+		tag    uint8
+		queue  uint8
+		time   [2]uint32
+		source seqAddr
+		dest   seqAddr
+		data   seqEventData
+	}
+
+	seqEventData struct {
+		channel     uint8
+		note        uint8
+		velocity    uint8
+		offVelocity uint8
+		param       uint32
+		value       int32
+	}
+
+	// seqClient is a client of the ALSA sequencer with one port of its own, connected to a port of another client.
+	seqClient struct {
+		f      *os.File
+		port   seqAddr
+		buf    bytes.Buffer // MIDI bytes read and not yet returned
+		parser midiParser   // of the MIDI bytes written
+	}
+)
+
+// The sequencer device and its ioctl requests.
+var (
+	seqDevice = "/dev/snd/seq"
+
+	seqIoctlClientID        = ioctlRequest(2, 0x01, unsafe.Sizeof(int32(0)))
+	seqIoctlGetClientInfo   = ioctlRequest(3, 0x10, unsafe.Sizeof(seqClientInfo{}))
+	seqIoctlSetClientInfo   = ioctlRequest(1, 0x11, unsafe.Sizeof(seqClientInfo{}))
+	seqIoctlCreatePort      = ioctlRequest(3, 0x20, unsafe.Sizeof(seqPortInfo{}))
+	seqIoctlSubscribePort   = ioctlRequest(1, 0x30, unsafe.Sizeof(seqPortSubscribe{}))
+	seqIoctlQueryNextClient = ioctlRequest(3, 0x51, unsafe.Sizeof(seqClientInfo{}))
+	seqIoctlQueryNextPort   = ioctlRequest(3, 0x52, unsafe.Sizeof(seqPortInfo{}))
+)
+
+// ioctlRequest encodes a request of the sequencer with the direction of its argument: 1 to write it to the kernel, 2
+// to read it back and 3 for both.
+func ioctlRequest(dir, nr, size uintptr) uintptr {
+	return dir<<30 | size<<16 | 'S'<<8 | nr
+}
+
+// cString returns the NUL-terminated string in b.
+func cString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
+// openSeq opens the sequencer as a new client, with flag os.O_RDONLY to receive events, os.O_WRONLY to send them or
+// os.O_RDWR for both.
+func openSeq(flag int) (*os.File, error) {
+	f, err := os.OpenFile(seqDevice, flag, 0)
+	if err != nil {
+		return nil, err
+	}
+	var info seqClientInfo
+	err = seqIoctl(f, seqIoctlClientID, unsafe.Pointer(&info.client))
+	if err == nil {
+		err = seqIoctl(f, seqIoctlGetClientInfo, unsafe.Pointer(&info))
+	}
+	if err == nil {
+		copy(info.name[:len(info.name)-1], seqClientName)
+		err = seqIoctl(f, seqIoctlSetClientInfo, unsafe.Pointer(&info))
+	}
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// seqIoctl makes the request of the sequencer with the argument at arg. The file is kept out of blocking mode, which
+// Fd would put it in, so that closing it stops a read.
+func seqIoctl(f *os.File, req uintptr, arg unsafe.Pointer) error {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var errno syscall.Errno
+	if err := conn.Control(func(fd uintptr) {
+		_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, fd, req, uintptr(arg))
+	}); err != nil {
+		return err
+	}
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// seqPorts lists the ports of the other clients of the sequencer, other than the system ones, that can be
+// subscribed to.
+func seqPorts() ([]midiPort, error) {
+	f, err := openSeq(os.O_RDONLY)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var self int32
+	if err := seqIoctl(f, seqIoctlClientID, unsafe.Pointer(&self)); err != nil {
+		return nil, err
+	}
+
+	var ports []midiPort
+	client := seqClientInfo{client: -1}
+	for seqIoctl(f, seqIoctlQueryNextClient, unsafe.Pointer(&client)) == nil {
+		if client.client == seqClientSystem || client.client == self {
+			continue
+		}
+		port := seqPortInfo{addr: seqAddr{uint8(client.client), 0xff}}
+		for seqIoctl(f, seqIoctlQueryNextPort, unsafe.Pointer(&port)) == nil {
+			if port.capability&seqCapNoExport != 0 {
+				continue
+			}
+			p := midiPort{
+				name: fmt.Sprintf("%s: %s (%d:%d)",
+					cString(client.name[:]), cString(port.name[:]), port.addr.client, port.addr.port),
+				client: int(port.addr.client),
+				port:   int(port.addr.port),
+				input:  port.capability&(seqCapRead|seqCapSubsRead) == seqCapRead|seqCapSubsRead,
+				output: port.capability&(seqCapWrite|seqCapSubsWrite) == seqCapWrite|seqCapSubsWrite,
+			}
+			if p.input || p.output {
+				ports = append(ports, p)
+			}
+		}
+	}
+
+	return ports, nil
+}
+
+// newSeqClient opens the sequencer with flag as a client with a port of the capability, for the app to use as a
+// MIDI port.
+func newSeqClient(flag int, capability uint32) (*seqClient, error) {
+	f, err := openSeq(flag)
+	if err != nil {
+		return nil, err
+	}
+	info := seqPortInfo{
+		capability:   capability,
+		kind:         seqPortTypeMIDIGeneric | seqPortTypeApplication,
+		midiChannels: midiChannels,
+	}
+	copy(info.name[:len(info.name)-1], seqClientName)
+	if err := seqIoctl(f, seqIoctlCreatePort, unsafe.Pointer(&info)); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &seqClient{f: f, port: info.addr}, nil
+}
+
+// subscribe connects the port of sender to that of dest, one of which is the client's own.
+func (c *seqClient) subscribe(sender, dest seqAddr) error {
+	sub := seqPortSubscribe{sender: sender, dest: dest}
+	return seqIoctl(c.f, seqIoctlSubscribePort, unsafe.Pointer(&sub))
+}
+
+// openSeqIn connects the port to a new client that receives what it sends.
+func openSeqIn(p midiPort) (io.ReadCloser, error) {
+	c, err := newSeqClient(os.O_RDONLY, seqCapWrite|seqCapSubsWrite)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.subscribe(seqAddr{uint8(p.client), uint8(p.port)}, c.port); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// openSeqOut connects a new client to the port, to send it what is written.
+func openSeqOut(p midiPort) (io.WriteCloser, error) {
+	c, err := newSeqClient(os.O_WRONLY, seqCapRead|seqCapSubsRead)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.subscribe(c.port, seqAddr{uint8(p.client), uint8(p.port)}); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Write sends the channel messages of the MIDI bytes as events. Other messages are skipped.
+func (c *seqClient) Write(p []byte) (int, error) {
+	for i, b := range p {
+		msg, ok := c.parser.feed(b)
+		if !ok {
+			continue
+		}
+		if e, ok := midiSeqEvent(msg); ok {
+			if err := c.send(e); err != nil {
+				return i, err
+			}
+		}
+	}
+	return len(p), nil
+}
+
+// send sends the event from the client's port to the ports subscribed to it, without scheduling it.
+func (c *seqClient) send(e seqEvent) error {
+	e.queue = seqQueueDirect
+	e.source = c.port
+	e.dest = seqAddr{seqAddressSubscribers, seqAddressUnknown}
+	_, err := c.f.Write((*[unsafe.Sizeof(seqEvent{})]byte)(unsafe.Pointer(&e))[:])
+	return err
+}
+
+// Read returns the MIDI bytes of the channel events received. Other events are skipped.
+func (c *seqClient) Read(p []byte) (int, error) {
+	size := int(unsafe.Sizeof(seqEvent{}))
+	buf := make([]byte, 64*size)
+	for c.buf.Len() == 0 {
+		n, err := c.f.Read(buf)
+		for i := 0; i+size <= n; i += size {
+			var e seqEvent
+			copy((*[unsafe.Sizeof(seqEvent{})]byte)(unsafe.Pointer(&e))[:], buf[i:])
+			c.buf.Write(e.midi())
+			if e.flags&seqEventLengthMask == seqEventLengthVariable {
+				i += int(*(*uint32)(unsafe.Pointer(&e.data)) &^ seqExtMask)
+			}
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return c.buf.Read(p)
+}
+
+// Close disconnects the client from the sequencer, which removes its port and subscription.
+func (c *seqClient) Close() error {
+	return c.f.Close()
+}
+
+// midi returns the MIDI message of a channel event, or nothing for other events.
+func (e seqEvent) midi() []byte {
+	d := e.data
+	ch := d.channel & 0x0f
+	switch e.kind {
+	case seqEventNoteOn:
+		return []byte{noteOn | ch, d.note & 0x7f, d.velocity & 0x7f}
+	case seqEventNoteOff:
+		return []byte{noteOff | ch, d.note & 0x7f, d.offVelocity & 0x7f}
+	case seqEventKeyPress:
+		return []byte{polyPressure | ch, d.note & 0x7f, d.velocity & 0x7f}
+	case seqEventController:
+		return []byte{controlChange | ch, byte(d.param & 0x7f), byte(d.value & 0x7f)}
+	case seqEventPgmChange:
+		return []byte{programChange | ch, byte(d.value & 0x7f)}
+	case seqEventChanPress:
+		return []byte{channelPressure | ch, byte(d.value & 0x7f)}
+	case seqEventPitchBend:
+		v := d.value + 8192
+		return []byte{pitchBend | ch, byte(v & 0x7f), byte(v >> 7 & 0x7f)}
+	}
+	return nil
+}
+
+// midiSeqEvent returns the event of a MIDI channel message, or false if it is not one.
+func midiSeqEvent(msg []byte) (seqEvent, bool) {
+	if len(msg) < 2 || msg[0] < noteOff || msg[0] >= sysex || len(msg) != 1+messageLength(msg[0]) {
+		return seqEvent{}, false
+	}
+	d := seqEventData{channel: msg[0] & 0x0f}
+	var kind uint8
+	switch msg[0] & 0xf0 {
+	case noteOn:
+		kind, d.note, d.velocity = seqEventNoteOn, msg[1], msg[2]
+	case noteOff:
+		kind, d.note, d.offVelocity = seqEventNoteOff, msg[1], msg[2]
+	case polyPressure:
+		kind, d.note, d.velocity = seqEventKeyPress, msg[1], msg[2]
+	case controlChange:
+		kind, d.param, d.value = seqEventController, uint32(msg[1]), int32(msg[2])
+	case programChange:
+		kind, d.value = seqEventPgmChange, int32(msg[1])
+	case channelPressure:
+		kind, d.value = seqEventChanPress, int32(msg[1])
+	case pitchBend:
+		kind, d.value = seqEventPitchBend, int32(msg[1])|int32(msg[2])<<7-8192
+	}
+	return seqEvent{kind: kind, data: d}, true
+}
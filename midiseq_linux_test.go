@@ -0,0 +1,138 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeqLayout(t *testing.T) {
+	portInfo := uintptr(164)
+	if unsafe.Sizeof(uintptr(0)) == 8 {
+		portInfo = 168
+	}
+	assert.Equal(t, uintptr(188), unsafe.Sizeof(seqClientInfo{}))
+	assert.Equal(t, portInfo, unsafe.Sizeof(seqPortInfo{}))
+	assert.Equal(t, uintptr(80), unsafe.Sizeof(seqPortSubscribe{}))
+	assert.Equal(t, uintptr(28), unsafe.Sizeof(seqEvent{}))
+	assert.Equal(t, uintptr(16), unsafe.Offsetof(seqEvent{}.data))
+
+	assert.Equal(t, uintptr(0x80045301), seqIoctlClientID)
+	assert.Equal(t, uintptr(0xc0bc5351), seqIoctlQueryNextClient)
+	assert.Equal(t, uintptr(0x40505330), seqIoctlSubscribePort)
+}
+
+func TestSeqEventMIDI(t *testing.T) {
+	tests := []struct {
+		event seqEvent
+		want  []byte
+	}{
+		{seqEvent{kind: seqEventNoteOn, data: seqEventData{channel: 2, note: 60, velocity: 100}}, []byte{0x92, 60, 100}},
+		{seqEvent{kind: seqEventNoteOff, data: seqEventData{note: 60, offVelocity: 64}}, []byte{0x80, 60, 64}},
+		{seqEvent{kind: seqEventController, data: seqEventData{channel: 15, param: 64, value: 127}}, []byte{0xbf, 64, 127}},
+		{seqEvent{kind: seqEventPgmChange, data: seqEventData{value: 5}}, []byte{0xc0, 5}},
+		{seqEvent{kind: seqEventPitchBend, data: seqEventData{value: -8192}}, []byte{0xe0, 0, 0}},
+		{seqEvent{kind: seqEventPitchBend, data: seqEventData{value: 8191}}, []byte{0xe0, 0x7f, 0x7f}},
+		{seqEvent{kind: 66}, nil}, // a client starting
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, test.event.midi())
+	}
+}
+
+// TestSeqIn plays notes from a virtual port of another client of the sequencer, where there is one.
+func TestSeqIn(t *testing.T) {
+	sender, err := newSeqClient(os.O_WRONLY, seqCapRead|seqCapSubsRead)
+	if err != nil {
+		t.Skipf("No ALSA sequencer: %v", err)
+	}
+	defer sender.Close()
+
+	ports, err := midiPorts()
+	assert.NoError(t, err)
+	var port midiPort
+	for _, p := range ports {
+		if p.client == int(sender.port.client) && p.port == int(sender.port.port) {
+			port = p
+		}
+	}
+	if !assert.True(t, port.input, "virtual port not listed") {
+		return
+	}
+
+	in, err := openMIDIIn(port)
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, n := range []uint8{60, 64, 67} {
+		assert.NoError(t, sender.send(seqEvent{kind: seqEventNoteOn, data: seqEventData{note: n, velocity: 100}}))
+	}
+	assert.NoError(t, sender.send(seqEvent{kind: seqEventNoteOff, data: seqEventData{note: 64}}))
+
+	var notes []int
+	var ons []bool
+	_ = readMIDI(in, func(note int, on bool) {
+		notes, ons = append(notes, note), append(ons, on)
+		if len(notes) == 4 {
+			_ = in.Close()
+		}
+	})
+	assert.Equal(t, []int{60, 64, 67, 64}, notes)
+	assert.Equal(t, []bool{true, true, true, false}, ons)
+}
+
+func TestMIDISeqEvent(t *testing.T) {
+	for _, msg := range [][]byte{
+		{0x92, 60, 100}, {0x80, 60, 64}, {0xa1, 62, 30}, {0xbf, 64, 127}, {0xc0, 5}, {0xd3, 90},
+		{0xe0, 0, 0}, {0xe0, 0, 0x40}, {0xe0, 0x7f, 0x7f},
+	} {
+		e, ok := midiSeqEvent(msg)
+		assert.True(t, ok)
+		assert.Equal(t, msg, e.midi())
+	}
+	_, ok := midiSeqEvent([]byte{0xf8})
+	assert.False(t, ok)
+	_, ok = midiSeqEvent([]byte{0x90, 60})
+	assert.False(t, ok)
+}
+
+// TestSeqOut plays notes to a virtual port of another client of the sequencer, where there is one.
+func TestSeqOut(t *testing.T) {
+	receiver, err := newSeqClient(os.O_RDONLY, seqCapWrite|seqCapSubsWrite)
+	if err != nil {
+		t.Skipf("No ALSA sequencer: %v", err)
+	}
+
+	ports, err := midiPorts()
+	assert.NoError(t, err)
+	var port midiPort
+	for _, p := range ports {
+		if p.client == int(receiver.port.client) && p.port == int(receiver.port.port) {
+			port = p
+		}
+	}
+	if !assert.True(t, port.output, "virtual port not listed") {
+		return
+	}
+
+	w, err := openMIDIOut(port)
+	if !assert.NoError(t, err) {
+		return
+	}
+	o := newMIDIOutput(w)
+	o.play([]synthNote{{62, 0, 0.01}})
+	assert.NoError(t, o.close())
+
+	var got []byte
+	_ = readMIDI(receiver, func(note int, on bool) {
+		if on {
+			got = append(got, noteOn, byte(note))
+		} else {
+			got = append(got, noteOff, byte(note))
+			_ = receiver.Close()
+		}
+	})
+	assert.Equal(t, []byte{noteOn, 62, noteOff, 62}, got)
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	musicXMLHeader = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<!DOCTYPE score-partwise PUBLIC "-//Recordare//DTD MusicXML 4.0 Partwise//EN" "http://www.musicxml.org/dtds/partwise.dtd">
+`
+	quarterDivisions = 1 // MusicXML divisions of a quarter note
+	beatsPerBar      = 4
+)
+
+type (
+	xmlScore struct {
+		XMLName  xml.Name     `xml:"score-partwise"`
+		Version  string       `xml:"version,attr"`
+		Title    string       `xml:"work>work-title"`
+		Software string       `xml:"identification>encoding>software"`
+		Part     xmlScorePart `xml:"part-list>score-part"`
+		Parts    []xmlPart    `xml:"part"`
+	}
+
+	xmlScorePart struct {
+		ID   string `xml:"id,attr"`
+		Name string `xml:"part-name"`
+	}
+
+	xmlPart struct {
+		ID       string       `xml:"id,attr"`
+		Measures []xmlMeasure `xml:"measure"`
+	}
+
+	// xmlMeasure holds its directions, harmonies, notes and bar line in the order they are written.
+	xmlMeasure struct {
+		Number     int            `xml:"number,attr"`
+		Attributes *xmlAttributes `xml:"attributes,omitempty"`
+		Items      []interface{}
+	}
+
+	xmlAttributes struct {
+		Divisions int     `xml:"divisions"`
+		Key       *xmlKey `xml:"key,omitempty"`
+		Beats     int     `xml:"time>beats"`
+		BeatType  int     `xml:"time>beat-type"`
+		ClefSign  string  `xml:"clef>sign"`
+		ClefLine  int     `xml:"clef>line"`
+	}
+
+	xmlKey struct {
+		Fifths int    `xml:"fifths"`
+		Mode   string `xml:"mode"`
+	}
+
+	xmlDirection struct {
+		XMLName   xml.Name `xml:"direction"`
+		Placement string   `xml:"placement,attr"`
+		Rehearsal string   `xml:"direction-type>rehearsal"`
+	}
+
+	xmlHarmony struct {
+		XMLName   xml.Name `xml:"harmony"`
+		RootStep  string   `xml:"root>root-step"`
+		RootAlter *int     `xml:"root>root-alter,omitempty"`
+		Kind      xmlKind  `xml:"kind"`
+		Bass      *xmlBass `xml:"bass,omitempty"`
+	}
+
+	xmlKind struct {
+		Text  string `xml:"text,attr"`
+		Value string `xml:",chardata"`
+	}
+
+	xmlBass struct {
+		Step  string `xml:"bass-step"`
+		Alter *int   `xml:"bass-alter,omitempty"`
+	}
+
+	xmlNote struct {
+		XMLName    xml.Name  `xml:"note"`
+		Chord      *struct{} `xml:"chord,omitempty"`
+		Step       string    `xml:"pitch>step"`
+		Alter      *int      `xml:"pitch>alter,omitempty"`
+		Octave     int       `xml:"pitch>octave"`
+		Duration   int       `xml:"duration"`
+		Type       string    `xml:"type"`
+		Accidental string    `xml:"accidental,omitempty"`
+		Lyric      *xmlLyric `xml:"lyric,omitempty"`
+	}
+
+	xmlLyric struct {
+		Text string `xml:"text"`
+	}
+
+	xmlBarline struct {
+		XMLName  xml.Name `xml:"barline"`
+		Location string   `xml:"location,attr"`
+		Style    string   `xml:"bar-style"`
+	}
+)
+
+var (
+	// harmonyKinds are the MusicXML kinds of the chord suffixes. Other suffixes are written as kind other, with the
+	// suffix as the text to show.
+	harmonyKinds = map[string]string{
+		"":     "major",
+		"m":    "minor",
+		"°":    "diminished",
+		"+":    "augmented",
+		"sus2": "suspended-second",
+		"sus4": "suspended-fourth",
+		"7":    "dominant",
+		"M7":   "major-seventh",
+		"m7":   "minor-seventh",
+		"m7♭5": "half-diminished",
+		"°7":   "diminished-seventh",
+		"mM7":  "major-minor",
+		"6":    "major-sixth",
+		"m6":   "minor-sixth",
+		"9":    "dominant-ninth",
+		"M9":   "major-ninth",
+		"m9":   "minor-ninth",
+		"5":    "power",
+	}
+
+	accidentalNames = map[int]string{-2: "flat-flat", -1: "flat", 0: "natural", 1: "sharp", 2: "double-sharp"}
+)
+
+// optionalAlter returns a pointer to alter, or nil when there is no alteration to write.
+func optionalAlter(alter int) *int {
+	if alter == 0 {
+		return nil
+	}
+	return &alter
+}
+
+// newHarmony returns the chord symbol of the named chord, or false if the name has no root.
+func newHarmony(name string) (xmlHarmony, bool) {
+	root, suffix, bass, ok := splitChordName(name)
+	if !ok {
+		return xmlHarmony{}, false
+	}
+	step, alter, _ := parseNote(root)
+	kind, ok := harmonyKinds[suffix]
+	if !ok {
+		kind = "other"
+	}
+
+	h := xmlHarmony{
+		RootStep:  string(step),
+		RootAlter: optionalAlter(alter),
+		Kind:      xmlKind{Text: suffix, Value: kind},
+	}
+	if bass != "" {
+		step, alter, _ := parseNote(bass)
+		h.Bass = &xmlBass{Step: string(step), Alter: optionalAlter(alter)}
+	}
+
+	return h, true
+}
+
+// xmlNotes returns the notes of a chord, or of a single note, with the accidentals needed in the bar given the
+// alterations already in force.
+func xmlNotes(pitches []pitch, duration int, noteType string, lyric string, keySig keySignature,
+	altered map[int]int) []interface{} {
+	var notes []interface{}
+	for i, p := range pitches {
+		n := xmlNote{
+			Step:     string(p.step),
+			Alter:    optionalAlter(p.alter),
+			Octave:   p.octave,
+			Duration: duration,
+			Type:     noteType,
+		}
+		if i > 0 {
+			n.Chord = &struct{}{}
+		} else if lyric != "" {
+			n.Lyric = &xmlLyric{Text: lyric}
+		}
+
+		if keySig.needsAccidental(p, altered) {
+			n.Accidental = accidentalNames[p.alter]
+		}
+		notes = append(notes, n)
+	}
+
+	return notes
+}
+
+// writeMusicXML writes the sections as a MusicXML 4.0 partwise score for one treble staff in 4/4. The scale is written
+// in quarter notes and each chord as a whole note with its chord symbol and position. Every section starts with a
+// rehearsal mark and ends with a double bar line.
+func (m *model) writeMusicXML(w io.Writer, sections []scoreSection) error {
+	keySig := keySignatureFor(m.key, m.scale)
+	attributes := &xmlAttributes{
+		Divisions: quarterDivisions,
+		Beats:     beatsPerBar,
+		BeatType:  4,
+		ClefSign:  "G",
+		ClefLine:  2,
+	}
+	if keySig.valid() {
+		attributes.Key = &xmlKey{Fifths: int(keySig), Mode: strings.ToLower(m.scale)}
+	}
+
+	var measures []xmlMeasure
+	for i, sec := range sections {
+		first := len(measures)
+		newMeasure := func() *xmlMeasure {
+			measures = append(measures, xmlMeasure{Number: len(measures) + 1})
+			return &measures[len(measures)-1]
+		}
+
+		var altered map[int]int
+		for j, p := range sec.melody {
+			if j%beatsPerBar == 0 {
+				newMeasure()
+				altered = make(map[int]int)
+			}
+			bar := &measures[len(measures)-1]
+			bar.Items = append(bar.Items, xmlNotes([]pitch{p}, quarterDivisions, "quarter", "", keySig, altered)...)
+		}
+		for _, c := range sec.chords {
+			bar := newMeasure()
+			if h, ok := newHarmony(c.name); ok {
+				bar.Items = append(bar.Items, h)
+			}
+			bar.Items = append(bar.Items, xmlNotes(voiceNotes(c.notes, scoreOctave), beatsPerBar*quarterDivisions,
+				"whole", c.position, keySig, make(map[int]int))...)
+		}
+		if len(measures) == first {
+			continue
+		}
+
+		measures[first].Items = append([]interface{}{xmlDirection{Placement: "above", Rehearsal: sec.title}},
+			measures[first].Items...)
+		style := "light-light"
+		if i == len(sections)-1 {
+			style = "light-heavy"
+		}
+		last := &measures[len(measures)-1]
+		last.Items = append(last.Items, xmlBarline{Location: "right", Style: style})
+	}
+	if len(measures) > 0 {
+		measures[0].Attributes = attributes
+	}
+
+	score := xmlScore{
+		Version:  "4.0",
+		Title:    fmt.Sprintf("%s %s", m.key, m.scale),
+		Software: "Chords for Keys",
+		Part:     xmlScorePart{ID: "P1", Name: "Piano"},
+		Parts:    []xmlPart{{ID: "P1", Measures: measures}},
+	}
+	if _, err := io.WriteString(w, musicXMLHeader); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(score); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// showMusicXMLExport asks what to export and where to save it as MusicXML.
+func (m *model) showMusicXMLExport() {
+	m.showScoreExport("MusicXML", ".musicxml", m.writeMusicXML)
+}
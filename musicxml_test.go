@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readScore is the part of a MusicXML score checked by the tests.
+type readScore struct {
+	Measures []struct {
+		Fifths  *int `xml:"attributes>key>fifths"`
+		Harmony []struct {
+			Step  string `xml:"root>root-step"`
+			Alter int    `xml:"root>root-alter"`
+			Kind  string `xml:"kind"`
+			Bass  string `xml:"bass>bass-step"`
+		} `xml:"harmony"`
+		Notes []struct {
+			Step       string `xml:"pitch>step"`
+			Alter      int    `xml:"pitch>alter"`
+			Octave     int    `xml:"pitch>octave"`
+			Accidental string `xml:"accidental"`
+			Lyric      string `xml:"lyric>text"`
+		} `xml:"note"`
+	} `xml:"part>measure"`
+}
+
+func TestHarmony(t *testing.T) {
+	tests := []struct {
+		name string
+		step string
+		alt  *int
+		kind string
+		bass *xmlBass
+	}{
+		{"C", "C", nil, "major", nil},
+		{"F♯m7♭5", "F", optionalAlter(1), "half-diminished", nil},
+		{"B♭7", "B", optionalAlter(-1), "dominant", nil},
+		{"C/E", "C", nil, "major", &xmlBass{Step: "E"}},
+		{"E♭+M7", "E", optionalAlter(-1), "other", nil},
+	}
+
+	for _, e := range tests {
+		h, ok := newHarmony(e.name)
+		assert.True(t, ok)
+		assert.Equal(t, e.step, h.RootStep, e.name)
+		assert.Equal(t, e.alt, h.RootAlter, e.name)
+		assert.Equal(t, e.kind, h.Kind.Value, e.name)
+		assert.Equal(t, e.bass, h.Bass, e.name)
+	}
+
+	_, ok := newHarmony("?")
+	assert.False(t, ok)
+}
+
+func TestWriteMusicXML(t *testing.T) {
+	m := model{key: "C♯", scale: "Major", scaleIntervals: scaleIntervals["Major"]}
+	m.scaleNotes = enumerateScale(m.key, m.scaleIntervals)
+	m.progression = []chord{m.buildSecondaryDoms()[2], {name: "C", position: "♭II", notes: []string{"C", "E", "G"}}}
+
+	var b bytes.Buffer
+	assert.NoError(t, m.writeMusicXML(&b, m.scoreSections(exportAll)))
+	assert.True(t, strings.HasPrefix(b.String(), "<?xml"))
+	assert.Contains(t, b.String(), `<score-partwise version="4.0">`)
+
+	var score readScore
+	assert.NoError(t, xml.Unmarshal(b.Bytes(), &score))
+	if !assert.Equal(t, 2+7+7+6+6+1+2, len(score.Measures)) {
+		return
+	}
+	assert.Equal(t, optionalAlter(7), score.Measures[0].Fifths)
+
+	// The scale is spelled with E♯ and B♯, which need no accidentals in the key.
+	var scale []string
+	for _, bar := range score.Measures[:2] {
+		for _, n := range bar.Notes {
+			assert.Empty(t, n.Accidental)
+			scale = append(scale, pitch{step: n.Step[0], alter: n.Alter, octave: n.Octave}.String())
+		}
+	}
+	assert.Equal(t, []string{"C♯4", "D♯4", "E♯4", "F♯4", "G♯4", "A♯4", "B♯4", "C♯5"}, scale)
+
+	// The secondary dominant of IV, C♯7, has a B natural and the last chord only naturals.
+	sec := score.Measures[len(score.Measures)-2]
+	assert.Equal(t, "C", sec.Harmony[0].Step)
+	assert.Equal(t, 1, sec.Harmony[0].Alter)
+	assert.Equal(t, "dominant", sec.Harmony[0].Kind)
+	assert.Equal(t, "V⁷ / IV", sec.Notes[0].Lyric)
+	assert.Equal(t, []string{"", "", "", "natural"}, []string{sec.Notes[0].Accidental, sec.Notes[1].Accidental,
+		sec.Notes[2].Accidental, sec.Notes[3].Accidental})
+	last := score.Measures[len(score.Measures)-1]
+	for _, n := range last.Notes {
+		assert.Equal(t, "natural", n.Accidental)
+	}
+}
+
+func TestXMLNotesAccidentals(t *testing.T) {
+	altered := make(map[int]int)
+	var accidentals []string
+	for _, p := range []pitch{{'F', 2, 4}, {'F', 2, 4}, {'F', 1, 4}, {'C', -1, 5}, {'F', 1, 5}} {
+		n := xmlNotes([]pitch{p}, 1, "quarter", "", 2, altered)[0].(xmlNote)
+		accidentals = append(accidentals, n.Accidental)
+	}
+	assert.Equal(t, []string{"double-sharp", "", "sharp", "flat", ""}, accidentals)
+}
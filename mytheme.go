@@ -11,7 +11,12 @@ type myTheme struct{}
 
 var _ fyne.Theme = (*myTheme)(nil)
 
+// Font returns the bundled music font, which has the accidentals and chord symbols, except for monospaced text, which
+// is set in the default monospaced font so that it can be aligned in columns.
 func (*myTheme) Font(s fyne.TextStyle) fyne.Resource {
+	if s.Monospace {
+		return theme.DefaultTheme().Font(s)
+	}
 	return resourceNotoSansRegularMusicTtf
 }
 
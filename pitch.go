@@ -0,0 +1,123 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	sharp       = "♯"
+	flat        = "♭"
+	natural     = "♮"
+	doubleSharp = "𝄪"
+	doubleFlat  = "𝄫"
+
+	middleC = 60 // MIDI note number of C4
+)
+
+// pitch is a spelled pitch: a letter, an alteration and an octave in scientific pitch notation (C4 is middle C), so
+// that E♯ and F are kept apart even though they sound the same.
+type pitch struct {
+	step   byte // 'A' to 'G'
+	alter  int  // semitones, -2 to 2
+	octave int
+}
+
+var (
+	steps         = "CDEFGAB"
+	stepSemitones = map[byte]int{'C': 0, 'D': 2, 'E': 4, 'F': 5, 'G': 7, 'A': 9, 'B': 11}
+
+	accidentals = map[int]string{-2: doubleFlat, -1: flat, 1: sharp, 2: doubleSharp}
+
+	// asciiAccidentals replaces the accidentals of note names with their ASCII spellings, as in "F#" and "Bb".
+	asciiAccidentals = strings.NewReplacer(sharp, "#", flat, "b", doubleSharp, "##", doubleFlat, "bb")
+)
+
+// parseNote splits a note name such as "E♭" into its letter and alteration. ASCII "#" and "b" are accepted in place
+// of "♯" and "♭". Names with more than a double sharp or flat, such as "Cbbb", are rejected, as pitches cannot name
+// them.
+func parseNote(note string) (step byte, alter int, ok bool) {
+	if note == "" || !strings.ContainsRune(steps, rune(note[0])) {
+		return 0, 0, false
+	}
+
+	step = note[0]
+	rest := note[1:]
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, sharp):
+			alter++
+			rest = rest[len(sharp):]
+		case strings.HasPrefix(rest, flat):
+			alter--
+			rest = rest[len(flat):]
+		case strings.HasPrefix(rest, doubleSharp):
+			alter += 2
+			rest = rest[len(doubleSharp):]
+		case strings.HasPrefix(rest, doubleFlat):
+			alter -= 2
+			rest = rest[len(doubleFlat):]
+		case rest[0] == '#':
+			alter++
+			rest = rest[1:]
+		case rest[0] == 'b':
+			alter--
+			rest = rest[1:]
+		default:
+			return 0, 0, false
+		}
+	}
+	if alter < -2 || alter > 2 {
+		return 0, 0, false
+	}
+
+	return step, alter, true
+}
+
+// newPitch returns the pitch of the named note in the given octave.
+func newPitch(note string, octave int) (pitch, bool) {
+	step, alter, ok := parseNote(note)
+	return pitch{step, alter, octave}, ok
+}
+
+// midi returns the MIDI note number of p.
+func (p pitch) midi() int {
+	return (p.octave+1)*chromaticScaleLen + stepSemitones[p.step] + p.alter
+}
+
+// diatonic returns the number of letter steps from C0 to p, ignoring the alteration. It gives the vertical position
+// of p on a staff.
+func (p pitch) diatonic() int {
+	return p.octave*len(steps) + strings.IndexByte(steps, p.step)
+}
+
+// name returns the note name of p without the octave.
+func (p pitch) name() string {
+	return string(p.step) + accidentals[p.alter]
+}
+
+func (p pitch) String() string {
+	return p.name() + strconv.Itoa(p.octave)
+}
+
+// voiceNotes spells the notes upwards in close position: the first note is placed in the given octave and every
+// following note on the first pitch above the previous one.
+func voiceNotes(notes []string, octave int) []pitch {
+	var pitches []pitch
+	for _, n := range notes {
+		p, ok := newPitch(n, octave)
+		if !ok {
+			continue
+		}
+		if len(pitches) > 0 {
+			last := pitches[len(pitches)-1].midi()
+			p.octave = pitches[len(pitches)-1].octave - 1
+			for p.midi() <= last {
+				p.octave++
+			}
+		}
+		pitches = append(pitches, p)
+	}
+
+	return pitches
+}
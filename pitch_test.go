@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNote(t *testing.T) {
+	tests := []struct {
+		note  string
+		step  byte
+		alter int
+		ok    bool
+	}{
+		{"C", 'C', 0, true},
+		{"E♯", 'E', 1, true},
+		{"C♭", 'C', -1, true},
+		{"G#", 'G', 1, true},
+		{"Bb", 'B', -1, true},
+		{"F𝄪", 'F', 2, true},
+		{"B𝄫", 'B', -2, true},
+		{"H", 0, 0, false},
+		{"Cx", 0, 0, false},
+		{"Cbbb", 0, 0, false},
+		{"E###", 0, 0, false},
+		{"F𝄪♯", 0, 0, false},
+		{"", 0, 0, false},
+	}
+
+	for _, e := range tests {
+		step, alter, ok := parseNote(e.note)
+		assert.Equal(t, e.ok, ok, e.note)
+		assert.Equal(t, e.step, step, e.note)
+		assert.Equal(t, e.alter, alter, e.note)
+	}
+}
+
+func TestPitchMidi(t *testing.T) {
+	tests := []struct {
+		pitch pitch
+		midi  int
+	}{
+		{pitch{'C', 0, 4}, 60},
+		{pitch{'A', 0, 4}, 69},
+		{pitch{'B', 1, 3}, 60},
+		{pitch{'C', -1, 4}, 59},
+		{pitch{'E', 0, 2}, 40},
+	}
+
+	for _, e := range tests {
+		assert.Equal(t, e.midi, e.pitch.midi(), e.pitch.String())
+	}
+}
+
+func TestVoiceNotes(t *testing.T) {
+	tests := []struct {
+		notes   []string
+		octave  int
+		pitches []string
+	}{
+		{[]string{"C", "E", "G"}, 4, []string{"C4", "E4", "G4"}},
+		{[]string{"A", "C", "E", "G"}, 3, []string{"A3", "C4", "E4", "G4"}},
+		{[]string{"C♯", "E♯", "G♯", "B♯"}, 4, []string{"C♯4", "E♯4", "G♯4", "B♯4"}},
+		{[]string{"F", "A♭", "C♭"}, 3, []string{"F3", "A♭3", "C♭4"}},
+		{[]string{"G", "C"}, 4, []string{"G4", "C5"}},
+	}
+
+	for _, e := range tests {
+		var pitches []string
+		for _, p := range voiceNotes(e.notes, e.octave) {
+			pitches = append(pitches, p.String())
+		}
+		assert.Equal(t, e.pitches, pitches)
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// practiceLogFile keeps the answers to practice questions, one JSON object per line. Lines are only ever appended.
+const practiceLogFile = "practice.jsonl"
+
+type (
+	// practiceResult is the result of one answer to a practice question.
+	practiceResult struct {
+		time     time.Time
+		quiz     string
+		kind     string
+		key      string // key and scale the question was asked in, if any
+		scale    string
+		quality  string // of the chord asked about, if any
+		question string
+		answer   string
+		correct  bool
+		elapsed  time.Duration // from showing the question to the answer
+	}
+
+	// practiceRecord is a line of the practice log.
+	practiceRecord struct {
+		Time     time.Time `json:"time"`
+		Quiz     string    `json:"quiz"`
+		Kind     string    `json:"kind"`
+		Key      string    `json:"key,omitempty"`
+		Scale    string    `json:"scale,omitempty"`
+		Quality  string    `json:"quality,omitempty"`
+		Question string    `json:"question"`
+		Answer   string    `json:"answer"`
+		Correct  bool      `json:"correct"`
+		Seconds  float64   `json:"seconds"`
+	}
+)
+
+// configPath returns the path of a file kept between sessions in the user's configuration directory.
+func configPath(name string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "chords-for-keys", name), nil
+}
+
+func (r practiceResult) record() practiceRecord {
+	return practiceRecord{
+		Time: r.time, Quiz: r.quiz, Kind: r.kind, Key: r.key, Scale: r.scale, Quality: r.quality,
+		Question: r.question, Answer: strings.TrimSpace(r.answer), Correct: r.correct,
+		Seconds: math.Round(r.elapsed.Seconds()*1000) / 1000,
+	}
+}
+
+func (r practiceRecord) result() practiceResult {
+	return practiceResult{
+		time: r.Time, quiz: r.Quiz, kind: r.Kind, key: r.Key, scale: r.Scale, quality: r.Quality,
+		question: r.Question, answer: r.Answer, correct: r.Correct,
+		elapsed: time.Duration(r.Seconds * float64(time.Second)),
+	}
+}
+
+// appendPracticeLog adds the result to the end of the log at path, on a line of its own even if the last line was cut
+// short.
+func appendPracticeLog(path string, r practiceResult) error {
+	b, err := json.Marshal(r.record())
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			b = append([]byte{'\n'}, b...)
+		}
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readPracticeLog returns the results in the log at path, which may not exist yet. Lines that cannot be read, such as
+// one cut short when the app was stopped, are skipped and reported in the error along with the results of the others.
+func readPracticeLog(path string) ([]practiceResult, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var results []practiceResult
+	var bad []string
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var r practiceRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			bad = append(bad, fmt.Sprint(line))
+			continue
+		}
+		results = append(results, r.result())
+	}
+	if err := scanner.Err(); err != nil {
+		return results, err
+	}
+	if len(bad) > 0 {
+		return results, fmt.Errorf("%s: skipped unreadable lines %s", path, strings.Join(bad, ", "))
+	}
+	return results, nil
+}
+
+// buildPractice returns the practice quizzes, the history of their answers, which is read from the practice log, and
+// their statistics.
+func (m *model) buildPractice() fyne.CanvasObject {
+	path, err := configPath(practiceLogFile)
+	if err != nil {
+		fyne.LogError("Unable to find where to keep the practice log, answers last for this session", err)
+	}
+	if m.practiceLog = path; path != "" {
+		if m.history, err = readPracticeLog(path); err != nil {
+			fyne.LogError("Unable to read the practice log", err)
+		}
+	}
+
+	return container.NewAppTabs(
+		container.NewTabItem("Ear Training", m.buildEarTraining()),
+		container.NewTabItem("Flashcards", m.buildFlashcards()),
+		container.NewTabItem("Quiz", m.buildQuiz()),
+		container.NewTabItem("History", m.buildHistory()),
+		container.NewTabItem("Statistics", m.buildStatistics()),
+	)
+}
+
+// recordPractice adds the result of an answer to the history and the practice log.
+func (m *model) recordPractice(r practiceResult) {
+	m.history = append(m.history, r)
+	if m.practiceLog != "" {
+		if err := appendPracticeLog(m.practiceLog, r); err != nil {
+			fyne.LogError("Unable to write the practice log", err)
+		}
+	}
+	if m.historyList != nil {
+		m.historyList.Refresh()
+	}
+	m.refreshStatistics()
+}
+
+// buildHistory returns the list of the answers to practice questions, the latest first.
+func (m *model) buildHistory() fyne.CanvasObject {
+	m.historyList = widget.NewList(
+		func() int { return len(m.history) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(m.history[len(m.history)-1-id].describe())
+		},
+	)
+	return m.historyList
+}
+
+// describe summarizes the result for the history, such as
+// "Oct 19 09:12  Key Signature: How many sharps in E Major? 4, right in 2.3 s".
+func (r practiceResult) describe() string {
+	grade := "wrong"
+	if r.correct {
+		grade = "right"
+	}
+	return fmt.Sprintf("%s  %s: %s %s, %s in %.1f s", r.time.Format("Jan 2 15:04"), r.kind, r.question,
+		strings.TrimSpace(r.answer), grade, r.elapsed.Seconds())
+}
+
+// timingStats describes the accuracy and answer times of the results of the quiz by kind, in the order of kinds.
+func timingStats(results []practiceResult, quiz string, kinds []string) string {
+	type counts struct {
+		right, total int
+		times        []time.Duration
+	}
+	byKind := map[string]*counts{}
+	for _, r := range results {
+		if r.quiz != quiz {
+			continue
+		}
+		c, ok := byKind[r.kind]
+		if !ok {
+			c = &counts{}
+			byKind[r.kind] = c
+		}
+		c.total++
+		if r.correct {
+			c.right++
+			c.times = append(c.times, r.elapsed)
+		}
+	}
+
+	var lines []string
+	for _, k := range kinds {
+		c, ok := byKind[k]
+		if !ok {
+			continue
+		}
+		line := fmt.Sprintf("%s: %d of %d right", k, c.right, c.total)
+		if len(c.times) > 0 {
+			sort.Slice(c.times, func(i, j int) bool { return c.times[i] < c.times[j] })
+			var sum time.Duration
+			for _, t := range c.times {
+				sum += t
+			}
+			line += fmt.Sprintf(", %.1f s average, %.1f s best", (sum / time.Duration(len(c.times))).Seconds(),
+				c.times[0].Seconds())
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return "No answers yet"
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimingStats(t *testing.T) {
+	results := []practiceResult{
+		{quiz: theoryQuiz, kind: quizSignature, correct: true, elapsed: 2 * time.Second},
+		{quiz: theoryQuiz, kind: quizSignature, correct: true, elapsed: 4 * time.Second},
+		{quiz: theoryQuiz, kind: quizSignature, elapsed: time.Second},
+		{quiz: theoryQuiz, kind: quizScaleDegree, elapsed: time.Second},
+		{quiz: earTrainingQuiz, kind: earTriad, correct: true, elapsed: time.Second},
+	}
+	assert.Equal(t, "Scale Degree: 0 of 1 right\nKey Signature: 2 of 3 right, 3.0 s average, 2.0 s best",
+		timingStats(results, theoryQuiz, quizKinds))
+	assert.Equal(t, "No answers yet", timingStats(results, flashcardsQuiz, quizKinds))
+}
+
+func TestHistory(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	m := sheetModel("C", "Major")
+	list := m.buildHistory().(*widget.List)
+	at := time.Date(2026, 10, 19, 9, 12, 0, 0, time.Local)
+	m.recordPractice(practiceResult{
+		time: at, quiz: theoryQuiz, kind: quizSignature, question: "How many sharps in E Major?", answer: "4",
+		correct: true, elapsed: 2300 * time.Millisecond,
+	})
+	m.recordPractice(practiceResult{
+		time: at.Add(time.Minute), quiz: flashcardsQuiz, kind: flashcardName, question: "F♯m", answer: " G♭ A C♯",
+		elapsed: 5 * time.Second,
+	})
+	assert.Equal(t, 2, list.Length())
+
+	label := list.CreateItem()
+	list.UpdateItem(0, label)
+	assert.Equal(t, "Oct 19 09:13  Chord Name: F♯m G♭ A C♯, wrong in 5.0 s", label.(*widget.Label).Text)
+	list.UpdateItem(1, label)
+	assert.Equal(t, "Oct 19 09:12  Key Signature: How many sharps in E Major? 4, right in 2.3 s",
+		label.(*widget.Label).Text)
+}
+
+func TestPracticeLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chords-for-keys", practiceLogFile)
+	results, err := readPracticeLog(path)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+
+	at := time.Date(2026, 10, 19, 9, 12, 0, 0, time.UTC)
+	written := []practiceResult{
+		{time: at, quiz: earTrainingQuiz, kind: earSeventh, key: "E♭", scale: "Minor", quality: "minor seventh",
+			question: "Which seventh of E♭ Minor is this?", answer: "I⁷", correct: true, elapsed: 1500 * time.Millisecond},
+		{time: at.Add(time.Minute), quiz: theoryQuiz, kind: quizSignatureKey, key: "A♭", scale: "Major",
+			question: "Which major key has 4 flats?", answer: "E♭", elapsed: 3 * time.Second},
+	}
+	for _, r := range written {
+		assert.NoError(t, appendPracticeLog(path, r))
+	}
+	results, err = readPracticeLog(path)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	for i, r := range results {
+		assert.True(t, written[i].time.Equal(r.time))
+		r.time = written[i].time
+		assert.Equal(t, written[i], r)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	assert.NoError(t, err)
+	_, err = f.WriteString(`{"time":"2026-10-19T09:14:00Z","quiz":"Qu`)
+	assert.NoError(t, f.Close())
+	assert.NoError(t, err)
+	assert.NoError(t, appendPracticeLog(path, written[0]))
+
+	results, err = readPracticeLog(path)
+	assert.EqualError(t, err, path+": skipped unreadable lines 3")
+	assert.Len(t, results, 3, "the line appended after a cut one starts a line of its own")
+}
+
+func TestRecordPractice(t *testing.T) {
+	m := sheetModel("C", "Major")
+	m.practiceLog = filepath.Join(t.TempDir(), practiceLogFile)
+	m.recordPractice(practiceResult{time: time.Now(), quiz: theoryQuiz, kind: quizScaleDegree, correct: true})
+	m.recordPractice(practiceResult{time: time.Now(), quiz: theoryQuiz, kind: quizScaleDegree})
+	assert.Len(t, m.history, 2)
+
+	results, err := readPracticeLog(m.practiceLog)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.False(t, results[1].correct)
+}
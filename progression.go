@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// buildProgression returns the progression builder. Chords are added from the chord selected on the other tabs and
+// are kept when the key changes, so progressions may modulate. Below them the progression is voiced in four parts.
+func (m *model) buildProgression() fyne.CanvasObject {
+	m.progressionGrid = container.NewGridWithColumns(8)
+
+	add := widget.NewButton("Add Selected Chord", func() {
+		if m.selected == nil {
+			return
+		}
+		m.progression = append(m.progression, *m.selected)
+		m.refreshProgression()
+	})
+	removeLast := widget.NewButton("Remove Last", func() {
+		if len(m.progression) == 0 {
+			return
+		}
+		m.progression = m.progression[:len(m.progression)-1]
+		m.refreshProgression()
+	})
+	clear := widget.NewButton("Clear", func() {
+		m.progression = nil
+		m.refreshProgression()
+	})
+	play := widget.NewButton("Play", m.playProgression)
+
+	return container.NewVBox(
+		container.NewHBox(add, removeLast, clear, layout.NewSpacer(), play),
+		m.progressionGrid,
+		m.buildVoiceLeading(),
+	)
+}
+
+func (m *model) refreshProgression() {
+	m.fillChordGrid(m.progression, m.progressionGrid)
+	m.refreshVoiceLeading()
+}
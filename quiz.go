@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	theoryQuiz = "Quiz"
+
+	// The kinds of quiz questions.
+	quizAll          = "All"
+	quizScaleDegree  = "Scale Degree"
+	quizSignature    = "Key Signature"
+	quizSignatureKey = "Key from Signature"
+)
+
+// quizQuestion is a question of the quiz with the answer to pick among the choices.
+type quizQuestion struct {
+	kind    string
+	key     string
+	scale   string
+	prompt  string
+	answer  string
+	choices []string
+}
+
+var (
+	quizKinds = []string{quizScaleDegree, quizSignature, quizSignatureKey}
+
+	// degreeNames are the ordinal numbers of the degrees of a scale.
+	degreeNames = []string{"1st", "2nd", "3rd", "4th", "5th", "6th", "7th"}
+
+	quizRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// quizKeys returns the keys of keyNames in the scale whose signature can be written, which leaves out keys such as
+// D♯ major.
+func quizKeys(scale string) []string {
+	var keys []string
+	for _, key := range keyNames {
+		m, err := keyModel(key, scale)
+		if err == nil && spelledWithLetters(m.scaleNotes) && keySignatureFor(key, scale).valid() {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// newQuizQuestion returns a random question of the kind in a random key.
+func newQuizQuestion(kind string, r *rand.Rand) quizQuestion {
+	if kind == quizAll {
+		kind = quizKinds[r.Intn(len(quizKinds))]
+	}
+	scale := scaleNames[r.Intn(len(scaleNames))]
+	keys := quizKeys(scale)
+	key := keys[r.Intn(len(keys))]
+	q := quizQuestion{kind: kind, key: key, scale: scale}
+
+	switch kind {
+	case quizScaleDegree:
+		notes := enumerateScale(key, scaleIntervals[scale])
+		degree := 1 + r.Intn(len(notes)-1)
+		q.prompt = fmt.Sprintf("What is the %s degree of %s %s?", degreeNames[degree], key, scale)
+		q.answer = notes[degree]
+		q.choices = degreeChoices(notes, degree)
+		r.Shuffle(len(q.choices), func(i, j int) { q.choices[i], q.choices[j] = q.choices[j], q.choices[i] })
+
+	case quizSignature:
+		k := keySignatureFor(key, scale)
+		accidentals, count := "sharps", k.sharps()
+		if k < 0 || (k == 0 && r.Intn(2) == 0) {
+			accidentals, count = "flats", k.flats()
+		}
+		q.prompt = fmt.Sprintf("How many %s in %s %s?", accidentals, key, scale)
+		q.answer = strconv.Itoa(count)
+		for i := 0; i <= len(steps); i++ {
+			q.choices = append(q.choices, strconv.Itoa(i))
+		}
+
+	case quizSignatureKey:
+		count, _, _ := strings.Cut(keySignatureFor(key, scale).describe(), ":")
+		q.prompt = fmt.Sprintf("Which %s key has %s?", strings.ToLower(scale), count)
+		q.answer = key
+		q.choices = keys
+	}
+	return q
+}
+
+// degreeChoices returns the choices for a degree of the scale: its letter flat, natural and sharp, and the degrees
+// on either side.
+func degreeChoices(notes []string, degree int) []string {
+	var choices []string
+	for alter := -1; alter <= 1; alter++ {
+		choices = append(choices, pitch{step: notes[degree][0], alter: alter}.name())
+	}
+	for _, n := range []string{notes[degree-1], notes[(degree+1)%len(notes)]} {
+		if !contains(choices, n) {
+			choices = append(choices, n)
+		}
+	}
+	return choices
+}
+
+func (m *model) buildQuiz() fyne.CanvasObject {
+	m.quizKind = quizAll
+	m.quizPrompt = widget.NewLabelWithStyle("", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+	m.quizFeedback = widget.NewLabel("")
+	m.quizStats = widget.NewLabel("")
+	m.quizChoices = container.NewGridWithColumns(4)
+
+	kindSelector := widget.NewSelect(append([]string{quizAll}, quizKinds...), func(kind string) {
+		m.quizKind = kind
+		m.quizFeedback.SetText("")
+		m.nextQuizQuestion()
+	})
+	kindSelector.SetSelected(quizAll)
+
+	return container.NewVBox(
+		container.NewHBox(
+			widget.NewLabel("Questions"),
+			kindSelector,
+			layout.NewSpacer(),
+			widget.NewButton("Skip", m.nextQuizQuestion),
+		),
+		m.quizPrompt,
+		m.quizChoices,
+		m.quizFeedback,
+		widget.NewCard("", "Timing", m.quizStats),
+	)
+}
+
+// nextQuizQuestion asks a new question and starts timing the answer.
+func (m *model) nextQuizQuestion() {
+	q := newQuizQuestion(m.quizKind, quizRand)
+	m.quizQuestion = &q
+	m.quizAsked = time.Now()
+
+	m.quizPrompt.SetText(q.prompt)
+	m.quizChoices.RemoveAll()
+	for _, c := range q.choices {
+		c := c
+		m.quizChoices.Add(widget.NewButton(c, func() { m.answerQuiz(c) }))
+	}
+	m.quizStats.SetText(timingStats(m.history, theoryQuiz, quizKinds))
+}
+
+// answerQuiz records the answer, shows whether it was right and asks the next question straight away.
+func (m *model) answerQuiz(answer string) {
+	q := m.quizQuestion
+	if q == nil {
+		return
+	}
+	now := time.Now()
+	r := practiceResult{
+		time: now, quiz: theoryQuiz, kind: q.kind, key: q.key, scale: q.scale, question: q.prompt, answer: answer,
+		correct: answer == q.answer, elapsed: now.Sub(m.quizAsked),
+	}
+	m.recordPractice(r)
+
+	if r.correct {
+		m.quizFeedback.SetText(fmt.Sprintf("Right in %.1f s: %s %s", r.elapsed.Seconds(), q.prompt, q.answer))
+	} else {
+		m.quizFeedback.SetText(fmt.Sprintf("Not %s: %s %s", answer, q.prompt, q.answer))
+	}
+	m.nextQuizQuestion()
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuizKeys(t *testing.T) {
+	assert.Equal(t, []string{"C", "C♯", "D♭", "D", "E♭", "E", "F", "F♯", "G♭", "G", "A♭", "A", "B♭", "B"},
+		quizKeys("Major"))
+	assert.Equal(t, []string{"C", "C♯", "D", "D♯", "E♭", "E", "F", "F♯", "G", "G♯", "A♭", "A", "A♯", "B♭", "B"},
+		quizKeys("Minor"))
+}
+
+func TestQuizQuestion(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	seen := map[string]bool{}
+	for i := 0; i < 300; i++ {
+		q := newQuizQuestion(quizAll, r)
+		seen[q.kind] = true
+		assert.Contains(t, q.choices, q.answer, q.prompt)
+		switch q.kind {
+		case quizScaleDegree:
+			assert.GreaterOrEqual(t, len(q.choices), 4, q.prompt)
+		case quizSignature:
+			n, err := strconv.Atoi(q.answer)
+			assert.NoError(t, err)
+			assert.LessOrEqual(t, n, 7)
+		case quizSignatureKey:
+			assert.True(t, strings.HasPrefix(q.prompt, "Which "), q.prompt)
+		}
+	}
+	assert.Len(t, seen, len(quizKinds))
+}
+
+func TestQuizAnswers(t *testing.T) {
+	tests := []struct {
+		kind   string
+		prompt string
+		answer string
+	}{
+		{quizScaleDegree, "What is the 6th degree of B♭ Minor?", "G♭"},
+		{quizScaleDegree, "What is the 7th degree of F♯ Major?", "E♯"},
+		{quizSignature, "How many sharps in E Major?", "4"},
+		{quizSignature, "How many flats in C Minor?", "3"},
+		{quizSignatureKey, "Which major key has 4 flats?", "A♭"},
+		{quizSignatureKey, "Which minor key has 6 sharps?", "D♯"},
+		{quizSignatureKey, "Which major key has no sharps or flats?", "C"},
+	}
+	for _, tt := range tests {
+		r := rand.New(rand.NewSource(1))
+		found := false
+		for i := 0; i < 10000 && !found; i++ {
+			q := newQuizQuestion(tt.kind, r)
+			if found = q.prompt == tt.prompt; found {
+				assert.Equal(t, tt.answer, q.answer, tt.prompt)
+			}
+		}
+		assert.True(t, found, tt.prompt)
+	}
+}
+
+func TestDegreeChoices(t *testing.T) {
+	notes := enumerateScale("B♭", scaleIntervals["Minor"])
+	assert.Equal(t, []string{"G♭", "G", "G♯", "F", "A♭"}, degreeChoices(notes, 5))
+	assert.Equal(t, []string{"C♭", "C", "C♯", "B♭", "D♭"}, degreeChoices(notes, 1))
+	assert.Equal(t, []string{"A♭", "A", "A♯", "G♭", "B♭"}, degreeChoices(notes, 6), "the 1st degree follows the 7th")
+}
+
+func TestQuiz(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	m := sheetModel("C", "Major")
+	m.buildQuiz()
+	q := *m.quizQuestion
+	assert.Equal(t, q.prompt, m.quizPrompt.Text)
+	assert.Len(t, m.quizChoices.Objects, len(q.choices))
+	assert.Equal(t, "No answers yet", m.quizStats.Text)
+
+	for _, o := range m.quizChoices.Objects {
+		if b := o.(*widget.Button); b.Text == q.answer {
+			test.Tap(b)
+			break
+		}
+	}
+	assert.True(t, strings.HasPrefix(m.quizFeedback.Text, "Right in "), m.quizFeedback.Text)
+	assert.Len(t, m.history, 1)
+	assert.Equal(t, theoryQuiz, m.history[0].quiz)
+	assert.Equal(t, q.answer, m.history[0].answer)
+	assert.NotSame(t, &q, m.quizQuestion, "the next question is asked straight away")
+	assert.Contains(t, m.quizStats.Text, q.kind+": 1 of 1 right")
+
+	m.answerQuiz("none")
+	assert.True(t, strings.HasPrefix(m.quizFeedback.Text, "Not none: "), m.quizFeedback.Text)
+	assert.False(t, m.history[1].correct)
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"fyne.io/fyne/v2/dialog"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	formatText = "text"
+	formatJSON = "json"
+	formatYAML = "yaml"
+
+	// schemaVersion is the version of keyData. It is raised whenever a field is removed, renamed or changes meaning;
+	// fields may be added without raising it. schema.json documents it and must be kept in step.
+	schemaVersion = 1
+)
+
+var formats = []string{formatText, formatJSON, formatYAML}
+
+// keySchema is the JSON Schema of keyData, for those who read the JSON and YAML output.
+//
+//go:embed schema.json
+var keySchema []byte
+
+type (
+	// keyData is the machine-readable form of a key and scale and their chord sections, as written in JSON and YAML.
+	// Note names use the music symbols ♯, ♭, 𝄪 and 𝄫, and chord names and positions use the same symbols as the
+	// window, such as ° and ⁷.
+	keyData struct {
+		Version    int           `json:"version" yaml:"version"`       // schemaVersion
+		Key        string        `json:"key" yaml:"key"`               // tonic, such as "E♭"
+		Scale      string        `json:"scale" yaml:"scale"`           // "Major" or "Minor"
+		ScaleNotes []string      `json:"scaleNotes" yaml:"scaleNotes"` // the seven notes of the scale from the tonic
+		Sections   []sectionData `json:"sections" yaml:"sections"`     // in the order of the window
+	}
+
+	// sectionData is one chord section, such as the triads.
+	sectionData struct {
+		ID     string      `json:"id" yaml:"id"`         // as in the --sections flag, such as "secondary-dominants"
+		Title  string      `json:"title" yaml:"title"`   // as in the window, such as "Secondary Dominants"
+		Chords []chordData `json:"chords" yaml:"chords"` // in the order of the window
+	}
+
+	// chordData is one chord of a section, or a chord that was parsed or identified.
+	chordData struct {
+		Name string `json:"name" yaml:"name"` // such as "Fm7♭5" or "D7/C"
+		// Roman numeral of the chord in its section, such as "II⁷" or "V⁷ / IV", left out for chords outside one
+		Position string `json:"position,omitempty" yaml:"position,omitempty"`
+		Root     string `json:"root" yaml:"root"` // such as "F"
+		// bass of a slash chord, such as "C" in "D7/C", left out for other chords
+		Bass         string   `json:"bass,omitempty" yaml:"bass,omitempty"`
+		Quality      string   `json:"quality" yaml:"quality"`           // see qualityNames
+		Notes        []string `json:"notes" yaml:"notes"`               // from the root up
+		PitchClasses []int    `json:"pitchClasses" yaml:"pitchClasses"` // of the notes, with C as 0
+		Intervals    []int    `json:"intervals" yaml:"intervals"`       // semitones of the notes above the root
+	}
+)
+
+// qualityNames are the names that chordData gives the qualities of chordQualities, by suffix.
+var qualityNames = map[string]string{
+	"":      "major",
+	"m":     "minor",
+	"°":     "diminished",
+	"+":     "augmented",
+	"sus4":  "suspended fourth",
+	"sus2":  "suspended second",
+	"7":     "dominant seventh",
+	"M7":    "major seventh",
+	"m7":    "minor seventh",
+	"m7♭5":  "half-diminished seventh",
+	"°7":    "diminished seventh",
+	"mM7":   "minor major seventh",
+	"+M7":   "augmented major seventh",
+	"7sus4": "dominant seventh suspended fourth",
+	"6":     "major sixth",
+	"m6":    "minor sixth",
+	"add9":  "added ninth",
+	"9":     "dominant ninth",
+	"M9":    "major ninth",
+	"m9":    "minor ninth",
+	"5":     "power",
+}
+
+// sectionFlags returns the names of all the chord sections as used by the --sections flag.
+func sectionFlags() []string {
+	var flags []string
+	for _, sec := range (&model{}).chordSections() {
+		flags = append(flags, sectionFlag(sec.title))
+	}
+	return flags
+}
+
+// keyModel returns a model of the key, which may use ASCII accidentals, and the scale.
+func keyModel(key, scale string) (*model, error) {
+	name, ok := keyName(key)
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", key)
+	}
+	intervals, ok := scaleIntervals[scale]
+	if !ok {
+		return nil, fmt.Errorf("unknown scale %q", scale)
+	}
+	m := &model{key: name, scale: scale, scaleIntervals: intervals}
+	m.scaleNotes = enumerateScale(m.key, m.scaleIntervals)
+	return m, nil
+}
+
+// parseSections returns the chord sections of a comma-separated list of their flags.
+func parseSections(s string) ([]string, error) {
+	flags := sectionFlags()
+	var sections []string
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.ToLower(strings.TrimSpace(f)); f == "" {
+			continue
+		}
+		if !contains(flags, f) {
+			return nil, fmt.Errorf("unknown section %q, expected one of %s", f, strings.Join(flags, ", "))
+		}
+		sections = append(sections, f)
+	}
+	return sections, nil
+}
+
+// keyData returns the key, the scale and the chord sections named by their flags in machine-readable form.
+func (m *model) keyData(sections []string) keyData {
+	d := keyData{
+		Version:    schemaVersion,
+		Key:        m.key,
+		Scale:      m.scale,
+		ScaleNotes: m.scaleNotes,
+		Sections:   []sectionData{},
+	}
+	for _, sec := range m.chordSections() {
+		id := sectionFlag(sec.title)
+		if !contains(sections, id) {
+			continue
+		}
+		s := sectionData{ID: id, Title: sec.title, Chords: []chordData{}}
+		for _, c := range sec.chords {
+			s.Chords = append(s.Chords, newChordData(c))
+		}
+		d.Sections = append(d.Sections, s)
+	}
+	return d
+}
+
+func newChordData(c chord) chordData {
+	root, suffix, bass, _ := splitChordName(c.name)
+	d := chordData{
+		Name:         c.name,
+		Position:     c.position,
+		Root:         root,
+		Bass:         bass,
+		Quality:      qualityNames[suffix],
+		Notes:        c.notes,
+		PitchClasses: []int{},
+		Intervals:    []int{},
+	}
+	for _, n := range c.notes {
+		pc := notePitchClass(n)
+		d.PitchClasses = append(d.PitchClasses, pc)
+		d.Intervals = append(d.Intervals, pitchClass(pc-notePitchClass(root)))
+	}
+	return d
+}
+
+// writeJSON writes the data as indented JSON.
+func writeJSON(w io.Writer, d keyData) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(d)
+}
+
+// writeYAML writes the data as YAML.
+func writeYAML(w io.Writer, d keyData) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(d); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// copyJSON copies all the chord sections of the key and scale to the clipboard as JSON.
+func (m *model) copyJSON() {
+	var b strings.Builder
+	if err := writeJSON(&b, m.keyData(sectionFlags())); err != nil {
+		dialog.ShowError(err, m.window)
+		return
+	}
+	m.window.Clipboard().SetContent(b.String())
+}
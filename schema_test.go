@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestKeyData(t *testing.T) {
+	m := sheetModel("E♭", "Minor")
+	d := m.keyData([]string{"sevenths", "tritone-substitution"})
+
+	assert.Equal(t, schemaVersion, d.Version)
+	assert.Equal(t, "E♭", d.Key)
+	assert.Equal(t, "Minor", d.Scale)
+	assert.Equal(t, []string{"E♭", "F", "G♭", "A♭", "B♭", "C♭", "D♭"}, d.ScaleNotes)
+	if assert.Len(t, d.Sections, 2) {
+		assert.Equal(t, "sevenths", d.Sections[0].ID)
+		assert.Equal(t, "Sevenths", d.Sections[0].Title)
+		assert.Equal(t, chordData{
+			Name:         "Fm7♭5",
+			Position:     "II⁷",
+			Root:         "F",
+			Quality:      "half-diminished seventh",
+			Notes:        []string{"F", "A♭", "C♭", "E♭"},
+			PitchClasses: []int{5, 8, 11, 3},
+			Intervals:    []int{0, 3, 6, 10},
+		}, d.Sections[0].Chords[1])
+		assert.Equal(t, "tritone-substitution", d.Sections[1].ID)
+		assert.Equal(t, "dominant seventh", d.Sections[1].Chords[0].Quality)
+	}
+}
+
+func TestKeyDataQualities(t *testing.T) {
+	for _, key := range keyNames {
+		for _, scale := range scaleNames {
+			for _, s := range sheetModel(key, scale).keyData(sectionFlags()).Sections {
+				for _, c := range s.Chords {
+					assert.NotEmpty(t, c.Quality, "%s %s %s %s", key, scale, s.Title, c.Name)
+				}
+			}
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	d := sheetModel("D", "Major").keyData(sectionFlags())
+	var b strings.Builder
+	if assert.NoError(t, writeJSON(&b, d)) {
+		assert.Contains(t, b.String(), `"version": 1,`)
+		assert.Contains(t, b.String(), `"name": "F♯m"`)
+
+		var got keyData
+		assert.NoError(t, json.Unmarshal([]byte(b.String()), &got))
+		assert.Equal(t, d, got)
+	}
+}
+
+func TestWriteYAML(t *testing.T) {
+	d := sheetModel("B♭", "Minor").keyData(sectionFlags())
+	var b strings.Builder
+	if assert.NoError(t, writeYAML(&b, d)) {
+		assert.True(t, strings.HasPrefix(b.String(), "version: 1\n"))
+
+		var got keyData
+		assert.NoError(t, yaml.Unmarshal([]byte(b.String()), &got))
+		assert.Equal(t, d, got)
+	}
+}
+
+// TestKeySchema checks that schema.json describes the fields of keyData and the values they take.
+func TestKeySchema(t *testing.T) {
+	type object struct {
+		Required   []string                   `json:"required"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	var schema struct {
+		object
+		Defs map[string]object `json:"$defs"`
+	}
+	if !assert.NoError(t, json.Unmarshal(keySchema, &schema)) {
+		return
+	}
+
+	for _, e := range []struct {
+		object object
+		value  interface{}
+	}{
+		{schema.object, keyData{}},
+		{schema.Defs["section"], sectionData{}},
+		{schema.Defs["chord"], chordData{}},
+	} {
+		var names, required []string
+		typ := reflect.TypeOf(e.value)
+		for i := 0; i < typ.NumField(); i++ {
+			tag := strings.Split(typ.Field(i).Tag.Get("json"), ",")
+			names = append(names, tag[0])
+			if len(tag) == 1 {
+				required = append(required, tag[0])
+			}
+		}
+		var properties []string
+		for name := range e.object.Properties {
+			properties = append(properties, name)
+		}
+		assert.ElementsMatch(t, names, properties, typ.Name())
+		assert.Equal(t, required, e.object.Required, typ.Name())
+	}
+
+	var values struct {
+		Const int      `json:"const"`
+		Enum  []string `json:"enum"`
+	}
+	assert.NoError(t, json.Unmarshal(schema.Properties["version"], &values))
+	assert.Equal(t, schemaVersion, values.Const)
+	assert.NoError(t, json.Unmarshal(schema.Properties["scale"], &values))
+	assert.Equal(t, scaleNames, values.Enum)
+	assert.NoError(t, json.Unmarshal(schema.Defs["section"].Properties["id"], &values))
+	assert.Equal(t, sectionFlags(), values.Enum)
+	assert.NoError(t, json.Unmarshal(schema.Defs["chord"].Properties["quality"], &values))
+	var qualities []string
+	for _, q := range qualityNames {
+		qualities = append(qualities, q)
+	}
+	assert.ElementsMatch(t, qualities, values.Enum)
+}
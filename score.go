@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	exportAll = "All"
+
+	scoreOctave = 4 // octave of the scale tonic and chord roots in exported scores
+)
+
+// scoreSection is a titled part of an exported score: the scale as a melody, or chords one to a bar.
+type scoreSection struct {
+	title  string
+	melody []pitch
+	chords []chord
+}
+
+// scoreSections returns the sections of an export item: the scale, up to the tonic an octave higher, a chord section,
+// the progression or all of them.
+func (m *model) scoreSections(item string) []scoreSection {
+	scale := scoreSection{
+		title:  exportScale,
+		melody: voiceNotes(append(append([]string{}, m.scaleNotes...), m.scaleNotes[0]), scoreOctave),
+	}
+	switch item {
+	case exportScale:
+		return []scoreSection{scale}
+	case exportAll:
+		sections := []scoreSection{scale}
+		for _, sec := range m.chordSections() {
+			sections = append(sections, scoreSection{title: sec.title, chords: sec.chords})
+		}
+		if len(m.progression) > 0 {
+			sections = append(sections, scoreSection{title: exportProgression, chords: m.progression})
+		}
+		return sections
+	}
+
+	return []scoreSection{{title: item, chords: m.exportChords(item)}}
+}
+
+// showScoreExport asks what to export, then asks where to save it in the given format.
+func (m *model) showScoreExport(format, extension string, write func(w io.Writer, sections []scoreSection) error) {
+	itemSelector := widget.NewSelect(append(m.exportItems(), exportAll), nil)
+	itemSelector.SetSelected(exportAll)
+
+	items := []*widget.FormItem{widget.NewFormItem("Export", itemSelector)}
+	dialog.ShowForm("Export "+format, "Export", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		item := itemSelector.Selected
+		m.saveFile(fmt.Sprintf("%s %s %s%s", m.key, m.scale, item, extension), extension, func(w io.Writer) error {
+			return write(w, m.scoreSections(item))
+		})
+	}, m.window)
+}
@@ -0,0 +1,445 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/go-pdf/fpdf"
+)
+
+const (
+	sheetPDF = "PDF"
+	sheetSVG = "SVG"
+
+	// Sizes on the page are in millimeters and font sizes in points.
+	pointSize    = 25.4 / 72
+	sheetMargin  = 12.0
+	sheetColumns = 7
+	sheetGap     = 2.0
+
+	cardHeight     = 14.0
+	cardKeyboard   = 8.0
+	cardDiagram    = 18.0
+	scaleKeyboardW = 70.0
+	scaleKeyboardH = 14.0
+
+	sheetFont = "Noto Sans"
+)
+
+type (
+	paperSize struct {
+		name          string
+		width, height float64
+	}
+
+	sheetOptions struct {
+		paper     paperSize
+		keyboards bool // draw the keys of the scale and of every chord
+		diagrams  bool // draw a guitar chord diagram for every chord
+	}
+
+	// sheetCanvas draws a page with y increasing downwards. Text is placed by its baseline, and shapes are outlined in
+	// black and filled with fill unless it is nil.
+	sheetCanvas interface {
+		text(x, y, size float64, centered bool, s string)
+		line(x1, y1, x2, y2, width float64)
+		rect(x, y, w, h float64, fill color.Color)
+		circle(x, y, r float64, fill color.Color)
+	}
+
+	// scaledCanvas draws on another canvas, scaled and then moved by the offset.
+	scaledCanvas struct {
+		c     sheetCanvas
+		x, y  float64
+		scale float64
+	}
+
+	// nullCanvas draws nothing, for measuring.
+	nullCanvas struct{}
+
+	svgCanvas struct {
+		b strings.Builder
+	}
+
+	pdfCanvas struct {
+		pdf *fpdf.Fpdf
+	}
+)
+
+var (
+	papers = []paperSize{{"A4", 210, 297}, {"Letter", 215.9, 279.4}}
+
+	sheetFormats = []string{sheetPDF, sheetSVG}
+
+	// Shades of gray that print well in black and white.
+	sheetScaleColor = color.Gray{Y: 0xdd}
+	sheetChordColor = color.Gray{Y: 0x99}
+	sheetRootColor  = color.Gray{Y: 0x44}
+)
+
+// paperNamed returns the paper size with the name.
+func paperNamed(name string) (paperSize, bool) {
+	for _, p := range papers {
+		if strings.EqualFold(p.name, name) {
+			return p, true
+		}
+	}
+	return paperSize{}, false
+}
+
+func (c scaledCanvas) text(x, y, size float64, centered bool, s string) {
+	c.c.text(c.x+x*c.scale, c.y+y*c.scale, size*c.scale, centered, s)
+}
+
+func (c scaledCanvas) line(x1, y1, x2, y2, width float64) {
+	c.c.line(c.x+x1*c.scale, c.y+y1*c.scale, c.x+x2*c.scale, c.y+y2*c.scale, width*c.scale)
+}
+
+func (c scaledCanvas) rect(x, y, w, h float64, fill color.Color) {
+	c.c.rect(c.x+x*c.scale, c.y+y*c.scale, w*c.scale, h*c.scale, fill)
+}
+
+func (c scaledCanvas) circle(x, y, r float64, fill color.Color) {
+	c.c.circle(c.x+x*c.scale, c.y+y*c.scale, r*c.scale, fill)
+}
+
+func (nullCanvas) text(float64, float64, float64, bool, string)         {}
+func (nullCanvas) line(float64, float64, float64, float64, float64)     {}
+func (nullCanvas) rect(float64, float64, float64, float64, color.Color) {}
+func (nullCanvas) circle(float64, float64, float64, color.Color)        {}
+
+// svgNumber formats a length to a hundredth of a millimeter.
+func svgNumber(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// svgFill returns the value of a fill attribute.
+func svgFill(c color.Color) string {
+	if c == nil {
+		return "none"
+	}
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+func (c *svgCanvas) text(x, y, size float64, centered bool, s string) {
+	anchor := ""
+	if centered {
+		anchor = ` text-anchor="middle"`
+	}
+	fmt.Fprintf(&c.b, `<text x="%s" y="%s" font-size="%s"%s>`, svgNumber(x), svgNumber(y), svgNumber(size*pointSize),
+		anchor)
+	_ = xml.EscapeText(&c.b, []byte(s))
+	c.b.WriteString("</text>\n")
+}
+
+func (c *svgCanvas) line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&c.b, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="black" stroke-width="%s"/>`+"\n",
+		svgNumber(x1), svgNumber(y1), svgNumber(x2), svgNumber(y2), svgNumber(width))
+}
+
+func (c *svgCanvas) rect(x, y, w, h float64, fill color.Color) {
+	fmt.Fprintf(&c.b, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s" stroke="black" stroke-width="0.2"/>`+"\n",
+		svgNumber(x), svgNumber(y), svgNumber(w), svgNumber(h), svgFill(fill))
+}
+
+func (c *svgCanvas) circle(x, y, r float64, fill color.Color) {
+	fmt.Fprintf(&c.b, `<circle cx="%s" cy="%s" r="%s" fill="%s" stroke="black" stroke-width="0.2"/>`+"\n",
+		svgNumber(x), svgNumber(y), svgNumber(r), svgFill(fill))
+}
+
+// setFill sets the fill color and returns the style to draw a shape with.
+func (c pdfCanvas) setFill(fill color.Color) string {
+	if fill == nil {
+		return "D"
+	}
+	r, g, b, _ := fill.RGBA()
+	c.pdf.SetFillColor(int(r>>8), int(g>>8), int(b>>8))
+	return "FD"
+}
+
+func (c pdfCanvas) text(x, y, size float64, centered bool, s string) {
+	c.pdf.SetFontSize(size)
+	if centered {
+		x -= c.pdf.GetStringWidth(s) / 2
+	}
+	c.pdf.Text(x, y, s)
+}
+
+func (c pdfCanvas) line(x1, y1, x2, y2, width float64) {
+	c.pdf.SetLineWidth(width)
+	c.pdf.Line(x1, y1, x2, y2)
+}
+
+func (c pdfCanvas) rect(x, y, w, h float64, fill color.Color) {
+	c.pdf.SetLineWidth(0.2)
+	c.pdf.Rect(x, y, w, h, c.setFill(fill))
+}
+
+func (c pdfCanvas) circle(x, y, r float64, fill color.Color) {
+	c.pdf.SetLineWidth(0.2)
+	c.pdf.Circle(x, y, r, c.setFill(fill))
+}
+
+// drawKeyboard draws a keyboard of keyboardOctaves octaves with the scale and chord keys shaded, as on the keyboard
+// widget.
+func drawKeyboard(c sheetCanvas, x, y, w, h float64, scale map[int]bool, chordKeys map[int]bool, rootKey int) {
+	whiteWidth := w / (keyboardOctaves * 7)
+	for _, black := range []bool{false, true} {
+		white := 0
+		for i := 0; i < keyboardKeys; i++ {
+			pc := i % chromaticScaleLen
+			var fill color.Color = color.White
+			switch {
+			case i == rootKey:
+				fill = sheetRootColor
+			case chordKeys[i]:
+				fill = sheetChordColor
+			case scale[pc]:
+				fill = sheetScaleColor
+			case blackKeys[pc]:
+				fill = color.Black
+			}
+
+			switch {
+			case blackKeys[pc] && black:
+				c.rect(x+float64(white)*whiteWidth-whiteWidth*0.3, y, whiteWidth*0.6, h*0.6, fill)
+			case !blackKeys[pc] && !black:
+				c.rect(x+float64(white)*whiteWidth, y, whiteWidth, h, fill)
+			}
+			if !blackKeys[pc] {
+				white++
+			}
+		}
+	}
+}
+
+// drawDiagram draws a guitar chord diagram: the strings from the lowest on the left, chordSpan frets from the nut or
+// from the fret named at the side, a dot on every fret played, and an open or muted mark above every other string.
+func drawDiagram(c sheetCanvas, x, y, w, h float64, frets []int) {
+	first, last := maxChordFret, 0
+	for _, f := range frets {
+		if f > 0 {
+			first, last = minInt(first, f), maxInt(last, f)
+		}
+	}
+	if last <= chordSpan {
+		first = 1
+	}
+
+	top := y + 3
+	stringGap := w / float64(len(frets)-1)
+	fretGap := (y + h - top) / chordSpan
+	for s := range frets {
+		c.line(x+float64(s)*stringGap, top, x+float64(s)*stringGap, y+h, 0.2)
+	}
+	for f := 0; f <= chordSpan; f++ {
+		width := 0.2
+		if f == 0 && first == 1 {
+			width = 0.8
+		}
+		c.line(x, top+float64(f)*fretGap, x+w, top+float64(f)*fretGap, width)
+	}
+	if first > 1 {
+		c.text(x-2.5, top+fretGap*0.8, 6, true, strconv.Itoa(first))
+	}
+
+	r := math.Min(stringGap, fretGap) * 0.35
+	for s, f := range frets {
+		sx := x + float64(s)*stringGap
+		switch f {
+		case mutedString:
+			c.text(sx, top-0.8, 6, true, "×")
+		case 0:
+			c.circle(sx, top-1.6, r*0.7, nil)
+		default:
+			c.circle(sx, top+(float64(f-first)+0.5)*fretGap, r, color.Black)
+		}
+	}
+}
+
+// drawSheet draws the chord sheet of the key at the top left of the canvas in the given width and returns its height.
+func (m *model) drawSheet(c sheetCanvas, width float64, opts sheetOptions) float64 {
+	y := 7.0
+	c.text(0, y, 18, false, fmt.Sprintf("%s %s", m.key, m.scale))
+	y += 6
+	c.text(0, y, 10, false, fmt.Sprintf("Scale: %s    Key signature: %s", strings.Join(m.scaleNotes, " "),
+		keySignatureFor(m.key, m.scale).describe()))
+	y += 3
+
+	scale := make(map[int]bool)
+	for _, n := range m.scaleNotes {
+		scale[notePitchClass(n)] = true
+	}
+	if opts.keyboards {
+		drawKeyboard(c, 0, y, scaleKeyboardW, scaleKeyboardH, scale, nil, -1)
+		y += scaleKeyboardH
+	}
+
+	cardWidth := (width - sheetGap*(sheetColumns-1)) / sheetColumns
+	height := cardHeight
+	if opts.keyboards {
+		height += cardKeyboard + sheetGap
+	}
+	if opts.diagrams {
+		height += cardDiagram + sheetGap
+	}
+	for _, sec := range m.chordSections() {
+		y += 7
+		c.text(0, y, 11, false, sec.title)
+		y += 2
+		for i, ch := range sec.chords {
+			cx := float64(i%sheetColumns) * (cardWidth + sheetGap)
+			cy := y + float64(i/sheetColumns)*(height+sheetGap)
+			m.drawCard(c, cx, cy, cardWidth, height, ch, opts)
+		}
+		y += float64((len(sec.chords)+sheetColumns-1)/sheetColumns) * (height + sheetGap)
+	}
+
+	return y
+}
+
+// drawCard draws a chord with its name, position and notes, and its keys and guitar diagram if they are wanted.
+func (m *model) drawCard(c sheetCanvas, x, y, w, h float64, ch chord, opts sheetOptions) {
+	c.rect(x, y, w, h, nil)
+	center := x + w/2
+	c.text(center, y+4.5, 10, true, ch.name)
+	c.text(center, y+8.5, 7, true, ch.position)
+	c.text(center, y+12, 7, true, strings.Join(ch.notes, " "))
+	y += cardHeight
+
+	if opts.keyboards {
+		keys := make(map[int]bool)
+		root := -1
+		for i, k := range keyboardVoicing(ch.notes) {
+			keys[k] = true
+			if i == 0 {
+				root = k
+			}
+		}
+		drawKeyboard(c, x+1.5, y, w-3, cardKeyboard, nil, keys, root)
+		y += cardKeyboard + sheetGap
+	}
+	if opts.diagrams {
+		if frets, ok := chordFrets(tunings[0], ch.notes); ok {
+			drawDiagram(c, x+w*0.25, y, w*0.5, cardDiagram, frets)
+		}
+	}
+}
+
+// layoutSheet draws the sheet within the margins of the page, shrunk if it is too tall to fit.
+func (m *model) layoutSheet(c sheetCanvas, opts sheetOptions) {
+	width := opts.paper.width - 2*sheetMargin
+	height := m.drawSheet(nullCanvas{}, width, opts)
+	scale := math.Min(1, (opts.paper.height-2*sheetMargin)/height)
+	m.drawSheet(scaledCanvas{c: c, x: sheetMargin, y: sheetMargin, scale: scale}, width/scale, opts)
+}
+
+// writeSheetSVG writes the chord sheet as an SVG image of the page.
+func (m *model) writeSheetSVG(w io.Writer, opts sheetOptions) error {
+	var c svgCanvas
+	fmt.Fprintf(&c.b, `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" width="%[1]smm" height="%[2]smm" viewBox="0 0 %[1]s %[2]s" font-family="%[3]s, sans-serif">
+<title>`, svgNumber(opts.paper.width), svgNumber(opts.paper.height), sheetFont)
+	_ = xml.EscapeText(&c.b, []byte(m.key+" "+m.scale))
+	c.b.WriteString("</title>\n")
+	fmt.Fprintf(&c.b, `<rect width="%s" height="%s" fill="white"/>`+"\n", svgNumber(opts.paper.width),
+		svgNumber(opts.paper.height))
+	m.layoutSheet(&c, opts)
+	c.b.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, c.b.String())
+	return err
+}
+
+// writeSheetPDF writes the chord sheet as a single page PDF, with the bundled font embedded so that accidentals print.
+func (m *model) writeSheetPDF(w io.Writer, opts sheetOptions) error {
+	pdf := fpdf.NewCustom(&fpdf.InitType{
+		UnitStr: "mm",
+		Size:    fpdf.SizeType{Wd: opts.paper.width, Ht: opts.paper.height},
+	})
+	pdf.SetTitle(m.key+" "+m.scale, true)
+	pdf.SetCreator("Chords for Keys", true)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddUTF8FontFromBytes(sheetFont, "", resourceNotoSansRegularMusicTtf.StaticContent)
+	pdf.SetFont(sheetFont, "", 10)
+	pdf.AddPage()
+	m.layoutSheet(pdfCanvas{pdf}, opts)
+
+	return pdf.Output(w)
+}
+
+// writeSheet writes the chord sheet in the format.
+func (m *model) writeSheet(w io.Writer, format string, opts sheetOptions) error {
+	if format == sheetSVG {
+		return m.writeSheetSVG(w, opts)
+	}
+	return m.writeSheetPDF(w, opts)
+}
+
+// writeSheetFile writes the chord sheet of the key to a PDF or SVG file, chosen by its extension, without a display.
+func writeSheetFile(path, key, scale string, opts sheetOptions) error {
+	m, err := keyModel(key, scale)
+	if err != nil {
+		return err
+	}
+	format := strings.ToUpper(strings.TrimPrefix(filepath.Ext(path), "."))
+	if format != sheetPDF && format != sheetSVG {
+		return fmt.Errorf("chord sheet %s must end in .pdf or .svg", path)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := m.writeSheet(f, format, opts); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// showSheetExport asks for the format, paper and drawings of the chord sheet, then asks where to save it.
+func (m *model) showSheetExport() {
+	formatSelector := widget.NewSelect(sheetFormats, nil)
+	formatSelector.SetSelected(sheetPDF)
+	var paperNames []string
+	for _, p := range papers {
+		paperNames = append(paperNames, p.name)
+	}
+	paperSelector := widget.NewSelect(paperNames, nil)
+	paperSelector.SetSelectedIndex(0)
+	keyboards := widget.NewCheck("", nil)
+	keyboards.SetChecked(true)
+	diagrams := widget.NewCheck("", nil)
+	diagrams.SetChecked(true)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Format", formatSelector),
+		widget.NewFormItem("Paper", paperSelector),
+		widget.NewFormItem("Keyboards", keyboards),
+		widget.NewFormItem("Guitar Diagrams", diagrams),
+	}
+	dialog.ShowForm("Export Chord Sheet", "Export", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		format := formatSelector.Selected
+		opts := sheetOptions{keyboards: keyboards.Checked, diagrams: diagrams.Checked}
+		opts.paper, _ = paperNamed(paperSelector.Selected)
+		extension := "." + strings.ToLower(format)
+
+		m.saveFile(fmt.Sprintf("%s %s Chords%s", m.key, m.scale, extension), extension, func(w io.Writer) error {
+			return m.writeSheet(w, format, opts)
+		})
+	}, m.window)
+}
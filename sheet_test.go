@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// boundsCanvas records how far down and right the sheet is drawn.
+type boundsCanvas struct {
+	right, bottom float64
+	texts         []string
+}
+
+func (c *boundsCanvas) extend(x, y float64) {
+	c.right, c.bottom = maxFloat(c.right, x), maxFloat(c.bottom, y)
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func (c *boundsCanvas) text(x, y, _ float64, _ bool, s string) {
+	c.extend(x, y)
+	c.texts = append(c.texts, s)
+}
+func (c *boundsCanvas) line(x1, y1, x2, y2, _ float64)         { c.extend(x1, y1); c.extend(x2, y2) }
+func (c *boundsCanvas) rect(x, y, w, h float64, _ color.Color) { c.extend(x+w, y+h) }
+func (c *boundsCanvas) circle(x, y, r float64, _ color.Color)  { c.extend(x+r, y+r) }
+
+func sheetModel(key, scale string) *model {
+	m := &model{key: key, scale: scale, scaleIntervals: scaleIntervals[scale]}
+	m.scaleNotes = enumerateScale(m.key, m.scaleIntervals)
+	return m
+}
+
+func TestLayoutSheet(t *testing.T) {
+	m := sheetModel("E♭", "Minor")
+	for _, paper := range papers {
+		for _, opts := range []sheetOptions{{paper: paper}, {paper: paper, keyboards: true, diagrams: true}} {
+			var c boundsCanvas
+			m.layoutSheet(&c, opts)
+			assert.InDelta(t, paper.width-sheetMargin, c.right, 0.5, "%s", paper.name)
+			assert.LessOrEqual(t, c.bottom, paper.height-sheetMargin+0.01, "%s", paper.name)
+			assert.Contains(t, c.texts, "E♭ Minor")
+			assert.Contains(t, c.texts, "Fm7♭5")
+			assert.Contains(t, c.texts, "Tritone Substitution")
+		}
+	}
+}
+
+func TestWriteSheetSVG(t *testing.T) {
+	var b strings.Builder
+	assert.NoError(t, sheetModel("A", "Major").writeSheetSVG(&b, sheetOptions{paper: papers[1]}))
+	svg := b.String()
+	assert.True(t, strings.HasPrefix(svg, `<?xml version="1.0" encoding="UTF-8"?>`))
+	assert.Contains(t, svg, `width="215.9mm" height="279.4mm" viewBox="0 0 215.9 279.4"`)
+	assert.Contains(t, svg, "<title>A Major</title>")
+	assert.Contains(t, svg, ">C♯m</text>")
+	assert.Contains(t, svg, ">sub VII⁷ / V⁷</text>")
+	assert.NotContains(t, svg, "<circle")
+	assert.True(t, strings.HasSuffix(svg, "</svg>\n"))
+}
+
+func TestWriteSheetPDF(t *testing.T) {
+	var b bytes.Buffer
+	assert.NoError(t, sheetModel("B♭", "Major").writeSheetPDF(&b, sheetOptions{paper: papers[0], diagrams: true}))
+	assert.True(t, bytes.HasPrefix(b.Bytes(), []byte("%PDF-")))
+	assert.Contains(t, b.String(), "/FontFile2")
+	assert.Contains(t, b.String(), "/MediaBox [0 0 595.28 841.89]")
+	assert.True(t, bytes.HasSuffix(bytes.TrimSpace(b.Bytes()), []byte("%%EOF")))
+}
+
+func TestWriteSheetFile(t *testing.T) {
+	dir := t.TempDir()
+	opts := sheetOptions{paper: papers[0]}
+
+	svg := filepath.Join(dir, "sheet.svg")
+	assert.NoError(t, writeSheetFile(svg, "G#", "Minor", opts))
+	data, err := os.ReadFile(svg)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "<title>G♯ Minor</title>")
+
+	assert.NoError(t, writeSheetFile(filepath.Join(dir, "sheet.PDF"), "Db", "Major", opts))
+	assert.EqualError(t, writeSheetFile(filepath.Join(dir, "sheet.png"), "C", "Major", opts),
+		"chord sheet "+filepath.Join(dir, "sheet.png")+" must end in .pdf or .svg")
+	assert.EqualError(t, writeSheetFile(svg, "H", "Major", opts), `unknown key "H"`)
+	assert.EqualError(t, writeSheetFile(svg, "C", "Dorian", opts), `unknown scale "Dorian"`)
+}
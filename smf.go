@@ -0,0 +1,351 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+const (
+	ticksPerBeat = 480
+
+	noteOff         = 0x80
+	noteOn          = 0x90
+	programChange   = 0xc0
+	channelPressure = 0xd0
+	sysex           = 0xf0
+	sysexEscape     = 0xf7
+	metaEvent       = 0xff
+	metaName        = 0x03
+	metaMarker      = 0x06
+	metaEnd         = 0x2f
+	metaTempo       = 0x51
+	metaTimeSig     = 0x58
+	metaKeySig      = 0x59
+	microsPerMin    = 60000000
+)
+
+type (
+	// midiEvent is a MIDI channel or meta event at an absolute time in ticks.
+	midiEvent struct {
+		tick int
+		data []byte
+	}
+
+	// midiTrack is a named track of a Standard MIDI File.
+	midiTrack struct {
+		name   string
+		events []midiEvent
+	}
+
+	// midiNote is a note read from a MIDI file, with its start and length in ticks.
+	midiNote struct {
+		tick     int
+		length   int
+		channel  int
+		note     int
+		velocity int
+	}
+
+	// midiSong is the notes of a MIDI file from all of its tracks, ordered by start time.
+	midiSong struct {
+		division int // ticks per quarter note
+		beats    int // time signature numerator
+		beatUnit int // time signature denominator
+		notes    []midiNote
+	}
+)
+
+var errNotSMF = errors.New("not a Standard MIDI File")
+
+// writeVarLen writes v as a MIDI variable length quantity.
+func writeVarLen(w *bytes.Buffer, v int) {
+	var groups []byte
+	groups = append(groups, byte(v&0x7f))
+	for v >>= 7; v > 0; v >>= 7 {
+		groups = append(groups, byte(v&0x7f)|0x80)
+	}
+	for i := len(groups) - 1; i >= 0; i-- {
+		w.WriteByte(groups[i])
+	}
+}
+
+func metaData(kind byte, data []byte) []byte {
+	var b bytes.Buffer
+	b.WriteByte(metaEvent)
+	b.WriteByte(kind)
+	writeVarLen(&b, len(data))
+	b.Write(data)
+	return b.Bytes()
+}
+
+// tempoEvent sets the tempo in beats per minute.
+func tempoEvent(tick, bpm int) midiEvent {
+	us := microsPerMin / bpm
+	return midiEvent{tick, metaData(metaTempo, []byte{byte(us >> 16), byte(us >> 8), byte(us)})}
+}
+
+// timeSigEvent sets a time signature of beats quarter notes to the bar.
+func timeSigEvent(tick, beats int) midiEvent {
+	return midiEvent{tick, metaData(metaTimeSig, []byte{byte(beats), 2, 24, 8})}
+}
+
+// keySigEvent sets the key signature.
+func keySigEvent(tick int, k keySignature, minor bool) midiEvent {
+	mode := byte(0)
+	if minor {
+		mode = 1
+	}
+	return midiEvent{tick, metaData(metaKeySig, []byte{byte(int8(k)), mode})}
+}
+
+// noteEvents returns the note on and note off events of a note.
+func noteEvents(tick, length, channel, note, velocity int) []midiEvent {
+	return []midiEvent{
+		{tick, []byte{byte(noteOn | channel), byte(note), byte(velocity)}},
+		{tick + length, []byte{byte(noteOff | channel), byte(note), 0}},
+	}
+}
+
+// writeSMF writes the tracks as a type 1 Standard MIDI File. Events are sorted by time, with note offs before note
+// ons at the same time so that repeated notes are not cut short.
+func writeSMF(w io.Writer, tracks []midiTrack) error {
+	header := []interface{}{
+		[4]byte{'M', 'T', 'h', 'd'},
+		uint32(6),
+		uint16(1), // format
+		uint16(len(tracks)),
+		uint16(ticksPerBeat),
+	}
+	for _, field := range header {
+		if err := binary.Write(w, binary.BigEndian, field); err != nil {
+			return err
+		}
+	}
+
+	for _, t := range tracks {
+		events := append([]midiEvent{}, t.events...)
+		sort.SliceStable(events, func(i, j int) bool {
+			if events[i].tick != events[j].tick {
+				return events[i].tick < events[j].tick
+			}
+			return events[i].data[0]&0xf0 == noteOff && events[j].data[0]&0xf0 != noteOff
+		})
+		if t.name != "" {
+			events = append([]midiEvent{{0, metaData(metaName, []byte(t.name))}}, events...)
+		}
+
+		var body bytes.Buffer
+		last := 0
+		for _, e := range events {
+			writeVarLen(&body, e.tick-last)
+			body.Write(e.data)
+			last = e.tick
+		}
+		writeVarLen(&body, 0)
+		body.Write(metaData(metaEnd, nil))
+
+		if _, err := w.Write([]byte("MTrk")); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.BigEndian, uint32(body.Len())); err != nil {
+			return err
+		}
+		if _, err := w.Write(body.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readVarLen reads a MIDI variable length quantity of at most four bytes.
+func readVarLen(r io.ByteReader) (int, error) {
+	var v int
+	for i := 0; i < 4; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		v = v<<7 | int(b&0x7f)
+		if b&0x80 == 0 {
+			return v, nil
+		}
+	}
+
+	return 0, errors.New("variable length quantity is too long")
+}
+
+// readSMF reads the notes of a Standard MIDI File of any format. Only the first time signature is kept; without one
+// the song is taken to be in 4/4.
+func readSMF(r io.Reader) (midiSong, error) {
+	br := bufio.NewReader(r)
+	var header struct {
+		ID       [4]byte
+		Length   uint32
+		Format   uint16
+		Tracks   uint16
+		Division uint16
+	}
+	if err := binary.Read(br, binary.BigEndian, &header); err != nil || string(header.ID[:]) != "MThd" {
+		return midiSong{}, errNotSMF
+	}
+	if header.Length < 6 {
+		return midiSong{}, errNotSMF
+	}
+	if _, err := br.Discard(int(header.Length) - 6); err != nil {
+		return midiSong{}, err
+	}
+	if header.Division&0x8000 != 0 || header.Division == 0 {
+		return midiSong{}, errors.New("SMPTE time division is not supported")
+	}
+
+	song := midiSong{division: int(header.Division), beats: 4, beatUnit: 4}
+	timeSig := false
+	for i := 0; i < int(header.Tracks); i++ {
+		var chunk struct {
+			ID     [4]byte
+			Length uint32
+		}
+		if err := binary.Read(br, binary.BigEndian, &chunk); err != nil {
+			return midiSong{}, fmt.Errorf("track %d: %w", i+1, err)
+		}
+		// The chunk is copied rather than read into a buffer of its stated length, so that a file claiming a longer
+		// chunk than it holds fails without allocating the claimed length first.
+		var data bytes.Buffer
+		if _, err := io.CopyN(&data, br, int64(chunk.Length)); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return midiSong{}, fmt.Errorf("track %d: %w", i+1, err)
+		}
+		if string(chunk.ID[:]) != "MTrk" {
+			i-- // unknown chunks are skipped and do not count as tracks
+			continue
+		}
+		if err := song.readTrack(data.Bytes(), &timeSig); err != nil {
+			return midiSong{}, fmt.Errorf("track %d: %w", i+1, err)
+		}
+	}
+
+	sort.Slice(song.notes, func(i, j int) bool {
+		a, b := song.notes[i], song.notes[j]
+		if a.tick != b.tick {
+			return a.tick < b.tick
+		}
+		return a.note < b.note || a.note == b.note && a.channel < b.channel
+	})
+	return song, nil
+}
+
+// readTrack adds the notes of a track to the song. Notes still sounding at the end of the track end there.
+func (s *midiSong) readTrack(data []byte, timeSig *bool) error {
+	r := bytes.NewReader(data)
+	sounding := make(map[[2]int][]midiNote) // started notes by channel and note number, oldest first
+	var tick int
+	var status byte
+	for r.Len() > 0 {
+		delta, err := readVarLen(r)
+		if err != nil {
+			return err
+		}
+		tick += delta
+
+		b, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if b < 0x80 {
+			// Running status: the byte read is the first data byte of a message with the previous status.
+			if status == 0 {
+				return errors.New("data byte without status")
+			}
+			_ = r.UnreadByte()
+		} else {
+			status = b
+		}
+
+		switch {
+		case status == metaEvent:
+			status = 0
+			kind, _ := r.ReadByte()
+			length, err := readVarLen(r)
+			if err != nil {
+				return err
+			}
+			if length > r.Len() {
+				return errors.New("meta event longer than the track")
+			}
+			meta := make([]byte, length)
+			if _, err := io.ReadFull(r, meta); err != nil {
+				return err
+			}
+			if kind == metaTimeSig && length >= 2 && meta[0] > 0 && meta[1] <= 6 && !*timeSig {
+				*timeSig = true
+				s.beats, s.beatUnit = int(meta[0]), 1<<meta[1]
+			}
+			if kind == metaEnd {
+				r.Reset(nil)
+			}
+		case status == sysex || status == sysexEscape:
+			status = 0
+			length, err := readVarLen(r)
+			if err != nil {
+				return err
+			}
+			if _, err := r.Seek(int64(length), io.SeekCurrent); err != nil {
+				return err
+			}
+		default:
+			msg := make([]byte, 2)
+			if kind := status & 0xf0; kind == programChange || kind == channelPressure {
+				msg = msg[:1]
+			}
+			if _, err := io.ReadFull(r, msg); err != nil {
+				return err
+			}
+			channel := int(status & 0x0f)
+			key := [2]int{channel, int(msg[0])}
+			switch kind := status & 0xf0; {
+			case kind == noteOn && msg[1] > 0:
+				sounding[key] = append(sounding[key], midiNote{tick, 0, channel, int(msg[0]), int(msg[1])})
+			case kind == noteOn || kind == noteOff:
+				if started := sounding[key]; len(started) > 0 {
+					n := started[0]
+					n.length = tick - n.tick
+					s.notes = append(s.notes, n)
+					sounding[key] = started[1:]
+				}
+			}
+		}
+	}
+
+	for _, started := range sounding {
+		for _, n := range started {
+			n.length = tick - n.tick
+			s.notes = append(s.notes, n)
+		}
+	}
+
+	return nil
+}
+
+// beatTicks returns the length of a beat of the time signature in ticks.
+func (s midiSong) beatTicks() int {
+	return s.division * 4 / s.beatUnit
+}
+
+// end returns the tick at which the last note ends.
+func (s midiSong) end() int {
+	var end int
+	for _, n := range s.notes {
+		if n.tick+n.length > end {
+			end = n.tick + n.length
+		}
+	}
+	return end
+}
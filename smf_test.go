@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteVarLen(t *testing.T) {
+	tests := []struct {
+		value int
+		data  []byte
+	}{
+		{0, []byte{0x00}},
+		{0x40, []byte{0x40}},
+		{0x7f, []byte{0x7f}},
+		{0x80, []byte{0x81, 0x00}},
+		{0x2000, []byte{0xc0, 0x00}},
+		{0x3fff, []byte{0xff, 0x7f}},
+		{0x100000, []byte{0xc0, 0x80, 0x00}},
+	}
+
+	for _, e := range tests {
+		var b bytes.Buffer
+		writeVarLen(&b, e.value)
+		assert.Equal(t, e.data, b.Bytes(), "%#x", e.value)
+	}
+}
+
+func TestWriteSMF(t *testing.T) {
+	var b bytes.Buffer
+	tracks := []midiTrack{{events: noteEvents(0, ticksPerBeat, 0, 60, 100)}}
+	assert.NoError(t, writeSMF(&b, tracks))
+
+	expected := []byte{
+		'M', 'T', 'h', 'd', 0, 0, 0, 6, 0, 1, 0, 1, 0x01, 0xe0,
+		'M', 'T', 'r', 'k', 0, 0, 0, 13,
+		0x00, 0x90, 60, 100,
+		0x83, 0x60, 0x80, 60, 0,
+		0x00, 0xff, 0x2f, 0x00,
+	}
+	assert.Equal(t, expected, b.Bytes())
+}
+
+func TestVoice(t *testing.T) {
+	tests := []struct {
+		notes   []string
+		voicing string
+		pitches []string
+	}{
+		{[]string{"C", "E", "G", "B"}, voicingClose, []string{"C4", "E4", "G4", "B4"}},
+		{[]string{"C", "E", "G", "B"}, voicingDrop2, []string{"G3", "C4", "E4", "B4"}},
+		{[]string{"C", "E", "G"}, voicingDrop2, []string{"E3", "C4", "G4"}},
+		{[]string{"C", "E", "G"}, voicingSpread, []string{"C3", "E4", "G4"}},
+	}
+
+	for _, e := range tests {
+		var pitches []string
+		for _, p := range voice(e.notes, 4, e.voicing) {
+			pitches = append(pitches, p.String())
+		}
+		assert.Equal(t, e.pitches, pitches, "%v %s", e.notes, e.voicing)
+	}
+}
+
+func TestMIDITracks(t *testing.T) {
+	m := model{key: "D", scale: "Major", scaleIntervals: scaleIntervals["Major"]}
+	m.scaleNotes = enumerateScale(m.key, m.scaleIntervals)
+	opts := defaultMIDIOptions()
+
+	tracks := m.midiTracks(exportScale, opts)
+	assert.Equal(t, 2, len(tracks))
+	assert.Contains(t, tracks[0].events, keySigEvent(0, 2, false))
+	var notes []int
+	for _, e := range tracks[1].events {
+		if e.data[0] == noteOn {
+			notes = append(notes, int(e.data[1]))
+		}
+	}
+	assert.Equal(t, []int{62, 64, 66, 67, 69, 71, 73, 74}, notes)
+
+	tracks = m.midiTracks("Triads", opts)
+	var markers []string
+	for _, e := range tracks[0].events {
+		if e.data[0] == metaEvent && e.data[1] == metaMarker {
+			markers = append(markers, string(e.data[3:]))
+		}
+	}
+	assert.Equal(t, []string{"D", "Em", "F♯m", "G", "A", "Bm", "C♯°"}, markers)
+	assert.Equal(t, 7*3*2, len(tracks[1].events))
+}
+
+func TestReadSMF(t *testing.T) {
+	m := model{key: "C", scale: "Major", scaleIntervals: scaleIntervals["Major"]}
+	m.scaleNotes = enumerateScale(m.key, m.scaleIntervals)
+	opts := defaultMIDIOptions()
+	opts.length = 4
+
+	var b bytes.Buffer
+	assert.NoError(t, m.exportMIDI(&b, "Triads", opts))
+	song, err := readSMF(&b)
+	assert.NoError(t, err)
+	assert.Equal(t, ticksPerBeat, song.division)
+	assert.Equal(t, 4, song.beats)
+	assert.Equal(t, 4, song.beatUnit)
+	assert.Equal(t, 21, len(song.notes))
+	assert.Equal(t, midiNote{tick: 0, length: 4 * ticksPerBeat, channel: 0, note: 60, velocity: defaultVelocity},
+		song.notes[0])
+	assert.Equal(t, 7*4*ticksPerBeat, song.end())
+}
+
+func TestReadSMFRunningStatus(t *testing.T) {
+	track := []byte{
+		0x00, 0xff, 0x58, 0x04, 0x03, 0x03, 0x18, 0x08, // 3/8
+		0x00, 0x91, 62, 80,
+		0x00, 64, 80, // running status
+		0x60, 62, 0, // note on with velocity 0 ends the note
+		0x00, 0xf0, 0x02, 0x7e, 0xf7, // sysex
+		0x30, 0x81, 64, 0,
+		0x00, 0xff, 0x2f, 0x00,
+	}
+	data := append([]byte{'M', 'T', 'h', 'd', 0, 0, 0, 6, 0, 0, 0, 1, 0, 96, 'M', 'T', 'r', 'k', 0, 0, 0,
+		byte(len(track))}, track...)
+
+	song, err := readSMF(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, song.beats)
+	assert.Equal(t, 8, song.beatUnit)
+	assert.Equal(t, 48, song.beatTicks())
+	assert.Equal(t, []midiNote{
+		{tick: 0, length: 0x60, channel: 1, note: 62, velocity: 80},
+		{tick: 0, length: 0x90, channel: 1, note: 64, velocity: 80},
+	}, song.notes)
+}
+
+func TestReadSMFErrors(t *testing.T) {
+	tests := [][]byte{
+		[]byte("RIFF"),
+		{'M', 'T', 'h', 'd', 0, 0, 0, 6, 0, 1, 0, 1, 0xe7, 0x28},
+		{'M', 'T', 'h', 'd', 0, 0, 0, 6, 0, 1, 0, 1, 0, 96, 'M', 'T', 'r', 'k', 0, 0, 0, 3, 0x00, 0x40, 0x40},
+		{'M', 'T', 'h', 'd', 0, 0, 0, 6, 0, 1, 0, 1, 0, 96, 'M', 'T', 'r', 'k', 0, 0, 0, 8},
+		// a meta event claiming more bytes than its track holds
+		{'M', 'T', 'h', 'd', 0, 0, 0, 6, 0, 1, 0, 1, 0, 96, 'M', 'T', 'r', 'k', 0, 0, 0, 7, 0x00, 0xff, 0x01, 0xff, 0xff, 0xff, 0x7f},
+	}
+
+	for i, data := range tests {
+		_, err := readSMF(bytes.NewReader(data))
+		assert.Error(t, err, "%d", i)
+	}
+}
+
+func TestReadSMFOversizedTrack(t *testing.T) {
+	data := []byte{'M', 'T', 'h', 'd', 0, 0, 0, 6, 0, 1, 0, 1, 0, 96, 'M', 'T', 'r', 'k', 0xff, 0xff, 0xff, 0xff, 0x00, 0x90}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := readSMF(bytes.NewReader(data))
+	runtime.ReadMemStats(&after)
+
+	assert.Error(t, err)
+	assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(1<<20))
+}
@@ -0,0 +1,426 @@
+package main
+
+import (
+	"image/color"
+	"sort"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	clefGrand  = "Grand Staff"
+	clefTreble = "Treble"
+	clefBass   = "Bass"
+
+	trebleClef = "𝄞"
+	bassClef   = "𝄢"
+
+	trebleBottomLine = 4*7 + 2 // E4
+	bassBottomLine   = 2*7 + 4 // G2
+
+	staffMargin   = 6   // staff spaces above and below each staff, room for three ledger lines
+	eventWidth    = 5   // staff spaces given to each note or chord
+	headerWidth   = 4   // staff spaces given to the clef
+	keySigWidth   = 1.2 // staff spaces given to each accidental of the key signature
+	ledgerOverlap = 0.4
+)
+
+type (
+	// staffEvent is a note or chord written on the staff, with an optional label such as a chord name written below
+	// the staff. A bar line is drawn after the event when bar is set.
+	staffEvent struct {
+		pitches []pitch
+		label   string
+		bar     bool
+	}
+
+	// staff writes a sequence of notes and chords on a treble, bass or grand staff with a key signature.
+	staff struct {
+		widget.BaseWidget
+
+		clef   string
+		keySig keySignature
+		events []staffEvent
+	}
+
+	staffRenderer struct {
+		s       *staff
+		objects []fyne.CanvasObject
+	}
+
+	// staffLines describes one five line staff of a system.
+	staffLines struct {
+		clef   string
+		bottom int     // diatonic position of the bottom line
+		y      float32 // y of the bottom line
+	}
+)
+
+var (
+	// Positions of the key signature accidentals on the treble staff, the bass staff is two octaves lower.
+	trebleSharpPositions = map[byte]int{'F': 38, 'C': 35, 'G': 39, 'D': 36, 'A': 33, 'E': 37, 'B': 34}
+	trebleFlatPositions  = map[byte]int{'B': 34, 'E': 37, 'A': 33, 'D': 36, 'G': 32, 'C': 35, 'F': 31}
+
+	clefs = []string{clefGrand, clefTreble, clefBass}
+
+	// symbolShapes gives the height in staff spaces of each music symbol and its anchor: the point, as a fraction of
+	// the height from the top, that sits on the line or space it refers to.
+	symbolShapes = map[string]struct{ height, anchor float32 }{
+		trebleClef:  {7.5, 0.72},
+		bassClef:    {3.3, 0.3},
+		sharp:       {2.8, 0.5},
+		flat:        {2.4, 0.72},
+		natural:     {2.8, 0.5},
+		doubleSharp: {1, 0.5},
+		doubleFlat:  {2.4, 0.72},
+	}
+)
+
+var _ fyne.Widget = (*staff)(nil)
+
+func newStaff() *staff {
+	s := &staff{clef: clefGrand}
+	s.ExtendBaseWidget(s)
+	return s
+}
+
+func (s *staff) setClef(clef string) {
+	s.clef = clef
+	s.Refresh()
+}
+
+func (s *staff) setKeySignature(k keySignature) {
+	s.keySig = k
+	s.Refresh()
+}
+
+func (s *staff) setEvents(events []staffEvent) {
+	s.events = events
+	s.Refresh()
+}
+
+// staffSpace returns the distance between two staff lines.
+func staffSpace() float32 {
+	return theme.TextSize() * 0.6
+}
+
+// lines returns the staves of the system, top first, with y measured from the top of the widget.
+func (s *staff) lines() []staffLines {
+	sp := staffSpace()
+	staffHeight := float32(staffMargin*2+4) * sp
+	switch s.clef {
+	case clefTreble:
+		return []staffLines{{trebleClef, trebleBottomLine, (staffMargin + 4) * sp}}
+	case clefBass:
+		return []staffLines{{bassClef, bassBottomLine, (staffMargin + 4) * sp}}
+	}
+	return []staffLines{
+		{trebleClef, trebleBottomLine, (staffMargin + 4) * sp},
+		{bassClef, bassBottomLine, staffHeight + 4*sp},
+	}
+}
+
+// staffFor returns the index of the staff that the pitch is written on.
+func (s *staff) staffFor(p pitch) int {
+	if s.clef == clefGrand && p.midi() < middleC {
+		return 1
+	}
+	return 0
+}
+
+// y returns the vertical position of the diatonic step on the staff.
+func (l staffLines) yOf(diatonic int) float32 {
+	return l.y - float32(diatonic-l.bottom)*staffSpace()/2
+}
+
+// ledgerLines returns the diatonic positions of the ledger lines needed by a note.
+func (l staffLines) ledgerLines(diatonic int) []int {
+	var ledgers []int
+	for d := l.bottom - 2; d >= diatonic; d -= 2 {
+		ledgers = append(ledgers, d)
+	}
+	for d := l.bottom + 10; d <= diatonic; d += 2 {
+		ledgers = append(ledgers, d)
+	}
+	return ledgers
+}
+
+func (s *staff) CreateRenderer() fyne.WidgetRenderer {
+	r := &staffRenderer{s: s}
+	r.Refresh()
+	return r
+}
+
+func (r *staffRenderer) Layout(size fyne.Size) {
+	r.objects = r.s.draw(size)
+}
+
+func (r *staffRenderer) MinSize() fyne.Size {
+	sp := staffSpace()
+	lines := r.s.lines()
+	width := (headerWidth + float32(len(steps))*keySigWidth + 2 + float32(eventWidth*len(r.s.events))) * sp
+	height := lines[len(lines)-1].y + float32(staffMargin)*sp + theme.TextSize()*1.5
+	return fyne.NewSize(width, height)
+}
+
+func (r *staffRenderer) Refresh() {
+	r.Layout(r.s.Size())
+	canvas.Refresh(r.s)
+}
+
+func (r *staffRenderer) Objects() []fyne.CanvasObject {
+	return r.objects
+}
+
+func (r *staffRenderer) Destroy() {}
+
+// draw creates the canvas objects for the staff at the given size.
+func (s *staff) draw(size fyne.Size) []fyne.CanvasObject {
+	var objects []fyne.CanvasObject
+	sp := staffSpace()
+	lines := s.lines()
+	ink := theme.ForegroundColor()
+
+	line := func(x1, y1, x2, y2 float32, width float32) {
+		l := canvas.NewLine(ink)
+		l.StrokeWidth = width
+		l.Position1 = fyne.NewPos(x1, y1)
+		l.Position2 = fyne.NewPos(x2, y2)
+		objects = append(objects, l)
+	}
+	text := func(label string, x, y float32) {
+		t := canvas.NewText(label, ink)
+		ts := t.MinSize()
+		t.Move(fyne.NewPos(x-ts.Width/2, y-ts.Height/2))
+		t.Resize(ts)
+		objects = append(objects, t)
+	}
+
+	// Staff lines, clefs and key signatures.
+	top := lines[0].y - 4*sp
+	bottom := lines[len(lines)-1].y
+	for _, l := range lines {
+		for i := 0; i < 5; i++ {
+			y := l.y - float32(i)*sp
+			line(0, y, size.Width, y, 1)
+		}
+		clefLine := l.bottom + 2 // the G line
+		if l.clef == bassClef {
+			clefLine = l.bottom + 6 // the F line
+		}
+		objects = append(objects, newSymbol(l.clef, 2*sp, l.yOf(clefLine), ink))
+		if !s.keySig.valid() {
+			continue
+		}
+		positions, symbol := trebleSharpPositions, sharp
+		if s.keySig < 0 {
+			positions, symbol = trebleFlatPositions, flat
+		}
+		octaveShift := 0
+		if l.clef == bassClef {
+			octaveShift = -2 * len(steps)
+		}
+		for i, st := range s.keySig.steps() {
+			objects = append(objects,
+				newSymbol(symbol, (headerWidth+float32(i)*keySigWidth+0.5)*sp, l.yOf(positions[st]+octaveShift), ink))
+		}
+	}
+	line(0, top, 0, bottom, 1)
+	line(size.Width-1, top, size.Width-1, bottom, 2)
+
+	// Notes, accidentals and bar lines.
+	x := float32(headerWidth+2) * sp
+	if s.keySig.valid() {
+		x += float32(len(s.keySig.steps())) * keySigWidth * sp
+	}
+	width := sp * eventWidth
+	if len(s.events) > 0 {
+		width = fyne.Max(width, (size.Width-x)/float32(len(s.events)))
+	}
+
+	altered := make(map[int]int) // alterations in force in the current bar, by diatonic position
+	for _, e := range s.events {
+		center := x + width/2
+		for i, l := range lines {
+			var ps []pitch
+			for _, p := range e.pitches {
+				if s.staffFor(p) == i {
+					ps = append(ps, p)
+				}
+			}
+			objects = append(objects, s.drawChord(l, ps, center, altered, ink)...)
+		}
+		if e.label != "" {
+			text(e.label, center, bottom+float32(staffMargin)*sp)
+		}
+		x += width
+		if e.bar {
+			line(x, top, x, bottom, 1)
+			altered = make(map[int]int)
+		}
+	}
+
+	return objects
+}
+
+// drawChord draws the pitches as whole notes stacked on one staff, centered on x. Notes a second apart are placed on
+// either side of the stem position and accidentals are moved left until they do not collide.
+func (s *staff) drawChord(l staffLines, pitches []pitch, x float32, altered map[int]int,
+	ink color.Color) []fyne.CanvasObject {
+	var objects []fyne.CanvasObject
+	sp := staffSpace()
+	headWidth := sp * 1.4
+
+	sorted := append([]pitch{}, pitches...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].diatonic() < sorted[j].diatonic() })
+
+	var accidentalColumns [][]int // diatonic positions of the accidentals in each column, right to left
+	lastDiatonic, lastShifted := -100, false
+	for _, p := range sorted {
+		d := p.diatonic()
+		y := l.yOf(d)
+
+		shifted := d-lastDiatonic == 1 && !lastShifted
+		lastDiatonic, lastShifted = d, shifted
+		hx := x
+		if shifted {
+			hx += headWidth
+		}
+
+		for _, ld := range l.ledgerLines(d) {
+			ly := l.yOf(ld)
+			ledger := canvas.NewLine(ink)
+			ledger.Position1 = fyne.NewPos(hx-headWidth/2-ledgerOverlap*sp, ly)
+			ledger.Position2 = fyne.NewPos(hx+headWidth/2+ledgerOverlap*sp, ly)
+			objects = append(objects, ledger)
+		}
+
+		head := canvas.NewCircle(color.Transparent)
+		head.StrokeColor = ink
+		head.StrokeWidth = sp / 4
+		head.Move(fyne.NewPos(hx-headWidth/2, y-sp/2))
+		head.Resize(fyne.NewSize(headWidth, sp))
+		objects = append(objects, head)
+
+		if !s.keySig.needsAccidental(p, altered) {
+			continue
+		}
+
+		symbol := accidentals[p.alter]
+		if p.alter == 0 {
+			symbol = natural
+		}
+		column := 0
+		for ; column < len(accidentalColumns); column++ {
+			clear := true
+			for _, other := range accidentalColumns[column] {
+				if d-other < 6 {
+					clear = false
+				}
+			}
+			if clear {
+				break
+			}
+		}
+		if column == len(accidentalColumns) {
+			accidentalColumns = append(accidentalColumns, nil)
+		}
+		accidentalColumns[column] = append(accidentalColumns[column], d)
+
+		objects = append(objects, newSymbol(symbol, x-headWidth/2-float32(column+1)*sp*1.2, y, ink))
+	}
+
+	return objects
+}
+
+// newSymbol draws a music symbol centered horizontally on x, with its anchor on y.
+func newSymbol(symbol string, x, y float32, c color.Color) fyne.CanvasObject {
+	shape := symbolShapes[symbol]
+	return newGlyph([]rune(symbol)[0], shape.height*staffSpace(), shape.anchor, x, y, c)
+}
+
+// chordSection is a titled group of chords, as shown by each card of the UI.
+type chordSection struct {
+	title  string
+	chords []chord
+}
+
+func (m *model) chordSections() []chordSection {
+	return []chordSection{
+		{"Triads", m.buildTriads()},
+		{"Sevenths", m.buildSevenths()},
+		{"Secondary Dominants", m.buildSecondaryDoms()},
+		{"Secondary Lead Tones", m.buildSecondaryLeads()},
+		{"Tritone Substitution", m.buildTritoneSubstition()},
+	}
+}
+
+// buildStaffs returns the staff notation of the scale and of every chord section, with a clef selector.
+func (m *model) buildStaffs() fyne.CanvasObject {
+	m.scaleStaff = newStaff()
+	m.chordStaffs = nil
+	cards := []fyne.CanvasObject{widget.NewCard("", "Scale", m.scaleStaff)}
+	for _, sec := range m.chordSections() {
+		s := newStaff()
+		m.chordStaffs = append(m.chordStaffs, s)
+		cards = append(cards, widget.NewCard("", sec.title, s))
+	}
+
+	clefSelector := widget.NewSelect(clefs, func(clef string) {
+		m.scaleStaff.setClef(clef)
+		for _, s := range m.chordStaffs {
+			s.setClef(clef)
+		}
+		m.refreshStaffs()
+	})
+	clefSelector.SetSelectedIndex(0)
+
+	return container.NewBorder(
+		container.NewHBox(widget.NewLabel("Clef"), clefSelector, layout.NewSpacer()),
+		nil, nil, nil,
+		container.NewVBox(cards...),
+	)
+}
+
+// staffOctave returns the octave that notes start from so that they sit on the staff with few ledger lines.
+func staffOctave(clef string) int {
+	if clef == clefTreble {
+		return 4
+	}
+	return 3
+}
+
+// refreshStaffs writes the current scale and chords on the staffs.
+func (m *model) refreshStaffs() {
+	keySig := keySignatureFor(m.key, m.scale)
+
+	octave := staffOctave(m.scaleStaff.clef)
+	if m.scaleStaff.clef == clefGrand {
+		octave = 4
+	}
+	var scaleEvents []staffEvent
+	for _, p := range voiceNotes(append(append([]string{}, m.scaleNotes...), m.scaleNotes[0]), octave) {
+		scaleEvents = append(scaleEvents, staffEvent{pitches: []pitch{p}})
+	}
+	m.scaleStaff.setKeySignature(keySig)
+	m.scaleStaff.setEvents(scaleEvents)
+
+	for i, sec := range m.chordSections() {
+		s := m.chordStaffs[i]
+		var events []staffEvent
+		for _, c := range sec.chords {
+			events = append(events, staffEvent{
+				pitches: voiceNotes(c.notes, staffOctave(s.clef)),
+				label:   c.name,
+				bar:     true,
+			})
+		}
+		s.setKeySignature(keySig)
+		s.setEvents(events)
+	}
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"image/color"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	weakMinAnswers = 5  // answers in a group before it can be a weak area
+	weakPercent    = 70 // accuracy below which a group is a weak area
+	weakAreasShown = 3
+	statsDays      = 14 // days with answers charted over time
+)
+
+type (
+	// accuracy counts the right answers of a group of practice results.
+	accuracy struct {
+		label        string
+		right, total int
+	}
+
+	// accuracyChart draws a horizontal bar for the accuracy of each group, in red for weak areas.
+	accuracyChart struct {
+		widget.BaseWidget
+
+		groups []accuracy
+	}
+
+	accuracyChartRenderer struct {
+		c       *accuracyChart
+		labels  []*canvas.Text
+		tracks  []*canvas.Rectangle
+		bars    []*canvas.Rectangle
+		values  []*canvas.Text
+		objects []fyne.CanvasObject
+	}
+)
+
+// practiceCSVHeader names the columns of the CSV export of the practice log.
+var practiceCSVHeader = []string{
+	"time", "quiz", "kind", "key", "scale", "quality", "question", "answer", "correct", "seconds",
+}
+
+var _ fyne.Widget = (*accuracyChart)(nil)
+
+func (a accuracy) percent() int {
+	return percent(a.right, a.total)
+}
+
+// weak reports whether the group has enough answers to judge and too few of them right.
+func (a accuracy) weak() bool {
+	return a.total >= weakMinAnswers && a.percent() < weakPercent
+}
+
+func (a accuracy) describe() string {
+	return fmt.Sprintf("%d%% of %d", a.percent(), a.total)
+}
+
+// accuracyBy groups the results by the label of each, leaving out results with an empty label, and returns the
+// groups sorted by less on their labels.
+func accuracyBy(results []practiceResult, label func(r practiceResult) string, less func(a, b string) bool) []accuracy {
+	byLabel := map[string]*accuracy{}
+	var groups []*accuracy
+	for _, r := range results {
+		l := label(r)
+		if l == "" {
+			continue
+		}
+		a, ok := byLabel[l]
+		if !ok {
+			a = &accuracy{label: l}
+			byLabel[l] = a
+			groups = append(groups, a)
+		}
+		a.total++
+		if r.correct {
+			a.right++
+		}
+	}
+
+	sort.Slice(groups, func(i, j int) bool { return less(groups[i].label, groups[j].label) })
+	sorted := make([]accuracy, 0, len(groups))
+	for _, a := range groups {
+		sorted = append(sorted, *a)
+	}
+	return sorted
+}
+
+// accuracyByKey groups the results by key and scale, in the order of keyNames with the major keys first.
+func accuracyByKey(results []practiceResult) []accuracy {
+	order := map[string]int{}
+	for i, scale := range scaleNames {
+		for j, key := range keyNames {
+			order[key+" "+scale] = i*len(keyNames) + j
+		}
+	}
+	return accuracyBy(results, func(r practiceResult) string {
+		if r.key == "" {
+			return ""
+		}
+		return r.key + " " + r.scale
+	}, func(a, b string) bool { return order[a] < order[b] })
+}
+
+// accuracyByQuality groups the results of questions on chords by the quality of the chord.
+func accuracyByQuality(results []practiceResult) []accuracy {
+	return accuracyBy(results, func(r practiceResult) string { return r.quality },
+		func(a, b string) bool { return a < b })
+}
+
+// accuracyByDay groups the results by the local day they were answered on, keeping the last statsDays days.
+func accuracyByDay(results []practiceResult) []accuracy {
+	days := accuracyBy(results, func(r practiceResult) string { return r.time.Local().Format("2006-01-02") },
+		func(a, b string) bool { return a < b })
+	if len(days) > statsDays {
+		days = days[len(days)-statsDays:]
+	}
+	for i, d := range days {
+		if t, err := time.ParseInLocation("2006-01-02", d.label, time.Local); err == nil {
+			days[i].label = t.Format("Mon Jan 2")
+		}
+	}
+	return days
+}
+
+// weakAreas describes the keys and chord qualities with the lowest accuracy below weakPercent.
+func weakAreas(results []practiceResult) string {
+	var weak []accuracy
+	for _, a := range append(accuracyByKey(results), accuracyByQuality(results)...) {
+		if a.weak() {
+			weak = append(weak, a)
+		}
+	}
+	if len(weak) == 0 {
+		return fmt.Sprintf("No weak areas: keys and chord qualities with fewer than %d%% right after %d answers show "+
+			"here", weakPercent, weakMinAnswers)
+	}
+
+	sort.SliceStable(weak, func(i, j int) bool { return weak[i].percent() < weak[j].percent() })
+	var areas []string
+	for _, a := range weak[:minInt(len(weak), weakAreasShown)] {
+		areas = append(areas, fmt.Sprintf("%s (%s)", a.label, a.describe()))
+	}
+	return "Weak areas: " + strings.Join(areas, ", ")
+}
+
+func newAccuracyChart() *accuracyChart {
+	c := &accuracyChart{}
+	c.ExtendBaseWidget(c)
+	return c
+}
+
+func (c *accuracyChart) setGroups(groups []accuracy) {
+	c.groups = groups
+	c.Refresh()
+}
+
+func (c *accuracyChart) CreateRenderer() fyne.WidgetRenderer {
+	r := &accuracyChartRenderer{c: c}
+	r.Refresh()
+	return r
+}
+
+// rowHeight returns the height of a bar with its gap.
+func (r *accuracyChartRenderer) rowHeight() float32 {
+	return theme.TextSize() + theme.Padding()*2
+}
+
+// labelWidth returns the width of the widest label.
+func (r *accuracyChartRenderer) labelWidth() float32 {
+	var w float32
+	for _, l := range r.labels {
+		w = fyne.Max(w, l.MinSize().Width)
+	}
+	return w
+}
+
+func (r *accuracyChartRenderer) Layout(size fyne.Size) {
+	labelWidth := r.labelWidth()
+	valueWidth := fyne.MeasureText("100% of 1000", theme.TextSize(), fyne.TextStyle{}).Width
+	trackWidth := fyne.Max(size.Width-labelWidth-valueWidth-theme.Padding()*4, 0)
+	rowHeight := r.rowHeight()
+
+	for i, a := range r.c.groups {
+		y := float32(i) * rowHeight
+		r.labels[i].Move(fyne.NewPos(0, y))
+		r.labels[i].Resize(fyne.NewSize(labelWidth, rowHeight))
+
+		x := labelWidth + theme.Padding()*2
+		barY := y + theme.Padding()
+		r.tracks[i].Move(fyne.NewPos(x, barY))
+		r.tracks[i].Resize(fyne.NewSize(trackWidth, rowHeight-theme.Padding()*2))
+		r.bars[i].Move(fyne.NewPos(x, barY))
+		r.bars[i].Resize(fyne.NewSize(trackWidth*float32(a.right)/float32(a.total), rowHeight-theme.Padding()*2))
+
+		r.values[i].Move(fyne.NewPos(x+trackWidth+theme.Padding()*2, y))
+		r.values[i].Resize(fyne.NewSize(valueWidth, rowHeight))
+	}
+}
+
+func (r *accuracyChartRenderer) MinSize() fyne.Size {
+	if len(r.c.groups) == 0 {
+		return fyne.NewSize(0, r.rowHeight())
+	}
+	return fyne.NewSize(r.labelWidth()+theme.IconInlineSize()*10, r.rowHeight()*float32(len(r.c.groups)))
+}
+
+// Refresh rebuilds the bars of the groups.
+func (r *accuracyChartRenderer) Refresh() {
+	r.labels, r.tracks, r.bars, r.values, r.objects = nil, nil, nil, nil, nil
+	for _, a := range r.c.groups {
+		label := canvas.NewText(a.label, theme.ForegroundColor())
+		label.Alignment = fyne.TextAlignTrailing
+		track := canvas.NewRectangle(theme.InputBackgroundColor())
+		var fill color.Color = chordHighlightColor
+		if a.weak() {
+			fill = rootHighlightColor
+		}
+		bar := canvas.NewRectangle(fill)
+		value := canvas.NewText(a.describe(), theme.ForegroundColor())
+
+		r.labels = append(r.labels, label)
+		r.tracks = append(r.tracks, track)
+		r.bars = append(r.bars, bar)
+		r.values = append(r.values, value)
+		r.objects = append(r.objects, label, track, bar, value)
+	}
+	r.Layout(r.c.Size())
+	canvas.Refresh(r.c)
+}
+
+func (r *accuracyChartRenderer) Objects() []fyne.CanvasObject {
+	return r.objects
+}
+
+func (r *accuracyChartRenderer) Destroy() {}
+
+// buildStatistics returns charts of the accuracy of the practice answers by key, by chord quality and over time, with
+// the weak areas.
+func (m *model) buildStatistics() fyne.CanvasObject {
+	m.statsLabel = widget.NewLabel("")
+	m.statsLabel.Wrapping = fyne.TextWrapWord
+	m.statsCharts = []*accuracyChart{newAccuracyChart(), newAccuracyChart(), newAccuracyChart()}
+	m.refreshStatistics()
+
+	return container.NewVBox(
+		container.NewBorder(nil, nil, nil,
+			widget.NewButton("Export CSV…", m.showPracticeExport),
+			m.statsLabel,
+		),
+		widget.NewCard("", "Accuracy by Key", m.statsCharts[0]),
+		widget.NewCard("", "Accuracy by Chord Quality", m.statsCharts[1]),
+		widget.NewCard("", "Accuracy by Day", m.statsCharts[2]),
+		layout.NewSpacer(),
+	)
+}
+
+func (m *model) refreshStatistics() {
+	if m.statsLabel == nil {
+		return
+	}
+	right := 0
+	for _, r := range m.history {
+		if r.correct {
+			right++
+		}
+	}
+	m.statsLabel.SetText(fmt.Sprintf("%d answers, %d%% right. %s.", len(m.history), percent(right, len(m.history)),
+		weakAreas(m.history)))
+	m.statsCharts[0].setGroups(accuracyByKey(m.history))
+	m.statsCharts[1].setGroups(accuracyByQuality(m.history))
+	m.statsCharts[2].setGroups(accuracyByDay(m.history))
+}
+
+// writePracticeCSV writes the results as CSV with a header line, one row per answer.
+func writePracticeCSV(w io.Writer, results []practiceResult) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(practiceCSVHeader); err != nil {
+		return err
+	}
+	for _, r := range results {
+		rec := r.record()
+		err := cw.Write([]string{
+			rec.Time.Format(time.RFC3339), rec.Quiz, rec.Kind, rec.Key, rec.Scale, rec.Quality, rec.Question,
+			rec.Answer, strconv.FormatBool(rec.Correct), strconv.FormatFloat(rec.Seconds, 'f', -1, 64),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// showPracticeExport asks where to save the practice log as CSV, for teachers to open in a spreadsheet.
+func (m *model) showPracticeExport() {
+	m.saveFile("practice.csv", ".csv", func(w io.Writer) error {
+		return writePracticeCSV(w, m.history)
+	})
+}
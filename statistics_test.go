@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+)
+
+// practiceResults returns a result in the key for each of the answers, right for a '+' and wrong otherwise.
+func practiceResults(key, scale, quality, answers string, at time.Time) []practiceResult {
+	var results []practiceResult
+	for i, a := range answers {
+		results = append(results, practiceResult{
+			time: at.Add(time.Duration(i) * time.Minute), quiz: theoryQuiz, kind: quizSignature, key: key, scale: scale,
+			quality: quality, correct: a == '+', elapsed: time.Second,
+		})
+	}
+	return results
+}
+
+func TestAccuracy(t *testing.T) {
+	at := time.Date(2026, 10, 19, 9, 0, 0, 0, time.Local)
+	var results []practiceResult
+	results = append(results, practiceResults("B♭", "Minor", "minor", "+-+", at)...)
+	results = append(results, practiceResults("E", "Major", "major", "++-+", at.Add(-24*time.Hour))...)
+	results = append(results, practiceResults("", "", "half-diminished seventh", "+----", at)...)
+	results = append(results, practiceResults("C", "Major", "", "+", at)...)
+
+	assert.Equal(t, []accuracy{{"C Major", 1, 1}, {"E Major", 3, 4}, {"B♭ Minor", 2, 3}}, accuracyByKey(results))
+	assert.Equal(t, []accuracy{{"half-diminished seventh", 1, 5}, {"major", 3, 4}, {"minor", 2, 3}},
+		accuracyByQuality(results))
+	assert.Equal(t, []accuracy{{"Sun Oct 18", 3, 4}, {"Mon Oct 19", 4, 9}}, accuracyByDay(results))
+
+	assert.Equal(t, "Weak areas: half-diminished seventh (20% of 5)", weakAreas(results))
+	assert.Contains(t, weakAreas(results[:3]), "No weak areas")
+
+	var days []practiceResult
+	for i := 0; i < statsDays+3; i++ {
+		days = append(days, practiceResults("C", "Major", "", "+", at.AddDate(0, 0, -i))...)
+	}
+	byDay := accuracyByDay(days)
+	assert.Len(t, byDay, statsDays)
+	assert.Equal(t, "Mon Oct 19", byDay[len(byDay)-1].label)
+}
+
+func TestWritePracticeCSV(t *testing.T) {
+	at := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	results := []practiceResult{
+		{time: at, quiz: theoryQuiz, kind: quizSignature, key: "E", scale: "Major", question: "How many sharps in E Major?",
+			answer: "4", correct: true, elapsed: 2345 * time.Millisecond},
+		{time: at, quiz: flashcardsQuiz, kind: flashcardName, quality: "minor", question: "F♯m", answer: " G♭ A, C♯",
+			elapsed: 5 * time.Second},
+	}
+	var b bytes.Buffer
+	assert.NoError(t, writePracticeCSV(&b, results))
+
+	rows, err := csv.NewReader(&b).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		practiceCSVHeader,
+		{"2026-10-19T09:00:00Z", "Quiz", "Key Signature", "E", "Major", "", "How many sharps in E Major?", "4", "true",
+			"2.345"},
+		{"2026-10-19T09:00:00Z", "Flashcards", "Chord Name", "", "", "minor", "F♯m", "G♭ A, C♯", "false", "5"},
+	}, rows)
+}
+
+func TestStatistics(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	m := sheetModel("C", "Major")
+	m.buildStatistics()
+	assert.Contains(t, m.statsLabel.Text, "0 answers, 0% right. No weak areas")
+
+	for _, r := range practiceResults("F♯", "Major", "", "+----", time.Now()) {
+		m.recordPractice(r)
+	}
+	assert.Equal(t, "5 answers, 20% right. Weak areas: F♯ Major (20% of 5).", m.statsLabel.Text)
+	assert.Equal(t, []accuracy{{"F♯ Major", 1, 5}}, m.statsCharts[0].groups)
+	assert.Empty(t, m.statsCharts[1].groups)
+	assert.Len(t, m.statsCharts[2].groups, 1)
+
+	empty := test.WidgetRenderer(newAccuracyChart()).MinSize()
+	full := test.WidgetRenderer(m.statsCharts[0]).MinSize()
+	assert.Greater(t, full.Width, empty.Width)
+}
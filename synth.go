@@ -0,0 +1,208 @@
+package main
+
+import "math"
+
+const (
+	sampleRate = 44100
+
+	waveSine  = "Sine"
+	waveSaw   = "Saw"
+	wavePiano = "Piano"
+
+	modeBlock    = "Block"
+	modeArpeggio = "Arpeggio"
+	modeStrum    = "Strum"
+
+	defaultTempo = 120
+
+	chordBeats  = 2    // length of a chord in beats
+	strumDelay  = 0.03 // seconds between the strings of a strum
+	noteGain    = 0.3  // amplitude of a single note before mixing
+	peakLimit   = 0.98 // mixes louder than this are scaled down
+	maxHarmonic = 30
+)
+
+type (
+	// synth renders notes to mono PCM at sampleRate with one of a few simple instrument sounds.
+	synth struct {
+		waveform string
+		mode     string // how chords are played: block, arpeggio or strum
+		tempo    int    // beats per minute
+	}
+
+	// synthNote is a MIDI note sounding from start for length seconds, not counting its release.
+	synthNote struct {
+		midi   int
+		start  float64
+		length float64
+	}
+
+	// envelope shapes the volume of a note: it rises over attack, falls over decay to sustain, and fades over release
+	// once the note ends. A positive fall makes the sustain decay exponentially with that time constant, as a struck
+	// string does.
+	envelope struct {
+		attack, decay, sustain, release, fall float64
+	}
+)
+
+var (
+	waveforms = []string{wavePiano, waveSine, waveSaw}
+	playModes = []string{modeBlock, modeArpeggio, modeStrum}
+
+	envelopes = map[string]envelope{
+		waveSine:  {attack: 0.01, decay: 0.1, sustain: 0.7, release: 0.15},
+		waveSaw:   {attack: 0.01, decay: 0.1, sustain: 0.6, release: 0.15},
+		wavePiano: {attack: 0.005, decay: 0.05, sustain: 0.8, release: 0.1, fall: 1.2},
+	}
+)
+
+func newSynth() synth {
+	return synth{waveform: wavePiano, mode: modeBlock, tempo: defaultTempo}
+}
+
+// beat returns the length of a beat in seconds.
+func (s synth) beat() float64 {
+	tempo := s.tempo
+	if tempo <= 0 {
+		tempo = defaultTempo
+	}
+	return 60 / float64(tempo)
+}
+
+// chordNotes schedules the notes of a chord, lowest first, according to the play mode.
+func (s synth) chordNotes(midis []int) []synthNote {
+	length := chordBeats * s.beat()
+	notes := make([]synthNote, 0, len(midis))
+	for i, m := range midis {
+		var start float64
+		switch s.mode {
+		case modeArpeggio:
+			start = float64(i) * s.beat() / 2
+		case modeStrum:
+			start = float64(i) * strumDelay
+		}
+		end := length
+		if s.mode == modeArpeggio {
+			end += float64(len(midis)-1) * s.beat() / 2
+		}
+		notes = append(notes, synthNote{m, start, end - start})
+	}
+
+	return notes
+}
+
+// progressionNotes schedules the chords one after the other, each played according to the play mode.
+func (s synth) progressionNotes(chords [][]int) []synthNote {
+	var notes []synthNote
+	var start float64
+	for _, c := range chords {
+		for _, n := range s.chordNotes(c) {
+			n.start += start
+			notes = append(notes, n)
+		}
+		start += chordBeats * s.beat()
+	}
+
+	return notes
+}
+
+// melodyNotes schedules the notes one after the other, each half a beat long.
+func (s synth) melodyNotes(midis []int) []synthNote {
+	notes := make([]synthNote, 0, len(midis))
+	for i, m := range midis {
+		notes = append(notes, synthNote{m, float64(i) * s.beat() / 2, s.beat() / 2})
+	}
+
+	return notes
+}
+
+// frequency returns the frequency in Hz of the MIDI note, with A4 at 440 Hz.
+func frequency(midi int) float64 {
+	return 440 * math.Pow(2, float64(midi-69)/12)
+}
+
+// level returns the envelope volume at time t of a note lasting length seconds.
+func (e envelope) level(t, length float64) float64 {
+	var v float64
+	switch {
+	case t < e.attack:
+		v = t / e.attack
+	case t < e.attack+e.decay:
+		v = 1 - (1-e.sustain)*(t-e.attack)/e.decay
+	case e.fall > 0:
+		v = e.sustain * math.Exp(-(t-e.attack-e.decay)/e.fall)
+	default:
+		v = e.sustain
+	}
+
+	if t > length {
+		held := e.level(length, length+1)
+		v = held * (1 - (t-length)/e.release)
+	}
+
+	return math.Max(v, 0)
+}
+
+// oscillator returns the waveform value at time t of a note of frequency f. Harmonics above the Nyquist frequency are
+// left out to avoid aliasing.
+func (s synth) oscillator(f, t float64) float64 {
+	harmonics := int(math.Min(maxHarmonic, sampleRate/2/f))
+	switch s.waveform {
+	case waveSaw:
+		var v float64
+		for h := 1; h <= harmonics; h++ {
+			v += math.Sin(2*math.Pi*float64(h)*f*t) / float64(h)
+		}
+		return v * 2 / math.Pi
+	case wavePiano:
+		// Upper harmonics are quieter and die away sooner than the fundamental.
+		var v float64
+		for h := 1; h <= harmonics && h <= 8; h++ {
+			fh := float64(h)
+			v += math.Sin(2*math.Pi*fh*f*t) / math.Pow(fh, 1.5) * math.Exp(-t*fh*0.8)
+		}
+		return v * 0.7
+	}
+
+	return math.Sin(2 * math.Pi * f * t)
+}
+
+// render mixes the notes into samples in [-1, 1].
+func (s synth) render(notes []synthNote) []float32 {
+	env, ok := envelopes[s.waveform]
+	if !ok {
+		env = envelopes[waveSine]
+	}
+
+	var end float64
+	for _, n := range notes {
+		end = math.Max(end, n.start+n.length+env.release)
+	}
+	samples := make([]float32, int(math.Ceil(end*sampleRate)))
+
+	for _, n := range notes {
+		f := frequency(n.midi)
+		first := int(n.start * sampleRate)
+		last := int(math.Min(float64(len(samples)), math.Ceil((n.start+n.length+env.release)*sampleRate)))
+		for i := first; i < last; i++ {
+			t := float64(i)/sampleRate - n.start
+			samples[i] += float32(noteGain * env.level(t, n.length) * s.oscillator(f, t))
+		}
+	}
+
+	var peak float32
+	for _, v := range samples {
+		if v > peak {
+			peak = v
+		} else if -v > peak {
+			peak = -v
+		}
+	}
+	if peak > peakLimit {
+		for i := range samples {
+			samples[i] *= peakLimit / peak
+		}
+	}
+
+	return samples
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrequency(t *testing.T) {
+	assert.InDelta(t, 440, frequency(69), 1e-9)
+	assert.InDelta(t, 261.626, frequency(60), 1e-3)
+	assert.InDelta(t, 880, frequency(81), 1e-9)
+}
+
+func TestChordNotes(t *testing.T) {
+	midis := []int{60, 64, 67}
+	tests := []struct {
+		mode   string
+		starts []float64
+		ends   []float64
+	}{
+		{modeBlock, []float64{0, 0, 0}, []float64{1, 1, 1}},
+		{modeArpeggio, []float64{0, 0.25, 0.5}, []float64{1.5, 1.5, 1.5}},
+		{modeStrum, []float64{0, 0.03, 0.06}, []float64{1, 1, 1}},
+	}
+
+	for _, e := range tests {
+		s := synth{waveform: waveSine, mode: e.mode, tempo: 120}
+		notes := s.chordNotes(midis)
+		assert.Equal(t, len(midis), len(notes), e.mode)
+		for i, n := range notes {
+			assert.Equal(t, midis[i], n.midi, e.mode)
+			assert.InDelta(t, e.starts[i], n.start, 1e-9, e.mode)
+			assert.InDelta(t, e.ends[i], n.start+n.length, 1e-9, e.mode)
+		}
+	}
+}
+
+func TestMelodyNotes(t *testing.T) {
+	s := synth{waveform: waveSine, mode: modeBlock, tempo: 60}
+	notes := s.melodyNotes([]int{60, 62, 64})
+	assert.Equal(t, []synthNote{{60, 0, 0.5}, {62, 0.5, 0.5}, {64, 1, 0.5}}, notes)
+}
+
+func TestRenderSine(t *testing.T) {
+	s := synth{waveform: waveSine, mode: modeBlock, tempo: 60}
+	samples := s.render([]synthNote{{69, 0, 1}})
+
+	release := envelopes[waveSine].release
+	assert.Equal(t, int(math.Ceil((1+release)*sampleRate)), len(samples))
+
+	// A 440 Hz sine crosses zero upwards 440 times a second.
+	crossings := 0
+	for i := 1; i < sampleRate; i++ {
+		if samples[i-1] < 0 && samples[i] >= 0 {
+			crossings++
+		}
+	}
+	assert.InDelta(t, 440, crossings, 1)
+}
+
+func TestRenderLimitsPeak(t *testing.T) {
+	for _, w := range waveforms {
+		s := synth{waveform: w, mode: modeBlock, tempo: 120}
+		var notes []synthNote
+		for m := 48; m < 72; m++ {
+			notes = append(notes, synthNote{m, 0, 0.5})
+		}
+		for _, v := range s.render(notes) {
+			if v > peakLimit+1e-6 || v < -peakLimit-1e-6 {
+				t.Fatalf("Sample %f out of range for waveform %s", v, w)
+			}
+		}
+	}
+}
+
+func TestWAVOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chord.wav")
+	s := newSynth()
+	samples := s.render(s.chordNotes(midiNotes(voiceNotes([]string{"C", "E", "G"}, chordOctave))))
+	assert.NoError(t, wavOutput{path}.play(samples))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 44+2*len(samples), len(data))
+	assert.Equal(t, []byte("RIFF"), data[0:4])
+	assert.Equal(t, []byte("WAVE"), data[8:12])
+	assert.Equal(t, []byte("data"), data[36:40])
+
+	var rate uint32
+	assert.NoError(t, binary.Read(bytes.NewReader(data[24:28]), binary.LittleEndian, &rate))
+	assert.Equal(t, uint32(sampleRate), rate)
+}
+
+func TestPCM16Clips(t *testing.T) {
+	data := pcm16([]float32{0, 1, -1, 2, -2})
+	values := make([]int16, 5)
+	assert.NoError(t, binary.Read(bytes.NewReader(data), binary.LittleEndian, values))
+	assert.Equal(t, []int16{0, math.MaxInt16, -math.MaxInt16, math.MaxInt16, -math.MaxInt16}, values)
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	tuiCommand = "tui"
+
+	tuiHeader  = 3 // lines above the chord sections: selectors, selected chord, blank
+	tuiFooter  = 1 // help line below the chord sections
+	cellHeight = 3 // chord name, position and notes
+	cellGap    = 2 // columns between chord cells
+)
+
+// The parts of the terminal UI that take the arrow keys, chosen with Tab.
+const (
+	tuiFocusKey = iota
+	tuiFocusScale
+	tuiFocusChords
+	tuiFocusCount
+)
+
+const tuiHelp = "Tab focus  ←→ change  ↑↓ section  Enter play  k/K key  s scale  PgUp/PgDn scroll  q quit"
+
+var (
+	tuiFocusStyle    = tcell.StyleDefault.Reverse(true)
+	tuiTitleStyle    = tcell.StyleDefault.Bold(true)
+	tuiSelectedStyle = tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorSteelBlue)
+	tuiHelpStyle     = tcell.StyleDefault.Dim(true)
+)
+
+// tui is the keyboard-driven terminal front-end. It shows the chord sections of the model as the window does, as rows
+// of cells under each section title, and scrolls them to keep the chord under the cursor in view.
+type tui struct {
+	m      *model
+	screen tcell.Screen
+	ascii  bool // spell accidentals and symbols in ASCII
+
+	focus    int
+	sections []chordSection
+	section  int // of the chord under the cursor
+	index    int // of the chord under the cursor in its section
+	scroll   int // lines of the chord sections scrolled off the top
+}
+
+// runTUI runs the tui command with its arguments until the user quits.
+func runTUI(args []string) error {
+	fs := flag.NewFlagSet(tuiCommand, flag.ContinueOnError)
+	key := fs.String("key", keyNames[0], "key, which may use ASCII accidentals, as in Eb")
+	scale := fs.String("scale", scaleNames[0], "scale: "+strings.Join(scaleNames, " or "))
+	ascii := fs.Bool("ascii", !utf8Locale(), "spell accidentals and symbols in ASCII")
+	wavPath := fs.String("wav", "", "write played chords to this WAV file instead of the audio device")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	m, err := keyModel(*key, *scale)
+	if err != nil {
+		return err
+	}
+	m.synth = newSynth()
+	if audio, err := newAudioOutput(*wavPath); err == nil {
+		m.audio = audio
+	}
+
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return err
+	}
+	if err := screen.Init(); err != nil {
+		return err
+	}
+	defer screen.Fini()
+
+	t := newTUI(m, screen, *ascii)
+	for {
+		t.draw()
+		if !t.handle(screen.PollEvent()) {
+			return nil
+		}
+	}
+}
+
+func newTUI(m *model, screen tcell.Screen, ascii bool) *tui {
+	t := &tui{m: m, screen: screen, ascii: ascii, focus: tuiFocusChords}
+	t.refresh()
+	return t
+}
+
+// refresh rebuilds the chord sections after the key or scale changed and moves the cursor to the first chord, clearing
+// the selection as the window does.
+func (t *tui) refresh() {
+	t.sections = t.m.chordSections()
+	t.section, t.index, t.scroll = 0, 0, 0
+	t.m.selected = nil
+}
+
+// setKey moves the key by delta entries of keyNames.
+func (t *tui) setKey(delta int) {
+	i := (indexOf(keyNames, t.m.key) + delta + len(keyNames)) % len(keyNames)
+	t.m.setKey(keyNames[i])
+	t.refresh()
+}
+
+// setScale moves the scale by delta entries of scaleNames.
+func (t *tui) setScale(delta int) {
+	i := (indexOf(scaleNames, t.m.scale) + delta + len(scaleNames)) % len(scaleNames)
+	t.m.setScale(scaleNames[i])
+	t.refresh()
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// handle acts on the event and reports whether the UI keeps running.
+func (t *tui) handle(ev tcell.Event) bool {
+	switch ev := ev.(type) {
+	case *tcell.EventResize:
+		t.screen.Sync()
+	case *tcell.EventKey:
+		return t.handleKey(ev)
+	}
+	return true
+}
+
+func (t *tui) handleKey(ev *tcell.EventKey) bool {
+	delta := 0
+	switch ev.Key() {
+	case tcell.KeyCtrlC, tcell.KeyEscape:
+		return false
+	case tcell.KeyTab:
+		t.focus = (t.focus + 1) % tuiFocusCount
+	case tcell.KeyBacktab:
+		t.focus = (t.focus + tuiFocusCount - 1) % tuiFocusCount
+	case tcell.KeyLeft:
+		delta = -1
+	case tcell.KeyRight:
+		delta = 1
+	case tcell.KeyUp:
+		t.moveSection(-1)
+	case tcell.KeyDown:
+		t.moveSection(1)
+	case tcell.KeyPgUp:
+		t.scroll -= t.bodyHeight()
+	case tcell.KeyPgDn:
+		t.scroll += t.bodyHeight()
+	case tcell.KeyEnter:
+		t.play()
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case 'q':
+			return false
+		case 'k':
+			t.setKey(1)
+		case 'K':
+			t.setKey(-1)
+		case 's', 'S':
+			t.setScale(1)
+		case ' ':
+			t.play()
+		}
+	}
+
+	if delta != 0 {
+		switch t.focus {
+		case tuiFocusKey:
+			t.setKey(delta)
+		case tuiFocusScale:
+			t.setScale(delta)
+		case tuiFocusChords:
+			t.moveChord(delta)
+		}
+	}
+	return true
+}
+
+// moveSection moves the cursor to the chord in the same column of another section, or the last chord of a shorter
+// one.
+func (t *tui) moveSection(delta int) {
+	if t.focus != tuiFocusChords {
+		t.focus = tuiFocusChords
+		return
+	}
+	t.section = (t.section + delta + len(t.sections)) % len(t.sections)
+	t.index = minInt(t.index, len(t.sections[t.section].chords)-1)
+	t.scrollToCursor()
+}
+
+// moveChord moves the cursor to the next or previous chord, into the next or previous section at either end.
+func (t *tui) moveChord(delta int) {
+	t.index += delta
+	if t.index < 0 {
+		t.section = (t.section + len(t.sections) - 1) % len(t.sections)
+		t.index = len(t.sections[t.section].chords) - 1
+	} else if t.index >= len(t.sections[t.section].chords) {
+		t.section = (t.section + 1) % len(t.sections)
+		t.index = 0
+	}
+	t.scrollToCursor()
+}
+
+// play selects and plays the chord under the cursor, as tapping its card does in the window.
+func (t *tui) play() {
+	if t.focus != tuiFocusChords {
+		return
+	}
+	t.m.tapChord(t.sections[t.section].chords[t.index])
+}
+
+// text returns the string as it is drawn, in ASCII if asked for.
+func (t *tui) text(s string) string {
+	if t.ascii {
+		return asciiSymbols.Replace(s)
+	}
+	return s
+}
+
+// drawText draws the string from the column and returns the column after it.
+func (t *tui) drawText(x, y int, style tcell.Style, s string) int {
+	for _, r := range t.text(s) {
+		t.screen.SetContent(x, y, r, nil, style)
+		x += maxInt(runewidth.RuneWidth(r), 1)
+	}
+	return x
+}
+
+// cellWidth returns the width of the chord cells, which is that of the widest text of any chord.
+func (t *tui) cellWidth() int {
+	w := 0
+	for _, sec := range t.sections {
+		for _, c := range sec.chords {
+			for _, s := range []string{c.name, c.position, strings.Join(c.notes, " ")} {
+				w = maxInt(w, runewidth.StringWidth(t.text(s)))
+			}
+		}
+	}
+	return w
+}
+
+// columns returns the number of chord cells in a row.
+func (t *tui) columns() int {
+	width, _ := t.screen.Size()
+	return maxInt((width+cellGap)/(t.cellWidth()+cellGap), 1)
+}
+
+// sectionHeight returns the lines of a section: its title, its rows of cells and a blank line.
+func (t *tui) sectionHeight(sec chordSection) int {
+	rows := (len(sec.chords) + t.columns() - 1) / t.columns()
+	return 1 + rows*cellHeight + 1
+}
+
+// contentHeight returns the lines of all the chord sections.
+func (t *tui) contentHeight() int {
+	h := 0
+	for _, sec := range t.sections {
+		h += t.sectionHeight(sec)
+	}
+	return h
+}
+
+func (t *tui) bodyHeight() int {
+	_, height := t.screen.Size()
+	return maxInt(height-tuiHeader-tuiFooter, 1)
+}
+
+// cursorLine returns the line of the chord sections where the cell under the cursor starts.
+func (t *tui) cursorLine() int {
+	line := 0
+	for _, sec := range t.sections[:t.section] {
+		line += t.sectionHeight(sec)
+	}
+	return line + 1 + t.index/t.columns()*cellHeight
+}
+
+// scrollToCursor scrolls the chord sections as little as needed for the cell under the cursor to be in view.
+func (t *tui) scrollToCursor() {
+	line := t.cursorLine()
+	if line-1 < t.scroll {
+		t.scroll = line - 1 // show the section title with its first row
+	}
+	if line+cellHeight > t.scroll+t.bodyHeight() {
+		t.scroll = line + cellHeight - t.bodyHeight()
+	}
+}
+
+func (t *tui) draw() {
+	t.scroll = minInt(t.scroll, t.contentHeight()-t.bodyHeight())
+	t.scroll = maxInt(t.scroll, 0)
+
+	t.screen.Clear()
+	t.drawHeader()
+	t.drawSections()
+	_, height := t.screen.Size()
+	t.drawText(0, height-1, tuiHelpStyle, tuiHelp)
+	t.screen.Show()
+}
+
+func (t *tui) drawHeader() {
+	style := func(focus int) tcell.Style {
+		if t.focus == focus {
+			return tuiFocusStyle
+		}
+		return tcell.StyleDefault
+	}
+
+	x := t.drawText(0, 0, tcell.StyleDefault, "Key ")
+	x = t.drawText(x, 0, style(tuiFocusKey), "◀ "+t.m.key+" ▶")
+	x = t.drawText(x, 0, tcell.StyleDefault, "   Scale ")
+	x = t.drawText(x, 0, style(tuiFocusScale), "◀ "+t.m.scale+" ▶")
+	t.drawText(x, 0, tcell.StyleDefault, "   Scale Notes "+strings.Join(t.m.scaleNotes, " "))
+
+	if c := t.m.selected; c != nil {
+		t.drawText(0, 1, tuiSelectedStyle, fmt.Sprintf(" %s  %s  %s ", c.name, c.position, strings.Join(c.notes, " ")))
+	}
+}
+
+func (t *tui) drawSections() {
+	width := t.cellWidth()
+	columns := t.columns()
+	bottom := tuiHeader + t.bodyHeight()
+	draw := func(x, y int, style tcell.Style, s string) {
+		if y >= tuiHeader && y < bottom {
+			t.drawText(x, y, style, s)
+		}
+	}
+
+	top := tuiHeader - t.scroll
+	for i, sec := range t.sections {
+		draw(0, top, tuiTitleStyle, sec.title)
+		for j, c := range sec.chords {
+			style := tcell.StyleDefault
+			if t.m.selected != nil && t.m.selected.name == c.name && t.m.selected.position == c.position {
+				style = tuiSelectedStyle
+			}
+			if t.focus == tuiFocusChords && i == t.section && j == t.index {
+				style = tuiFocusStyle
+			}
+
+			x := j % columns * (width + cellGap)
+			y := top + 1 + j/columns*cellHeight
+			for k, s := range []string{c.name, c.position, strings.Join(c.notes, " ")} {
+				s += strings.Repeat(" ", width-runewidth.StringWidth(t.text(s)))
+				draw(x, y+k, style.Bold(k == 0), s)
+			}
+		}
+		top += t.sectionHeight(sec)
+	}
+}
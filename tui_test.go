@@ -0,0 +1,138 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// simTUI returns a terminal UI of the key and scale drawn on a simulated screen of the given size.
+func simTUI(t *testing.T, key, scale string, width, height int, ascii bool) (*tui, tcell.SimulationScreen) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	screen.SetSize(width, height)
+	m, err := keyModel(key, scale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ui := newTUI(m, screen, ascii)
+	ui.draw()
+	return ui, screen
+}
+
+// screenLines returns the text of the lines of the screen, without trailing spaces.
+func screenLines(screen tcell.SimulationScreen) []string {
+	cells, width, height := screen.GetContents()
+	lines := make([]string, height)
+	for y := range lines {
+		var b strings.Builder
+		for _, c := range cells[y*width : (y+1)*width] {
+			if len(c.Runes) > 0 {
+				b.WriteRune(c.Runes[0])
+			}
+		}
+		lines[y] = strings.TrimRight(b.String(), " ")
+	}
+	return lines
+}
+
+// key sends a key to the terminal UI, checks that it keeps running and draws it.
+func key(t *testing.T, ui *tui, k tcell.Key, r rune) {
+	assert.True(t, ui.handle(tcell.NewEventKey(k, r, tcell.ModNone)))
+	ui.draw()
+}
+
+func TestTUIDraw(t *testing.T) {
+	_, screen := simTUI(t, "E♭", "Minor", 64, 12, false)
+	assert.Equal(t, []string{
+		"Key ◀ E♭ ▶   Scale ◀ Minor ▶   Scale Notes E♭ F G♭ A♭ B♭ C♭ D♭",
+		"",
+		"",
+		"Triads",
+		"E♭m            F°             G♭             A♭m",
+		"I              II             III            IV",
+		"E♭ G♭ B♭       F A♭ C♭        G♭ B♭ D♭       A♭ C♭ E♭",
+		"B♭m            C♭             D♭",
+		"V              VI             VII",
+		"B♭ D♭ F        C♭ E♭ G♭       D♭ F A♭",
+		"",
+		"Tab focus  ←→ change  ↑↓ section  Enter play  k/K key  s scale",
+	}, screenLines(screen))
+}
+
+func TestTUIASCII(t *testing.T) {
+	_, screen := simTUI(t, "E♭", "Minor", 64, 12, true)
+	lines := screenLines(screen)
+	assert.Equal(t, "Key ◀ Eb ▶   Scale ◀ Minor ▶   Scale Notes Eb F Gb Ab Bb Cb Db", lines[0])
+	assert.Equal(t, "Ebm            Fdim           Gb             Abm", lines[4])
+}
+
+func TestTUIKeys(t *testing.T) {
+	ui, screen := simTUI(t, "C", "Major", 100, 12, false)
+
+	key(t, ui, tcell.KeyRune, 'k')
+	assert.Equal(t, "C♯", ui.m.key)
+	key(t, ui, tcell.KeyRune, 'K')
+	key(t, ui, tcell.KeyRune, 'K')
+	assert.Equal(t, "B", ui.m.key)
+
+	key(t, ui, tcell.KeyTab, 0) // focus the key selector
+	key(t, ui, tcell.KeyRight, 0)
+	assert.Equal(t, "C", ui.m.key)
+	key(t, ui, tcell.KeyTab, 0) // focus the scale selector
+	key(t, ui, tcell.KeyRight, 0)
+	assert.Equal(t, "Minor", ui.m.scale)
+	assert.Equal(t, []string{"C", "D", "E♭", "F", "G", "A♭", "B♭"}, ui.m.scaleNotes)
+	assert.Equal(t, "Key ◀ C ▶   Scale ◀ Minor ▶   Scale Notes C D E♭ F G A♭ B♭", screenLines(screen)[0])
+
+	key(t, ui, tcell.KeyEnter, 0) // does nothing outside the chords
+	assert.Nil(t, ui.m.selected)
+	key(t, ui, tcell.KeyTab, 0) // focus the chords
+	key(t, ui, tcell.KeyRight, 0)
+	key(t, ui, tcell.KeyDown, 0)
+	key(t, ui, tcell.KeyEnter, 0)
+	if assert.NotNil(t, ui.m.selected) {
+		assert.Equal(t, "Dm7♭5", ui.m.selected.name)
+	}
+	assert.Equal(t, " Dm7♭5  II⁷  D F A♭ C", screenLines(screen)[1])
+	key(t, ui, tcell.KeyRune, ' ') // tapping the selected chord again clears the selection
+	assert.Nil(t, ui.m.selected)
+
+	key(t, ui, tcell.KeyLeft, 0)
+	key(t, ui, tcell.KeyLeft, 0) // to the last triad
+	assert.Equal(t, 0, ui.section)
+	assert.Equal(t, 6, ui.index)
+	key(t, ui, tcell.KeyUp, 0) // to the tritone substitution
+	assert.Equal(t, 4, ui.section)
+	assert.Equal(t, 0, ui.index)
+
+	assert.False(t, ui.handle(tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone)))
+	assert.False(t, ui.handle(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)))
+}
+
+func TestTUIScroll(t *testing.T) {
+	ui, screen := simTUI(t, "C", "Major", 100, 12, false)
+	assert.Equal(t, 0, ui.scroll)
+
+	key(t, ui, tcell.KeyUp, 0) // to the tritone substitution, at the bottom
+	lines := screenLines(screen)
+	assert.Equal(t, "Tritone Substitution", lines[7])
+	assert.Equal(t, "D♭7", lines[8])
+	assert.Equal(t, "D♭ F A♭ B", lines[10])
+
+	key(t, ui, tcell.KeyPgUp, 0)
+	key(t, ui, tcell.KeyPgUp, 0)
+	key(t, ui, tcell.KeyPgUp, 0)
+	key(t, ui, tcell.KeyPgUp, 0)
+	assert.Equal(t, 0, ui.scroll)
+	key(t, ui, tcell.KeyPgDn, 0)
+	assert.Equal(t, ui.bodyHeight(), ui.scroll)
+
+	key(t, ui, tcell.KeyRune, 'k') // a new key starts at the top
+	assert.Equal(t, 0, ui.scroll)
+	assert.Equal(t, "Triads", screenLines(screen)[3])
+}
@@ -0,0 +1,406 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	// The voicings that lead the voices from chord to chord of a progression rather than voicing each chord alone.
+	voicingSATB     = "SATB Voice Leading"
+	voicingLedClose = "Close Voice Leading"
+
+	parallelCost   = 24 // for each pair of voices moving in parallel fifths or octaves
+	unresolvedCost = 12 // for each leading tone or seventh left unresolved
+	startCost      = 4  // divides the distance of the first chord's voices from the middle of their ranges
+)
+
+type (
+	// ledChord is a chord of a progression voiced for bass, tenor, alto and soprano, with the voice-leading issues of
+	// the move into it from the chord before.
+	ledChord struct {
+		chord   chord
+		pitches []pitch // bass first
+		issues  []string
+	}
+
+	// voicingCandidate is a way of voicing a chord, with the cost of its spacing and doubling.
+	voicingCandidate struct {
+		pitches []pitch
+		cost    int
+	}
+)
+
+var (
+	leadVoicings = []string{voicingSATB, voicingLedClose}
+
+	voiceNames = []string{"bass", "tenor", "alto", "soprano"}
+
+	// voiceRanges are the lowest and highest MIDI notes of each voice, bass first.
+	voiceRanges = [][2]int{{40, 60}, {48, 67}, {55, 74}, {60, 79}}
+
+	// doublingCosts are the costs of doubling the root, third, fifth and seventh of a chord.
+	doublingCosts = []int{0, 3, 1, 4}
+)
+
+// chordTones returns the root, third, fifth and seventh of the chord, as many as it has, leaving out extensions.
+func chordTones(c chord) []string {
+	if len(c.notes) > 4 {
+		return c.notes[:4]
+	}
+	return c.notes
+}
+
+// intervalAbove returns the semitones from the root of the chord up to its tone i.
+func intervalAbove(tones []string, i int) int {
+	return pitchClass(notePitchClass(tones[i]) - notePitchClass(tones[0]))
+}
+
+// diminished reports whether the chord has a minor third and a diminished fifth.
+func diminished(tones []string) bool {
+	return len(tones) >= 3 && intervalAbove(tones, 1) == 3 && intervalAbove(tones, 2) == 6
+}
+
+// hasSeventh reports whether the fourth tone of the chord is a seventh rather than, say, a sixth.
+func hasSeventh(tones []string) bool {
+	if len(tones) < 4 {
+		return false
+	}
+	i := intervalAbove(tones, 3)
+	return i == 10 || i == 11 || (i == 9 && diminished(tones))
+}
+
+// pitchesInRange returns every pitch of the notes from the MIDI note lo up to hi.
+func pitchesInRange(notes []string, lo, hi int) []pitch {
+	var pitches []pitch
+	for _, n := range notes {
+		for octave := 0; octave <= 8; octave++ {
+			if p, ok := newPitch(n, octave); ok && p.midi() >= lo && p.midi() <= hi {
+				pitches = append(pitches, p)
+			}
+		}
+	}
+	return pitches
+}
+
+// voicingCandidates returns the four-part voicings of the chord in root position for the style. The voices keep to
+// their ranges without crossing; in SATB no two upper voices are more than an octave apart, in close position the
+// upper voices are all within an octave. The third and seventh are always there; the fifth may be left out.
+func voicingCandidates(c chord, style string) []voicingCandidate {
+	tones := chordTones(c)
+	if len(tones) < 3 {
+		return nil
+	}
+	var upper [3][]pitch
+	for v := range upper {
+		upper[v] = pitchesInRange(tones, voiceRanges[v+1][0], voiceRanges[v+1][1])
+	}
+
+	var candidates []voicingCandidate
+	for _, b := range pitchesInRange(tones[:1], voiceRanges[0][0], voiceRanges[0][1]) {
+		for _, t := range upper[0] {
+			for _, a := range upper[1] {
+				for _, s := range upper[2] {
+					pitches := []pitch{b, t, a, s}
+					if cost, ok := voicingCost(tones, pitches, style); ok {
+						candidates = append(candidates, voicingCandidate{pitches, cost})
+					}
+				}
+			}
+		}
+	}
+	return candidates
+}
+
+// voicingCost returns the cost of the doubled and missing tones of the voicing, or false if the voicing breaks the
+// spacing of the style or leaves out a tone other than the fifth.
+func voicingCost(tones []string, pitches []pitch, style string) (int, bool) {
+	b, t, a, s := pitches[0].midi(), pitches[1].midi(), pitches[2].midi(), pitches[3].midi()
+	if b >= t || t >= a || a >= s {
+		return 0, false
+	}
+	if style == voicingLedClose && s-t >= chromaticScaleLen {
+		return 0, false
+	}
+	if style == voicingSATB && (a-t > chromaticScaleLen || s-a > chromaticScaleLen) {
+		return 0, false
+	}
+
+	counts := map[string]int{}
+	for _, p := range pitches {
+		counts[p.name()]++
+	}
+	cost := 0
+	for i, n := range tones {
+		switch {
+		case counts[n] == 0 && i != 2:
+			return 0, false
+		case counts[n] == 0:
+			cost += len(voiceNames) - len(tones) + 1 // a fifth left out of a triad doubles two tones
+		case counts[n] > 1:
+			cost += (counts[n] - 1) * doublingCosts[i]
+		}
+	}
+	return cost, true
+}
+
+// parallels describes the pairs of voices moving from one voicing to the next in parallel fifths or octaves.
+func parallels(from, to []pitch) []string {
+	var issues []string
+	for i := 0; i < len(from); i++ {
+		for j := i + 1; j < len(from); j++ {
+			before := pitchClass(from[j].midi() - from[i].midi())
+			after := pitchClass(to[j].midi() - to[i].midi())
+			if before != after || (before != 0 && before != 7) {
+				continue
+			}
+			di, dj := to[i].midi()-from[i].midi(), to[j].midi()-from[j].midi()
+			if di == 0 || dj == 0 || (di > 0) != (dj > 0) {
+				continue
+			}
+			interval := "fifths"
+			if before == 0 {
+				interval = "octaves"
+			}
+			issues = append(issues, fmt.Sprintf("Parallel %s between %s and %s", interval, voiceNames[i], voiceNames[j]))
+		}
+	}
+	return issues
+}
+
+// unresolved describes the tendency tones of the chord that are not resolved in the move to the next chord: the
+// leading tone of a dominant going to its tonic or of a diminished chord going to the chord a semitone up should rise
+// a semitone, and a seventh should fall by step.
+func unresolved(c, next chord, tonic string, from, to []pitch) []string {
+	tones, nextTones := chordTones(c), chordTones(next)
+	if len(tones) < 3 || len(nextTones) == 0 {
+		return nil
+	}
+	root, nextRoot := notePitchClass(tones[0]), notePitchClass(nextTones[0])
+	if root == nextRoot {
+		return nil
+	}
+
+	leadingTone := ""
+	switch motion := pitchClass(nextRoot - root); {
+	case motion == 5 && intervalAbove(tones, 1) == 4 && intervalAbove(tones, 2) == 7 &&
+		(hasSeventh(tones) || nextRoot == notePitchClass(tonic)):
+		leadingTone = tones[1]
+	case motion == 1 && diminished(tones):
+		leadingTone = tones[0]
+	}
+	seventh := ""
+	if hasSeventh(tones) {
+		seventh = tones[3]
+	}
+
+	var issues []string
+	for v, p := range from {
+		step := to[v].midi() - p.midi()
+		switch {
+		case p.name() == leadingTone && step != 1:
+			issues = append(issues, fmt.Sprintf("Unresolved leading tone %s in %s", p.name(), voiceNames[v]))
+		case p.name() == seventh && step != -1 && step != -2:
+			issues = append(issues, fmt.Sprintf("Unresolved seventh %s in %s", p.name(), voiceNames[v]))
+		}
+	}
+	return issues
+}
+
+// movement returns the semitones moved by all the voices together.
+func movement(from, to []pitch) int {
+	total := 0
+	for v := range from {
+		d := to[v].midi() - from[v].midi()
+		if d < 0 {
+			d = -d
+		}
+		total += d
+	}
+	return total
+}
+
+// voiceLead voices the chords in four parts for the style, choosing the voicings that together move the voices the
+// least while avoiding parallel fifths and octaves and unresolved tendency tones, and flags those that remain. Chords
+// that cannot be voiced in four parts, such as power chords, are voiced in close position from chordOctave.
+func voiceLead(chords []chord, tonic, style string) []ledChord {
+	type state struct {
+		voicingCandidate
+		total int // of the best path to this voicing
+		prev  int // voicing of the previous chord on that path, or -1
+	}
+
+	stages := make([][]state, len(chords))
+	for i, c := range chords {
+		candidates := voicingCandidates(c, style)
+		if len(candidates) == 0 {
+			candidates = []voicingCandidate{{pitches: voiceNotes(c.notes, chordOctave)}}
+		}
+		for _, cand := range candidates {
+			st := state{voicingCandidate: cand, prev: -1}
+			if i == 0 {
+				st.total = cand.cost + startDistance(cand.pitches)/startCost
+			} else {
+				st.total = -1
+				for j, p := range stages[i-1] {
+					total := p.total + cand.cost + transitionCost(chords[i-1], c, tonic, p.pitches, cand.pitches)
+					if st.total < 0 || total < st.total {
+						st.total, st.prev = total, j
+					}
+				}
+			}
+			stages[i] = append(stages[i], st)
+		}
+	}
+	if len(stages) == 0 {
+		return nil
+	}
+
+	best := 0
+	for j, st := range stages[len(stages)-1] {
+		if st.total < stages[len(stages)-1][best].total {
+			best = j
+		}
+	}
+	led := make([]ledChord, len(chords))
+	for i := len(chords) - 1; i >= 0; i-- {
+		st := stages[i][best]
+		led[i] = ledChord{chord: chords[i], pitches: st.pitches}
+		best = st.prev
+	}
+	for i := 1; i < len(led); i++ {
+		led[i].issues = leadingIssues(led[i-1].chord, led[i].chord, tonic, led[i-1].pitches, led[i].pitches)
+	}
+	return led
+}
+
+// startDistance returns the semitones of the voices from the middle of their ranges.
+func startDistance(pitches []pitch) int {
+	total := 0
+	for v, p := range pitches {
+		if v >= len(voiceRanges) {
+			break
+		}
+		d := p.midi() - (voiceRanges[v][0]+voiceRanges[v][1])/2
+		if d < 0 {
+			d = -d
+		}
+		total += d
+	}
+	return total
+}
+
+// transitionCost returns the cost of moving from one voicing to the next: the voice movement and the issues.
+func transitionCost(c, next chord, tonic string, from, to []pitch) int {
+	if len(from) != len(to) {
+		return 0
+	}
+	return movement(from, to) + parallelCost*len(parallels(from, to)) +
+		unresolvedCost*len(unresolved(c, next, tonic, from, to))
+}
+
+// leadingIssues describes the parallels and unresolved tendency tones of the move from one voicing to the next.
+func leadingIssues(c, next chord, tonic string, from, to []pitch) []string {
+	if len(from) != len(to) {
+		return nil
+	}
+	return append(parallels(from, to), unresolved(c, next, tonic, from, to)...)
+}
+
+// voiceChords voices each of the chords: the voice-leading voicings voice the chords together, in the ranges of the
+// voices, and the others voice each chord alone from the octave.
+func voiceChords(chords []chord, tonic string, octave int, voicing string) [][]pitch {
+	var voiced [][]pitch
+	if contains(leadVoicings, voicing) {
+		for _, l := range voiceLead(chords, tonic, voicing) {
+			voiced = append(voiced, l.pitches)
+		}
+		return voiced
+	}
+	for _, c := range chords {
+		voiced = append(voiced, voice(c.notes, octave, voicing))
+	}
+	return voiced
+}
+
+// buildVoiceLeading returns the progression voiced in four parts on a grand staff, with the issues of the voice
+// leading, to play or export.
+func (m *model) buildVoiceLeading() fyne.CanvasObject {
+	m.leadStyle = voicingSATB
+	m.leadStaff = newStaff()
+	m.leadIssues = widget.NewLabel("")
+	m.leadIssues.Wrapping = fyne.TextWrapWord
+
+	styleSelector := widget.NewRadioGroup(leadVoicings, func(style string) {
+		if style == "" {
+			return
+		}
+		m.leadStyle = style
+		m.refreshVoiceLeading()
+	})
+	styleSelector.Horizontal = true
+	styleSelector.Required = true
+	styleSelector.SetSelected(m.leadStyle)
+
+	return widget.NewCard("", "Voice Leading", container.NewVBox(
+		container.NewHBox(
+			styleSelector,
+			layout.NewSpacer(),
+			widget.NewButton("Play", m.playVoiceLeading),
+			widget.NewButton("Export MIDI…", m.exportVoiceLeading),
+		),
+		m.leadStaff,
+		m.leadIssues,
+	))
+}
+
+// refreshVoiceLeading voices the progression again and shows it with its issues.
+func (m *model) refreshVoiceLeading() {
+	if m.leadStaff == nil {
+		return
+	}
+	var events []staffEvent
+	var issues []string
+	for i, l := range voiceLead(m.progression, m.key, m.leadStyle) {
+		events = append(events, staffEvent{pitches: l.pitches, label: l.chord.name, bar: true})
+		for _, issue := range l.issues {
+			issues = append(issues, fmt.Sprintf("%s → %s: %s", m.progression[i-1].name, l.chord.name, issue))
+		}
+	}
+	m.leadStaff.setKeySignature(keySignatureFor(m.key, m.scale))
+	m.leadStaff.setEvents(events)
+
+	switch {
+	case len(m.progression) == 0:
+		m.leadIssues.SetText("Add chords to the progression to voice them")
+	case len(issues) == 0:
+		m.leadIssues.SetText("No parallel fifths or octaves, and every leading tone and seventh resolves")
+	default:
+		m.leadIssues.SetText(strings.Join(issues, "\n"))
+	}
+}
+
+// playVoiceLeading plays the progression as voiced on the voice-leading staff.
+func (m *model) playVoiceLeading() {
+	var chords [][]int
+	for _, pitches := range voiceChords(m.progression, m.key, chordOctave, m.leadStyle) {
+		chords = append(chords, midiNotes(pitches))
+	}
+	m.play(m.synth.progressionNotes(chords))
+}
+
+// exportVoiceLeading asks where to save the progression as voiced on the voice-leading staff as a MIDI file.
+func (m *model) exportVoiceLeading() {
+	opts := defaultMIDIOptions()
+	opts.tempo = m.synth.tempo
+	opts.voicing = m.leadStyle
+	m.saveFile(fmt.Sprintf("%s %s %s.mid", m.key, m.scale, exportProgression), ".mid", func(w io.Writer) error {
+		return m.exportMIDI(w, exportProgression, opts)
+	})
+}
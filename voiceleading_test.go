@@ -0,0 +1,169 @@
+package main
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+)
+
+// pitchNames returns the names of the pitches with their octaves.
+func pitchNames(pitches []pitch) []string {
+	var names []string
+	for _, p := range pitches {
+		names = append(names, p.String())
+	}
+	return names
+}
+
+func TestVoiceLead(t *testing.T) {
+	m := sheetModel("C", "Major")
+	triads, sevenths := m.buildTriads(), m.buildSevenths()
+	progression := []chord{triads[0], triads[5], sevenths[1], sevenths[4], triads[0]}
+
+	for _, style := range leadVoicings {
+		led := voiceLead(progression, "C", style)
+		assert.Len(t, led, len(progression))
+		for i, l := range led {
+			assert.Empty(t, l.issues, "%s %s", style, l.chord.name)
+			assert.Len(t, l.pitches, len(voiceNames))
+			assert.Equal(t, progression[i].notes[0], l.pitches[0].name(), "the bass has the root")
+			for v, p := range l.pitches {
+				assert.GreaterOrEqual(t, p.midi(), voiceRanges[v][0])
+				assert.LessOrEqual(t, p.midi(), voiceRanges[v][1])
+			}
+			if style == voicingLedClose {
+				assert.Less(t, l.pitches[3].midi()-l.pitches[1].midi(), chromaticScaleLen)
+			}
+		}
+	}
+
+	led := voiceLead(progression, "C", voicingSATB)
+	assert.Equal(t, []string{"G2", "B3", "F4", "G4"}, pitchNames(led[3].pitches))
+	assert.Equal(t, []string{"C3", "C4", "E4", "G4"}, pitchNames(led[4].pitches),
+		"the leading tone rises to the tonic and the seventh falls to the third")
+	assert.Empty(t, voiceLead(nil, "C", voicingSATB))
+
+	power := voiceLead([]chord{{name: "C5", notes: []string{"C", "G"}}, triads[4]}, "C", voicingSATB)
+	assert.Equal(t, []string{"C4", "G4"}, pitchNames(power[0].pitches))
+	assert.Empty(t, power[1].issues)
+}
+
+func TestVoicingCost(t *testing.T) {
+	tests := []struct {
+		tones   []string
+		pitches []string
+		style   string
+		cost    int
+		ok      bool
+	}{
+		{[]string{"C", "E", "G"}, []string{"C3", "G3", "E4", "C5"}, voicingSATB, 0, true},
+		{[]string{"C", "E", "G"}, []string{"C3", "E3", "G3", "E4"}, voicingSATB, 3, true},
+		{[]string{"C", "E", "G"}, []string{"C3", "C4", "E4", "C5"}, voicingSATB, 2, true},
+		{[]string{"C", "E", "G"}, []string{"C3", "C4", "G4", "C5"}, voicingSATB, 0, false},
+		{[]string{"C", "E", "G"}, []string{"C3", "E3", "G4", "C5"}, voicingSATB, 0, false},
+		{[]string{"C", "E", "G"}, []string{"C3", "G3", "E4", "C5"}, voicingLedClose, 0, false},
+		{[]string{"C", "E", "G"}, []string{"C3", "G3", "E3", "C4"}, voicingSATB, 0, false},
+		{[]string{"G", "B", "D", "F"}, []string{"G2", "B3", "F4", "G4"}, voicingLedClose, 1, true},
+		{[]string{"G", "B", "D", "F"}, []string{"G2", "B3", "D4", "G4"}, voicingSATB, 0, false},
+	}
+
+	for _, e := range tests {
+		var pitches []pitch
+		for _, n := range e.pitches {
+			p, ok := newPitch(n[:len(n)-1], int(n[len(n)-1]-'0'))
+			assert.True(t, ok)
+			pitches = append(pitches, p)
+		}
+		cost, ok := voicingCost(e.tones, pitches, e.style)
+		assert.Equal(t, e.ok, ok, "%v %s", e.pitches, e.style)
+		assert.Equal(t, e.cost, cost, "%v %s", e.pitches, e.style)
+	}
+}
+
+func TestLeadingIssues(t *testing.T) {
+	g7 := chord{name: "G7", notes: []string{"G", "B", "D", "F"}}
+	g := chord{name: "G", notes: []string{"G", "B", "D"}}
+	c := chord{name: "C", notes: []string{"C", "E", "G"}}
+	f := chord{name: "F", notes: []string{"F", "A", "C"}}
+	bDim := chord{name: "B°", notes: []string{"B", "D", "F"}}
+	voicing := func(names ...string) []pitch {
+		var pitches []pitch
+		for _, n := range names {
+			p, _ := newPitch(n[:len(n)-1], int(n[len(n)-1]-'0'))
+			pitches = append(pitches, p)
+		}
+		return pitches
+	}
+
+	tests := []struct {
+		from, to chord
+		tonic    string
+		before   []pitch
+		after    []pitch
+		issues   []string
+	}{
+		{g7, c, "C", voicing("G2", "B3", "F4", "G4"), voicing("C3", "C4", "E4", "G4"), nil},
+		{g7, c, "C", voicing("G2", "B3", "F4", "G4"), voicing("C3", "G3", "E4", "G4"),
+			[]string{"Unresolved leading tone B in tenor"}},
+		{g7, c, "C", voicing("G2", "F3", "B3", "D4"), voicing("C3", "G3", "C4", "E4"),
+			[]string{"Unresolved seventh F in tenor"}},
+		{g, c, "C", voicing("G2", "D3", "B3", "G4"), voicing("C3", "G3", "E4", "C5"),
+			[]string{"Parallel fifths between bass and tenor", "Parallel octaves between bass and soprano",
+				"Unresolved leading tone B in alto"}},
+		{g, c, "F", voicing("G2", "D3", "B3", "G4"), voicing("C3", "G3", "E4", "C5"),
+			[]string{"Parallel fifths between bass and tenor", "Parallel octaves between bass and soprano"}},
+		{c, f, "C", voicing("C3", "G3", "E4", "C5"), voicing("F3", "A3", "F4", "C5"), nil},
+		{bDim, c, "C", voicing("B2", "F3", "D4", "B4"), voicing("C3", "G3", "E4", "C5"),
+			[]string{"Parallel octaves between bass and soprano"}},
+		{bDim, c, "C", voicing("B2", "F3", "D4", "B4"), voicing("C3", "E3", "E4", "G4"),
+			[]string{"Unresolved leading tone B in soprano"}},
+	}
+
+	for _, e := range tests {
+		assert.Equal(t, e.issues, leadingIssues(e.from, e.to, e.tonic, e.before, e.after), "%s %s", e.from.name,
+			pitchNames(e.before))
+	}
+}
+
+func TestVoiceLeadingMIDI(t *testing.T) {
+	m := sheetModel("C", "Major")
+	m.progression = m.buildSevenths()[:3]
+	opts := defaultMIDIOptions()
+	opts.voicing = voicingSATB
+
+	var notes []int
+	for _, e := range m.midiTracks(exportProgression, opts)[1].events {
+		if e.data[0] == noteOn {
+			notes = append(notes, int(e.data[1]))
+		}
+	}
+	var led []int
+	for _, l := range voiceLead(m.progression, "C", voicingSATB) {
+		led = append(led, midiNotes(l.pitches)...)
+	}
+	assert.Equal(t, led, notes)
+	assert.Len(t, notes, 3*len(voiceNames))
+}
+
+func TestVoiceLeadingUI(t *testing.T) {
+	a := test.NewApp()
+	defer a.Quit()
+
+	m := sheetModel("C", "Major")
+	m.buildVoiceLeading()
+	m.refreshVoiceLeading()
+	assert.Equal(t, "Add chords to the progression to voice them", m.leadIssues.Text)
+
+	g := chord{name: "G", notes: []string{"G", "B", "D"}}
+	c := chord{name: "C", notes: []string{"C", "E", "G"}}
+	m.progression = []chord{g, c}
+	m.refreshVoiceLeading()
+	assert.Len(t, m.leadStaff.events, 2)
+	assert.Equal(t, "C", m.leadStaff.events[1].label)
+	assert.Contains(t, m.leadIssues.Text, "No parallel fifths or octaves")
+
+	m.progression = []chord{{name: "C5", notes: []string{"C", "G"}}, {name: "D5", notes: []string{"D", "A"}}}
+	m.refreshVoiceLeading()
+	assert.Equal(t, "C5 → D5: Parallel fifths between bass and tenor", m.leadIssues.Text)
+}
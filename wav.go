@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// pcm16 converts samples in [-1, 1] to 16 bit little endian PCM, clipping anything outside the range.
+func pcm16(samples []float32) []byte {
+	data := make([]byte, 2*len(samples))
+	for i, v := range samples {
+		v = float32(math.Max(-1, math.Min(1, float64(v))))
+		binary.LittleEndian.PutUint16(data[2*i:], uint16(int16(v*math.MaxInt16)))
+	}
+
+	return data
+}
+
+// writeWAV writes mono samples as a 16 bit PCM WAV file.
+func writeWAV(w io.Writer, samples []float32, rate int) error {
+	const (
+		channels      = 1
+		bitsPerSample = 16
+		headerSize    = 36
+	)
+	data := pcm16(samples)
+	blockAlign := channels * bitsPerSample / 8
+
+	header := []interface{}{
+		[4]byte{'R', 'I', 'F', 'F'},
+		uint32(headerSize + len(data)),
+		[4]byte{'W', 'A', 'V', 'E'},
+		[4]byte{'f', 'm', 't', ' '},
+		uint32(16), // size of the fmt chunk
+		uint16(1),  // PCM
+		uint16(channels),
+		uint32(rate),
+		uint32(rate * blockAlign),
+		uint16(blockAlign),
+		uint16(bitsPerSample),
+		[4]byte{'d', 'a', 't', 'a'},
+		uint32(len(data)),
+	}
+	for _, field := range header {
+		if err := binary.Write(w, binary.LittleEndian, field); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.Write(data)
+	return err
+}